@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/go-find-liquor/internal/logging"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+var migrateOutput string
+
+// newConfigCmd creates the config command and its subcommands
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration file",
+		// Skip the root pre-run, which loads (and would migrate) the configuration
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if debug {
+				logging.SetLevel(log.DebugLevel)
+			}
+		},
+	}
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Convert a legacy single-user config file to the multi-user format",
+		Long: `Convert a legacy single-user config file (given with -c) to the multi-user format.
+
+The result is written to the file given with -o, or printed to stdout.
+Environment variables are not merged into the output.`,
+		Example:      "  go-find-liquor config migrate -c old.yaml -o config.yaml",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         configMigrateRun,
+	}
+	migrateCmd.Flags().StringVarP(&migrateOutput, "output", "o", "", "Write the migrated config to this file instead of stdout")
+
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print a JSON Schema for the configuration file",
+		Long: `Print a JSON Schema describing the configuration file, including the
+credential keys each notification type requires, for editor completion and
+validation.`,
+		Example:      "  go-find-liquor config schema > config.schema.json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         configSchemaRun,
+	}
+
+	configCmd.AddCommand(migrateCmd, schemaCmd)
+	return configCmd
+}
+
+func configMigrateRun(cmd *cobra.Command, args []string) error {
+	if configFile == "" {
+		return fmt.Errorf("a legacy config file must be given with -c/--config")
+	}
+
+	migrated, err := config.MigrateConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	if migrateOutput == "" {
+		_, err := cmd.OutOrStdout().Write(migrated)
+		return err
+	}
+
+	if err := os.WriteFile(migrateOutput, migrated, 0600); err != nil {
+		return fmt.Errorf("failed to write migrated config: %w", err)
+	}
+	log.Infof("Wrote migrated configuration to %s", migrateOutput)
+	return nil
+}
+
+func configSchemaRun(cmd *cobra.Command, args []string) error {
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate config schema: %w", err)
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+	return err
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/internal/runner"
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+var previewItemsFile string
+
+// newNotificationsCmd creates the notifications command and its subcommands
+func newNotificationsCmd() *cobra.Command {
+	notificationsCmd := &cobra.Command{
+		Use:   "notifications",
+		Short: "Work with configured notifications",
+	}
+
+	previewCmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Print the notifications each configured channel would send for sample items, without sending",
+		Long: `Print the notifications each configured channel would send for sample items, without sending.
+
+The items file is a JSON array of found items, for example:
+  [{"Name": "BLANTON'S", "Store": "1001 - Portland", "Price": "$64.95", "Date": "2026-01-02T15:04:05Z"}]`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         notificationsPreviewRun,
+	}
+	previewCmd.Flags().StringVar(&previewItemsFile, "items", "", "JSON file of sample found items")
+	_ = previewCmd.MarkFlagRequired("items")
+
+	notificationsCmd.AddCommand(previewCmd)
+	return notificationsCmd
+}
+
+func notificationsPreviewRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	items, err := loadPreviewItems(previewItemsFile)
+	if err != nil {
+		return err
+	}
+
+	return writePreview(cmd.OutOrStdout(), conf, items)
+}
+
+// loadPreviewItems reads sample found items from a JSON file
+func loadPreviewItems(path string) ([]search.LiquorItem, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is provided by the user on the command line
+	if err != nil {
+		return nil, fmt.Errorf("failed to read items file: %w", err)
+	}
+
+	var items []search.LiquorItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse items file %s: %w", path, err)
+	}
+	return items, nil
+}
+
+// writePreview renders the found item notifications for every user's
+// configured channels to w, with the same message options the runner uses,
+// and their heartbeat if the user gets heartbeats
+func writePreview(w io.Writer, conf config.Config, items []search.LiquorItem) error {
+	for _, user := range conf.Users {
+		opts, err := runner.MessageOptions(conf, user)
+		if err != nil {
+			return err
+		}
+		for _, nc := range user.Notifications {
+			mode := "individual"
+			if nc.Condense {
+				mode = "condensed"
+			}
+			fmt.Fprintf(w, "=== User '%s' - %s (%s) ===\n", user.Name, nc.Type, mode)
+
+			messages, err := notification.PreviewFoundItems(nc, items, opts...)
+			if err != nil {
+				return fmt.Errorf("failed to preview notifications for user '%s': %w", user.Name, err)
+			}
+			// Heartbeats are only sent with a heartbeat interval
+			if user.EffectiveHeartbeatInterval(conf.HeartbeatInterval) > 0 {
+				heartbeat, err := notification.PreviewHeartbeat(nc, notification.HeartbeatStats{
+					User:         user.Name,
+					Users:        len(conf.Users),
+					ItemsWatched: len(user.Items),
+				}, opts...)
+				if err != nil {
+					return fmt.Errorf("failed to preview heartbeat for user '%s': %w", user.Name, err)
+				}
+				messages = append(messages, heartbeat)
+			}
+
+			for _, message := range messages {
+				fmt.Fprintf(w, "Subject: %s\n%s\n\n", message.Subject, strings.TrimRight(message.Body, "\n"))
+			}
+		}
+	}
+	return nil
+}
@@ -40,6 +40,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/toozej/go-find-liquor/internal/logging"
 	"github.com/toozej/go-find-liquor/internal/runner"
 	"github.com/toozej/go-find-liquor/pkg/config"
 	"github.com/toozej/go-find-liquor/pkg/man"
@@ -47,9 +48,12 @@ import (
 )
 
 var (
-	configFile string
-	once       bool
-	debug      bool
+	configFile      string
+	once            bool
+	debug           bool
+	maxItemsPerUser int
+	dryRun          bool
+	logFormat       string
 )
 
 var rootCmd = &cobra.Command{
@@ -70,6 +74,9 @@ func rootCmdRun(cmd *cobra.Command, args []string) error {
 
 	// Log configuration summary for multi-user scenarios
 	logConfigurationSummary(conf)
+	if conf.DryRun {
+		log.Info("Dry run: notifications will be logged instead of sent")
+	}
 
 	// Create runner (supports both single and multi-user configurations)
 	r, err := runner.NewRunner(conf)
@@ -88,7 +95,11 @@ func rootCmdRun(cmd *cobra.Command, args []string) error {
 	go func() {
 		<-sigCh
 		log.Info("Received termination signal, shutting down...")
-		r.Stop()
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), conf.EffectiveShutdownTimeout())
+		defer cancelShutdown()
+		if err := r.StopWithContext(shutdownCtx); err != nil {
+			log.Warnf("Shutdown did not complete cleanly: %v", err)
+		}
 		cancel()
 	}()
 
@@ -103,11 +114,15 @@ func rootCmdRun(cmd *cobra.Command, args []string) error {
 	} else {
 		userCount := len(conf.Users)
 		if userCount == 1 {
-			log.Infof("Starting continuous search for user '%s' with interval %.0f hours",
-				conf.Users[0].Name, conf.Interval.Hours())
+			log.Infof("Starting continuous search for user '%s', searching %s",
+				conf.Users[0].Name, conf.Users[0].ScheduleDescription(conf.Interval))
 		} else {
-			log.Infof("Starting continuous search for %d users with interval %.0f hours",
-				userCount, conf.Interval.Hours())
+			log.Infof("Starting continuous search for %d users with default interval %s",
+				userCount, conf.Interval)
+		}
+
+		if conf.HotReload {
+			watchConfig(ctx, r)
 		}
 
 		if err := r.Start(ctx); err != nil {
@@ -120,6 +135,21 @@ func rootCmdRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// watchConfig reloads users from the config file in the background whenever it changes
+func watchConfig(ctx context.Context, r runner.Runner) {
+	path := config.FilePath()
+	if path == "" {
+		log.Warn("hot_reload is enabled but there is no config file to watch")
+		return
+	}
+
+	go func() {
+		if err := r.WatchConfig(ctx, path, config.GetConfig); err != nil {
+			log.Errorf("Config hot reload disabled: %v", err)
+		}
+	}()
+}
+
 func logConfigurationSummary(conf config.Config) {
 	userCount := len(conf.Users)
 
@@ -127,7 +157,8 @@ func logConfigurationSummary(conf config.Config) {
 		user := conf.Users[0]
 		log.Infof("Configuration loaded: Single user '%s'", user.Name)
 		log.Infof("  - Items: %d", len(user.Items))
-		log.Infof("  - Location: %s (within %d miles)", user.Zipcode, user.Distance)
+		log.Infof("  - Location: %s", user.LocationDescription())
+		log.Infof("  - Schedule: %s", user.ScheduleDescription(conf.Interval))
 		log.Infof("  - Notifications: %d configured", len(user.Notifications))
 
 		// Log condensing status for notifications
@@ -141,8 +172,8 @@ func logConfigurationSummary(conf config.Config) {
 	} else {
 		log.Infof("Configuration loaded: Multi-user setup with %d users", userCount)
 		for i, user := range conf.Users {
-			log.Infof("  User %d: '%s' - %d items, %s (%d miles), %d notifications",
-				i+1, user.Name, len(user.Items), user.Zipcode, user.Distance, len(user.Notifications))
+			log.Infof("  User %d: '%s' - %d items, %s, %s, %d notifications",
+				i+1, user.Name, len(user.Items), user.LocationDescription(), user.ScheduleDescription(conf.Interval), len(user.Notifications))
 		}
 	}
 
@@ -153,22 +184,47 @@ func logConfigurationSummary(conf config.Config) {
 }
 
 func rootCmdPreRun(cmd *cobra.Command, args []string) {
+	// Set the log format first so every line is in it
+	logFormatFlag := cmd.Flags().Changed("log-format")
+	if logFormatFlag {
+		if err := logging.SetFormat(logFormat); err != nil {
+			log.Warnf("Ignoring --log-format: %v", err)
+		}
+	}
+
 	// Set custom config file if specified
 	if configFile != "" {
 		config.SetConfigFile(configFile)
 		log.Infof("Using config file: %s", configFile)
 	}
 
+	if cmd.Flags().Changed("max-items-per-user") {
+		config.SetMaxItemsPerUser(maxItemsPerUser)
+	}
+	if cmd.Flags().Changed("dry-run") {
+		config.SetDryRun(dryRun)
+	}
+
 	// Set log level based on debug flag or config verbose setting
 	if debug {
-		log.SetLevel(log.DebugLevel)
+		logging.SetLevel(log.DebugLevel)
 		log.Debug("Debug logging enabled via command line flag")
-	} else {
-		// Load config to check verbose setting
-		if conf, err := config.GetConfig(); err == nil && conf.Verbose {
-			log.SetLevel(log.DebugLevel)
+	}
+
+	// Load config to check verbose setting and per-component log levels
+	if conf, err := config.GetConfig(); err == nil {
+		if !debug && conf.Verbose {
+			logging.SetLevel(log.DebugLevel)
 			log.Debug("Debug logging enabled via configuration")
 		}
+		if !logFormatFlag && conf.LogFormat != "" {
+			if err := logging.SetFormat(conf.LogFormat); err != nil {
+				log.Warnf("Ignoring log_format: %v", err)
+			}
+		}
+		if err := logging.SetComponentLevels(conf.LogLevels); err != nil {
+			log.Warnf("Ignoring log_levels: %v", err)
+		}
 	}
 }
 
@@ -183,12 +239,21 @@ func Execute() error {
 func init() {
 	// create rootCmd-level flags
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug-level logging")
-	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Config file path")
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Config file path (.yaml, .json or .toml)")
+	rootCmd.PersistentFlags().IntVar(&maxItemsPerUser, "max-items-per-user", 0, "Maximum items per user, overriding max_items_per_user (0 = unlimited)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Log notifications instead of sending them, overriding dry_run")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format, text or json, overriding log_format")
 	rootCmd.Flags().BoolVarP(&once, "once", "o", false, "Run search once and exit")
 
 	// add sub-commands
 	rootCmd.AddCommand(
 		man.NewManCmd(),
 		version.Command(),
+		newNotificationsCmd(),
+		newConfigCmd(),
+		newValidateCmd(),
+		newSearchCmd(),
+		newTestNotifyCmd(),
+		newStatusCmd(),
 	)
 }
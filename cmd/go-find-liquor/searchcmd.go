@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+var (
+	searchItems    []string
+	searchZipcode  string
+	searchDistance int
+)
+
+// newSearchCmd creates the search command
+func newSearchCmd() *cobra.Command {
+	searchCmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search OLCC once for items and print the results, without a config file or notifications",
+		Long: `Search OLCC once for items and print the results, without a config file or notifications.
+
+Each --item is searched in turn and the stores carrying it are printed as a table.
+Exits nonzero if any search fails.`,
+		Example:      `  go-find-liquor search --item "Blanton's" --item 0171B --zipcode 97201 --distance 10`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         searchRun,
+	}
+	searchCmd.Flags().StringArrayVar(&searchItems, "item", nil, "Item name or code to search for (repeatable)")
+	searchCmd.Flags().StringVar(&searchZipcode, "zipcode", "", "Zipcode to search near")
+	searchCmd.Flags().IntVar(&searchDistance, "distance", 10, "Search radius in miles")
+	_ = searchCmd.MarkFlagRequired("item")
+	_ = searchCmd.MarkFlagRequired("zipcode")
+
+	return searchCmd
+}
+
+func searchRun(cmd *cobra.Command, args []string) error {
+	if searchDistance <= 0 {
+		return fmt.Errorf("--distance must be positive")
+	}
+
+	searcher := search.NewSearcher("")
+
+	var results []search.LiquorItem
+	var errs []error
+	for _, item := range searchItems {
+		found, err := searcher.SearchItem(cmd.Context(), item, searchZipcode, searchDistance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to search for %s: %w", item, err))
+			continue
+		}
+		if len(found) == 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "No results for %s\n", item)
+		}
+		results = append(results, found...)
+	}
+
+	if len(results) > 0 {
+		if err := writeSearchResults(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeSearchResults prints found items to w as a table
+func writeSearchResults(w io.Writer, items []search.LiquorItem) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUERY\tNAME\tCODE\tSIZE\tPRICE\tQTY\tSTORE")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			item.Query, item.Name, item.Code, item.Size, item.Price, item.Quantity, item.Store)
+	}
+	return tw.Flush()
+}
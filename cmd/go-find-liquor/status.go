@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/go-find-liquor/internal/runner"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+var (
+	statusJSON bool
+	statusAddr string
+)
+
+// newStatusCmd creates the status command
+func newStatusCmd() *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show each user's last search, result count and next scheduled search",
+		Long: `Show each user's last search, result count and next scheduled search.
+
+The status is read from a running go-find-liquor's status endpoint, which is
+served on status_addr (a localhost address) when it is set. The address is
+taken from the configuration (honoring -c) unless --addr is given.`,
+		Example:      "  go-find-liquor status -c config.yaml\n  go-find-liquor status --addr 127.0.0.1:9092 --json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         statusRun,
+	}
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
+	statusCmd.Flags().StringVar(&statusAddr, "addr", "", "Status address of the running instance, overriding status_addr")
+
+	return statusCmd
+}
+
+func statusRun(cmd *cobra.Command, args []string) error {
+	addr := statusAddr
+	if addr == "" {
+		conf, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if conf.StatusAddr == "" {
+			return fmt.Errorf("status_addr is not set, so there is no status endpoint to query")
+		}
+		addr = conf.StatusAddr
+	}
+
+	report, err := runner.FetchStatus(cmd.Context(), addr)
+	if err != nil {
+		return err
+	}
+
+	if statusJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return writeStatus(cmd.OutOrStdout(), report)
+}
+
+// writeStatus prints the status report to w as a table
+func writeStatus(w io.Writer, report runner.StatusReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USER\tRUNNING\tLAST SEARCH\tRESULTS\tNEXT SEARCH\tLAST ERROR")
+	for _, user := range report.Users {
+		results := "-"
+		if user.LastSearchTime != nil {
+			results = fmt.Sprintf("%d", user.LastResultCount)
+		}
+		fmt.Fprintf(tw, "%s\t%t\t%s\t%s\t%s\t%s\n",
+			user.Name, user.Alive, formatStatusTime(user.LastSearchTime), results,
+			formatStatusTime(user.NextSearchTime), user.LastError)
+	}
+	return tw.Flush()
+}
+
+// formatStatusTime formats an optional status time, or "-" if unset
+func formatStatusTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
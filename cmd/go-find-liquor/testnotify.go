@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+var testNotifyUser string
+
+// newTestNotifyCmd creates the test-notify command
+func newTestNotifyCmd() *cobra.Command {
+	testNotifyCmd := &cobra.Command{
+		Use:   "test-notify",
+		Short: "Send a test notification through every configured channel",
+		Long: `Send a test notification through every configured channel.
+
+Each user's channels are set up from the configuration (honoring -c) and sent
+"go-find-liquor test notification", reporting whether each one succeeded.
+Exits nonzero if any channel fails.`,
+		Example:      "  go-find-liquor test-notify --user alice",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         testNotifyRun,
+	}
+	testNotifyCmd.Flags().StringVar(&testNotifyUser, "user", "", "Only test the notifications of this user")
+	return testNotifyCmd
+}
+
+func testNotifyRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	return testNotifications(cmd.Context(), cmd.OutOrStdout(), conf, testNotifyUser)
+}
+
+// testNotifications sends a test notification through each channel of every
+// user, or only the named user, writing each channel's result to w
+func testNotifications(ctx context.Context, w io.Writer, conf config.Config, user string) error {
+	if user != "" && !hasUser(conf, user) {
+		return fmt.Errorf("no user named '%s' in the configuration", user)
+	}
+
+	opts := []notification.ManagerOption{
+		notification.WithAllowedTypes(conf.AllowedNotificationTypes),
+		notification.WithDryRun(conf.DryRun),
+	}
+
+	tested, failed := 0, 0
+	for _, u := range conf.Users {
+		if user != "" && u.Name != user {
+			continue
+		}
+		for i, nc := range u.Notifications {
+			tested++
+			// A manager per channel, so each channel's result is reported separately
+			manager, err := notification.NewNotificationManager([]config.NotificationConfig{nc}, opts...)
+			if err == nil {
+				err = manager.NotifyTest(ctx)
+			}
+			if err != nil {
+				failed++
+				fmt.Fprintf(w, "User '%s' - notification %d (%s): FAILED: %v\n", u.Name, i+1, nc.Type, err)
+				continue
+			}
+			fmt.Fprintf(w, "User '%s' - notification %d (%s): ok\n", u.Name, i+1, nc.Type)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notification channel(s) failed", failed, tested)
+	}
+	return nil
+}
+
+// hasUser reports whether conf has a user named name
+func hasUser(conf config.Config, name string) bool {
+	for _, u := range conf.Users {
+		if u.Name == name {
+			return true
+		}
+	}
+	return false
+}
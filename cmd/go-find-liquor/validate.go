@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// newValidateCmd creates the validate command
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration and print a summary, without searching",
+		Long: `Check the configuration and print a summary, without searching.
+
+The configuration is loaded the same way as when running (honoring -c), then
+every user's notifications are set up to check their credentials. Nothing is
+sent. Exits nonzero with the validation error if the configuration is invalid.`,
+		Example:      "  go-find-liquor validate -c config.yaml",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         validateRun,
+	}
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("configuration is invalid: %w", err)
+	}
+
+	if err := checkNotifications(conf); err != nil {
+		return fmt.Errorf("configuration is invalid: %w", err)
+	}
+
+	writeValidationSummary(cmd.OutOrStdout(), conf)
+	return nil
+}
+
+// checkNotifications sets up each user's notification manager, which checks
+// the required credentials of every channel without sending anything
+func checkNotifications(conf config.Config) error {
+	for _, user := range conf.Users {
+		_, err := notification.NewNotificationManager(user.Notifications,
+			notification.WithHeartbeatTemplate(conf.HeartbeatTemplate),
+			notification.WithAllowedTypes(conf.AllowedNotificationTypes),
+		)
+		if err != nil {
+			return fmt.Errorf("notifications for user '%s': %w", user.Name, err)
+		}
+	}
+	return nil
+}
+
+// itemOptions describes the options set on a watched item, e.g. " (within 50 miles, note: for dad)"
+func itemOptions(item config.ItemConfig) string {
+	var opts []string
+	if item.Distance > 0 {
+		opts = append(opts, fmt.Sprintf("within %d miles", item.Distance))
+	}
+	if item.MaxPrice > 0 {
+		opts = append(opts, fmt.Sprintf("max $%.2f", item.MaxPrice))
+	}
+	if item.Note != "" {
+		opts = append(opts, "note: "+item.Note)
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(opts, ", ") + ")"
+}
+
+// writeValidationSummary prints the users, items and notification types of conf to w
+func writeValidationSummary(w io.Writer, conf config.Config) {
+	fmt.Fprintf(w, "Configuration is valid: %d user(s), default interval %s\n", len(conf.Users), conf.Interval)
+	for _, user := range conf.Users {
+		fmt.Fprintf(w, "\nUser '%s'\n", user.Name)
+		fmt.Fprintf(w, "  Location: %s\n", user.LocationDescription())
+		fmt.Fprintf(w, "  Schedule: %s\n", user.ScheduleDescription(conf.Interval))
+		fmt.Fprintf(w, "  Items (%d):\n", len(user.Items))
+		for _, item := range user.Items {
+			fmt.Fprintf(w, "    - %s%s\n", item.Name, itemOptions(item))
+		}
+		fmt.Fprintf(w, "  Notifications (%d):\n", len(user.Notifications))
+		for _, nc := range user.Notifications {
+			mode := "individual"
+			if nc.Condense {
+				mode = "condensed"
+			}
+			fmt.Fprintf(w, "    - %s (%s)\n", nc.Type, mode)
+		}
+	}
+}
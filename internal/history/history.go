@@ -0,0 +1,253 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// Item fields that can make up the key identifying an item in the history
+const (
+	KeyCode  = "code"
+	KeyStore = "store"
+	KeyPrice = "price"
+	KeySize  = "size"
+)
+
+// DefaultKeyFields identify an item by its code at a store, so a price change
+// alone doesn't make it new
+var DefaultKeyFields = []string{KeyCode, KeyStore}
+
+// PriceHistory persists the last-seen bottle price of each item at each store,
+// grouped by the search term that found it, so that a search can be compared
+// against the previous one
+type PriceHistory struct {
+	mu        sync.Mutex
+	path      string
+	keyFields []string
+	// prices maps search term -> item key -> price in cents
+	prices map[string]map[string]int64
+}
+
+// Option configures optional PriceHistory behavior
+type Option func(*PriceHistory) error
+
+// WithKeyFields sets the item fields (KeyCode, KeyStore, KeyPrice, KeySize)
+// whose combination identifies an item, defaulting to DefaultKeyFields. An
+// item whose key isn't in the history is new, so including KeyPrice makes
+// any price change count as new.
+func WithKeyFields(fields []string) Option {
+	return func(h *PriceHistory) error {
+		if len(fields) == 0 {
+			return nil
+		}
+		keyFields := make([]string, 0, len(fields))
+		for _, field := range fields {
+			field = strings.ToLower(strings.TrimSpace(field))
+			switch field {
+			case KeyCode, KeyStore, KeyPrice, KeySize:
+				keyFields = append(keyFields, field)
+			default:
+				return fmt.Errorf("unknown dedup key field %q", field)
+			}
+		}
+		h.keyFields = keyFields
+		return nil
+	}
+}
+
+// Open loads the price history stored at path. A missing file is not an
+// error: the history simply starts empty, as on the first run.
+func Open(path string, opts ...Option) (*PriceHistory, error) {
+	h := &PriceHistory{path: path, keyFields: DefaultKeyFields, prices: make(map[string]map[string]int64)}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the configured state dir
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price history %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &h.prices); err != nil {
+		return nil, fmt.Errorf("failed to parse price history %s: %w", path, err)
+	}
+	if h.prices == nil {
+		h.prices = make(map[string]map[string]int64)
+	}
+	return h, nil
+}
+
+// DetectPriceChange reports the previously recorded price of item at its store
+// and whether the current price differs from it. An item with no recorded
+// price, or whose price can't be parsed, is reported as unchanged.
+func (h *PriceHistory) DetectPriceChange(item search.LiquorItem) (old string, changed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	previous, ok := h.prices[item.Query][h.itemKey(item)]
+	if !ok {
+		return "", false
+	}
+
+	current, err := ParseCents(item.Price)
+	if err != nil {
+		return FormatCents(previous), false
+	}
+	return FormatCents(previous), current != previous
+}
+
+// Seen reports whether item was in stock at its store in the last recorded search
+func (h *PriceHistory) Seen(item search.LiquorItem) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, ok := h.prices[item.Query][h.itemKey(item)]
+	return ok
+}
+
+// IsNewOrCheaper reports whether item newly appeared in stock at its store, or
+// is now cheaper there than when last recorded
+func (h *PriceHistory) IsNewOrCheaper(item search.LiquorItem) bool {
+	if !h.Seen(item) {
+		return true
+	}
+
+	old, changed := h.DetectPriceChange(item)
+	if !changed {
+		return false
+	}
+	previous, _ := ParseCents(old)
+	current, _ := ParseCents(item.Price)
+	return current < previous
+}
+
+// Record replaces the recorded prices for query with items, so stores that no
+// longer stock an item are forgotten and count as new if it reappears there.
+// Items without a parseable price are recorded as in stock at zero cents.
+func (h *PriceHistory) Record(query string, items []search.LiquorItem) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(items) == 0 {
+		delete(h.prices, query)
+		return
+	}
+
+	prices := make(map[string]int64, len(items))
+	for _, item := range items {
+		cents, _ := ParseCents(item.Price)
+		prices[h.itemKey(item)] = cents
+	}
+	h.prices[query] = prices
+}
+
+// Forget removes item from the recorded prices, so it counts as new the next
+// time it is found
+func (h *PriceHistory) Forget(item search.LiquorItem) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prices := h.prices[item.Query]
+	delete(prices, h.itemKey(item))
+	if len(prices) == 0 {
+		delete(h.prices, item.Query)
+	}
+}
+
+// Save writes the price history to its file, creating the state dir if needed
+func (h *PriceHistory) Save() error {
+	h.mu.Lock()
+	data, err := json.MarshalIndent(h.prices, "", "  ")
+	h.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode price history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated history
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write price history: %w", err)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("failed to write price history: %w", err)
+	}
+	return nil
+}
+
+// itemKey joins the configured key fields of item
+func (h *PriceHistory) itemKey(item search.LiquorItem) string {
+	parts := make([]string, len(h.keyFields))
+	for i, field := range h.keyFields {
+		switch field {
+		case KeyCode:
+			parts[i] = item.Code
+		case KeyStore:
+			parts[i] = item.Store
+		case KeyPrice:
+			parts[i] = item.Price
+			if cents, err := ParseCents(item.Price); err == nil {
+				parts[i] = FormatCents(cents)
+			}
+		case KeySize:
+			parts[i] = item.Size
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// ParseCents parses a price such as "$1,299.99" into cents
+func ParseCents(price string) (int64, error) {
+	s := strings.TrimSpace(price)
+	s = strings.TrimPrefix(s, "$")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, fmt.Errorf("empty price")
+	}
+
+	dollars, cents, hasCents := strings.Cut(s, ".")
+	if dollars == "" {
+		dollars = "0"
+	}
+	d, err := strconv.ParseInt(dollars, 10, 64)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid price %q", price)
+	}
+
+	var c int64
+	if hasCents {
+		if len(cents) == 0 || len(cents) > 2 {
+			return 0, fmt.Errorf("invalid price %q", price)
+		}
+		if len(cents) == 1 {
+			cents += "0"
+		}
+		c, err = strconv.ParseInt(cents, 10, 64)
+		if err != nil || c < 0 {
+			return 0, fmt.Errorf("invalid price %q", price)
+		}
+	}
+
+	return d*100 + c, nil
+}
+
+// FormatCents formats cents as a price such as "$1299.99"
+func FormatCents(cents int64) string {
+	return fmt.Sprintf("$%d.%02d", cents/100, cents%100)
+}
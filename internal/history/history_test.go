@@ -0,0 +1,177 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+func TestParseCents(t *testing.T) {
+	tests := map[string]int64{
+		"$64.95":    6495,
+		"$1,299.99": 129999,
+		"29.95":     2995,
+		" $30 ":     3000,
+		"$7.5":      750,
+	}
+	for in, want := range tests {
+		got, err := ParseCents(in)
+		if err != nil {
+			t.Errorf("ParseCents(%q) returned error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseCents(%q) = %d, want %d", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "$", "N/A", "$1.999", "$-5.00"} {
+		if _, err := ParseCents(in); err == nil {
+			t.Errorf("Expected ParseCents(%q) to fail", in)
+		}
+	}
+}
+
+func TestPriceHistory_FirstRun(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Expected missing history file to start empty, got: %v", err)
+	}
+
+	item := search.LiquorItem{Code: "0171B", Store: "Store A", Price: "$64.95", Query: "blanton"}
+	if !h.IsNewOrCheaper(item) {
+		t.Error("Expected every item to be new on the first run")
+	}
+	if _, changed := h.DetectPriceChange(item); changed {
+		t.Error("Expected no price change without history")
+	}
+}
+
+func TestPriceHistory_DetectPriceChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "alice.json")
+	h, err := Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+	h.Record("blanton", []search.LiquorItem{
+		{Code: "0171B", Store: "Store A", Price: "$1,299.99", Query: "blanton"},
+		{Code: "0171B", Store: "Store B", Price: "$64.95", Query: "blanton"},
+	})
+	if err := h.Save(); err != nil {
+		t.Fatalf("Failed to save history: %v", err)
+	}
+
+	// Reload to make sure prices survive a restart
+	h, err = Open(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen history: %v", err)
+	}
+
+	dropped := search.LiquorItem{Code: "0171B", Store: "Store A", Price: "$1,199.99", Query: "blanton"}
+	old, changed := h.DetectPriceChange(dropped)
+	if !changed || old != "$1299.99" {
+		t.Errorf("Expected change from $1299.99, got old=%q changed=%v", old, changed)
+	}
+	if !h.IsNewOrCheaper(dropped) {
+		t.Error("Expected a price drop to be notifiable")
+	}
+
+	raised := search.LiquorItem{Code: "0171B", Store: "Store B", Price: "$69.95", Query: "blanton"}
+	if h.IsNewOrCheaper(raised) {
+		t.Error("Expected a price increase not to be notifiable")
+	}
+
+	same := search.LiquorItem{Code: "0171B", Store: "Store B", Price: "$64.95", Query: "blanton"}
+	if h.IsNewOrCheaper(same) {
+		t.Error("Expected an unchanged price not to be notifiable")
+	}
+}
+
+func TestPriceHistory_StoreDisappears(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), "alice.json"))
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+
+	storeA := search.LiquorItem{Code: "0171B", Store: "Store A", Price: "$64.95", Query: "blanton"}
+	storeB := search.LiquorItem{Code: "0171B", Store: "Store B", Price: "$64.95", Query: "blanton"}
+	h.Record("blanton", []search.LiquorItem{storeA, storeB})
+
+	// Store B sells out, then restocks at the same price
+	h.Record("blanton", []search.LiquorItem{storeA})
+	if !h.IsNewOrCheaper(storeB) {
+		t.Error("Expected an item back in stock at a store to be notifiable")
+	}
+	if h.IsNewOrCheaper(storeA) {
+		t.Error("Expected an item still in stock at the same price not to be notifiable")
+	}
+
+	// Sold out everywhere
+	h.Record("blanton", nil)
+	if !h.IsNewOrCheaper(storeA) {
+		t.Error("Expected an item back in stock after selling out to be notifiable")
+	}
+}
+
+func TestPriceHistory_Forget(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), "alice.json"))
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+
+	storeA := search.LiquorItem{Code: "0171B", Store: "Store A", Price: "$64.95", Query: "blanton"}
+	storeB := search.LiquorItem{Code: "0171B", Store: "Store B", Price: "$64.95", Query: "blanton"}
+	h.Record("blanton", []search.LiquorItem{storeA, storeB})
+
+	h.Forget(storeA)
+	if !h.IsNewOrCheaper(storeA) {
+		t.Error("Expected a forgotten item to be notifiable")
+	}
+	if h.IsNewOrCheaper(storeB) {
+		t.Error("Expected other items to stay recorded")
+	}
+
+	h.Forget(storeB)
+	if _, ok := h.prices["blanton"]; ok {
+		t.Error("Expected a query without items to be removed")
+	}
+}
+
+func TestPriceHistory_KeyFields(t *testing.T) {
+	before := search.LiquorItem{Code: "0171B", Store: "Store A", Price: "$64.95", Size: "750 ML", Query: "blanton"}
+	raised := before
+	raised.Price = "$69.95"
+
+	tests := []struct {
+		name     string
+		fields   []string
+		realerts bool
+	}{
+		{"default key", nil, false},
+		{"code and store", []string{"code", "store"}, false},
+		{"with price", []string{"code", "store", "price"}, true},
+		{"with size", []string{"code", "store", "size"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := Open(filepath.Join(t.TempDir(), "alice.json"), WithKeyFields(tc.fields))
+			if err != nil {
+				t.Fatalf("Failed to open history: %v", err)
+			}
+			h.Record("blanton", []search.LiquorItem{before})
+
+			if got := h.IsNewOrCheaper(raised); got != tc.realerts {
+				t.Errorf("Expected price change to re-alert=%v, got %v", tc.realerts, got)
+			}
+			if h.IsNewOrCheaper(before) {
+				t.Error("Expected an unchanged item not to re-alert")
+			}
+		})
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "alice.json"), WithKeyFields([]string{"color"})); err == nil {
+		t.Error("Expected error for unknown key field")
+	}
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestSetComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	originalOut, originalLevel := log.StandardLogger().Out, log.GetLevel()
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetOutput(originalOut)
+		SetLevel(originalLevel)
+		_ = SetComponentLevels(nil)
+	})
+
+	SetLevel(log.InfoLevel)
+	if err := SetComponentLevels(map[string]string{"search": "debug"}); err != nil {
+		t.Fatalf("Failed to set component levels: %v", err)
+	}
+
+	For(Search).Debug("search debug message")
+	For(Runner).Debug("runner debug message")
+	For(Runner).Info("runner info message")
+	log.Debug("global debug message")
+
+	out := buf.String()
+	if !strings.Contains(out, "search debug message") || !strings.Contains(out, "component=search") {
+		t.Errorf("Expected search debug output, got: %s", out)
+	}
+	if strings.Contains(out, "runner debug message") || strings.Contains(out, "global debug message") {
+		t.Errorf("Expected other components to stay at info, got: %s", out)
+	}
+	if !strings.Contains(out, "runner info message") {
+		t.Errorf("Expected runner info output, got: %s", out)
+	}
+}
+
+func TestSetComponentLevels_Invalid(t *testing.T) {
+	t.Cleanup(func() { _ = SetComponentLevels(nil) })
+
+	if err := SetComponentLevels(map[string]string{"scraper": "debug"}); err == nil {
+		t.Error("Expected error for unknown component")
+	}
+	if err := SetComponentLevels(map[string]string{"search": "loud"}); err == nil {
+		t.Error("Expected error for invalid level")
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	var buf bytes.Buffer
+	originalOut, originalFormatter := log.StandardLogger().Out, log.StandardLogger().Formatter
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetOutput(originalOut)
+		log.SetFormatter(originalFormatter)
+	})
+
+	if err := SetFormat(FormatJSON); err != nil {
+		t.Fatalf("Failed to set JSON format: %v", err)
+	}
+	For(Runner).WithField("user", "alice").Info("runner info message")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if line["msg"] != "runner info message" || line["component"] != "runner" || line["user"] != "alice" {
+		t.Errorf("Unexpected JSON log line: %v", line)
+	}
+
+	if err := SetFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
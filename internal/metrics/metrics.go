@@ -0,0 +1,152 @@
+// Package metrics collects search statistics and exposes them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DurationBuckets are the upper bounds, in seconds, of the search duration histogram
+var DurationBuckets = []float64{0.5, 1, 2, 5, 10, 30, 60, 120}
+
+// histogram counts observations into cumulative buckets
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Metrics holds per-user search counters, the number of items in stock and a
+// search duration histogram. A nil *Metrics ignores every update, so callers
+// don't need to check whether metrics are enabled.
+type Metrics struct {
+	mu        sync.Mutex
+	attempted map[string]uint64
+	succeeded map[string]uint64
+	failed    map[string]uint64
+	inStock   map[string]int
+	durations map[string]*histogram
+}
+
+// New creates an empty set of metrics
+func New() *Metrics {
+	return &Metrics{
+		attempted: make(map[string]uint64),
+		succeeded: make(map[string]uint64),
+		failed:    make(map[string]uint64),
+		inStock:   make(map[string]int),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// ObserveSearch records a search by user that took d and failed if err is non-nil
+func (m *Metrics) ObserveSearch(user string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.attempted[user]++
+	if err != nil {
+		m.failed[user]++
+	} else {
+		m.succeeded[user]++
+	}
+
+	h, ok := m.durations[user]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(DurationBuckets))}
+		m.durations[user] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range DurationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// SetItemsInStock records how many found items (item and store pairs) user's last search cycle found in stock
+func (m *Metrics) SetItemsInStock(user string, n int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inStock[user] = n
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Write(w)
+}
+
+// Write writes the metrics to w in the Prometheus text exposition format
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	writeCounter(&b, "gfl_searches_attempted_total", "Item searches attempted.", m.attempted)
+	writeCounter(&b, "gfl_searches_succeeded_total", "Item searches that completed.", m.succeeded)
+	writeCounter(&b, "gfl_searches_failed_total", "Item searches that failed.", m.failed)
+
+	b.WriteString("# HELP gfl_items_in_stock Found items in stock in the last search cycle.\n")
+	b.WriteString("# TYPE gfl_items_in_stock gauge\n")
+	for _, user := range sortedKeys(m.inStock) {
+		fmt.Fprintf(&b, "gfl_items_in_stock{user=%s} %d\n", quoteLabel(user), m.inStock[user])
+	}
+
+	b.WriteString("# HELP gfl_search_duration_seconds Time taken by item searches.\n")
+	b.WriteString("# TYPE gfl_search_duration_seconds histogram\n")
+	for _, user := range sortedKeys(m.durations) {
+		h := m.durations[user]
+		label := quoteLabel(user)
+		for i, bound := range DurationBuckets {
+			fmt.Fprintf(&b, "gfl_search_duration_seconds_bucket{user=%s,le=\"%s\"} %d\n",
+				label, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&b, "gfl_search_duration_seconds_bucket{user=%s,le=\"+Inf\"} %d\n", label, h.count)
+		fmt.Fprintf(&b, "gfl_search_duration_seconds_sum{user=%s} %s\n", label, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "gfl_search_duration_seconds_count{user=%s} %d\n", label, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCounter writes a per-user counter
+func writeCounter(b *strings.Builder, name, help string, values map[string]uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	for _, user := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{user=%s} %d\n", name, quoteLabel(user), values[user])
+	}
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines
+func quoteLabel(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + value + `"`
+}
+
+// sortedKeys returns the keys of m in order, so output is stable
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
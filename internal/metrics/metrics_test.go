@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_Write(t *testing.T) {
+	m := New()
+	m.ObserveSearch("alice", 1500*time.Millisecond, nil)
+	m.ObserveSearch("alice", 45*time.Second, errors.New("timeout"))
+	m.ObserveSearch(`bob "the" builder`, 200*time.Millisecond, nil)
+	m.SetItemsInStock("alice", 3)
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE gfl_searches_attempted_total counter\n",
+		`gfl_searches_attempted_total{user="alice"} 2` + "\n",
+		`gfl_searches_succeeded_total{user="alice"} 1` + "\n",
+		`gfl_searches_failed_total{user="alice"} 1` + "\n",
+		`gfl_searches_attempted_total{user="bob \"the\" builder"} 1` + "\n",
+		"# TYPE gfl_items_in_stock gauge\n",
+		`gfl_items_in_stock{user="alice"} 3` + "\n",
+		"# TYPE gfl_search_duration_seconds histogram\n",
+		`gfl_search_duration_seconds_bucket{user="alice",le="1"} 0` + "\n",
+		`gfl_search_duration_seconds_bucket{user="alice",le="2"} 1` + "\n",
+		`gfl_search_duration_seconds_bucket{user="alice",le="60"} 2` + "\n",
+		`gfl_search_duration_seconds_bucket{user="alice",le="+Inf"} 2` + "\n",
+		`gfl_search_duration_seconds_sum{user="alice"} 46.5` + "\n",
+		`gfl_search_duration_seconds_count{user="alice"} 2` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	m := New()
+	m.SetItemsInStock("alice", 1)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected Prometheus text content type, got %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `gfl_items_in_stock{user="alice"} 1`) {
+		t.Errorf("Expected metrics in body, got: %s", rec.Body.String())
+	}
+}
+
+func TestMetrics_NilIsDisabled(t *testing.T) {
+	var m *Metrics
+	m.ObserveSearch("alice", time.Second, nil)
+	m.SetItemsInStock("alice", 1)
+}
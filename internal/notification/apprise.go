@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AppriseNotifier sends notifications through an Apprise API server, which
+// delivers them to every service given as an Apprise URL (e.g. tgram://token/chatid)
+type AppriseNotifier struct {
+	notifyURL string
+	urls      []string
+	client    *http.Client
+}
+
+// apprisePayload is the JSON body of a stateless Apprise API notification
+type apprisePayload struct {
+	URLs  []string `json:"urls"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Type  string   `json:"type"`
+}
+
+// NewAppriseNotifier creates a new Apprise notifier posting to the Apprise API
+// server at endpoint, which notifies each of the Apprise URLs in urls
+func NewAppriseNotifier(endpoint string, urls []string) (*AppriseNotifier, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid apprise endpoint %q, must be an http:// or https:// URL", endpoint)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("apprise requires at least one URL in urls")
+	}
+
+	return &AppriseNotifier{
+		notifyURL: strings.TrimSuffix(endpoint, "/") + "/notify/",
+		urls:      urls,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify sends the subject and message to every Apprise URL
+func (a *AppriseNotifier) Notify(ctx context.Context, subject, message string) error {
+	jsonData, err := json.Marshal(apprisePayload{URLs: a.urls, Title: subject, Body: message, Type: "info"})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.notifyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req) // #nosec G704 -- Apprise endpoint is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return statusError("apprise", resp)
+	}
+
+	return nil
+}
+
+// splitAppriseURLs splits a list of Apprise URLs separated by commas, spaces or newlines
+func splitAppriseURLs(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+}
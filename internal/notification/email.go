@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Email TLS modes, selected with the optional tls_mode credential
+const (
+	// EmailStartTLS connects in plain text and upgrades with STARTTLS (default, usually port 587)
+	EmailStartTLS = "starttls"
+	// EmailTLS connects over TLS from the start (usually port 465)
+	EmailTLS = "tls"
+)
+
+// EmailNotifier sends notifications as plain text email over SMTP
+type EmailNotifier struct {
+	host        string
+	port        string
+	username    string
+	password    string
+	from        string
+	to          []string
+	implicitTLS bool
+	tlsConfig   *tls.Config
+	timeout     time.Duration
+}
+
+// NewEmailNotifier creates a new email notifier. tlsMode is EmailStartTLS (or empty) or EmailTLS.
+func NewEmailNotifier(host, port, username, password, from string, to []string, tlsMode string) (*EmailNotifier, error) {
+	var implicitTLS bool
+	switch strings.ToLower(tlsMode) {
+	case "", EmailStartTLS:
+	case EmailTLS:
+		implicitTLS = true
+	default:
+		return nil, fmt.Errorf("invalid email tls_mode %q, must be %s or %s", tlsMode, EmailStartTLS, EmailTLS)
+	}
+
+	return &EmailNotifier{
+		host:        host,
+		port:        port,
+		username:    username,
+		password:    password,
+		from:        from,
+		to:          to,
+		implicitTLS: implicitTLS,
+		tlsConfig:   &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12},
+		timeout:     30 * time.Second,
+	}, nil
+}
+
+// Notify sends an email with subject and message to every recipient
+func (e *EmailNotifier) Notify(ctx context.Context, subject, message string) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(e.host, e.port)
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if e.implicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: e.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if !e.implicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(e.tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+		return fmt.Errorf("SMTP authentication failed: %w", err)
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(e.buildMessage(subject, message)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage formats subject and message as a plain text email
+func (e *EmailNotifier) buildMessage(subject, message string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + e.from + "\r\n")
+	b.WriteString("To: " + strings.Join(e.to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// splitAddresses splits a comma-separated list of email addresses
+func splitAddresses(list string) []string {
+	var addresses []string
+	for _, address := range strings.Split(list, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,170 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// fakeSMTPServer accepts SMTP sessions over implicit TLS and records the
+// envelope and data of every message
+type fakeSMTPServer struct {
+	listener net.Listener
+	rootCAs  *x509.CertPool
+
+	mu         sync.Mutex
+	recipients []string
+	data       string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	// Borrow httptest's self-signed localhost certificate
+	certServer := httptest.NewTLSServer(nil)
+	cert := certServer.TLS.Certificates[0]
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(certServer.Certificate())
+	certServer.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeSMTPServer{listener: listener, rootCAs: rootCAs}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			reply("235 authenticated")
+		case strings.HasPrefix(cmd, "MAIL FROM"):
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO"):
+			s.mu.Lock()
+			s.recipients = append(s.recipients, strings.TrimSpace(line[len("RCPT TO:"):]))
+			s.mu.Unlock()
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil || dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	notifier, err := NewEmailNotifier(host, port, "user", "secret", "gfl@example.com",
+		[]string{"alice@example.com", "bob@example.com"}, EmailTLS)
+	if err != nil {
+		t.Fatalf("Failed to create email notifier: %v", err)
+	}
+	notifier.tlsConfig = &tls.Config{RootCAs: server.rootCAs, ServerName: host, MinVersion: tls.VersionTLS12}
+
+	if err := notifier.Notify(context.Background(), "GFL - Found Blanton's!", "Found Blanton's at Store A\nfor $64.95"); err != nil {
+		t.Fatalf("Expected email to be sent, got: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if strings.Join(server.recipients, ",") != "<alice@example.com>,<bob@example.com>" {
+		t.Errorf("Expected both recipients, got %v", server.recipients)
+	}
+	for _, want := range []string{"Subject: GFL - Found Blanton's!\r\n", "To: alice@example.com, bob@example.com\r\n", "Found Blanton's at Store A\r\nfor $64.95"} {
+		if !strings.Contains(server.data, want) {
+			t.Errorf("Expected message data to contain %q, got: %s", want, server.data)
+		}
+	}
+}
+
+func TestNewNotificationManager_Email(t *testing.T) {
+	credential := map[string]string{
+		"smtp_host": "smtp.example.com",
+		"smtp_port": "587",
+		"username":  "user",
+		"password":  "secret",
+		"from":      "gfl@example.com",
+		"to":        "alice@example.com, bob@example.com",
+	}
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{{Type: "email", Credential: credential}})
+	if err != nil {
+		t.Fatalf("Expected valid email config, got: %v", err)
+	}
+	email, ok := manager.notifiers[0].(*EmailNotifier)
+	if !ok {
+		t.Fatalf("Expected an EmailNotifier, got %T", manager.notifiers[0])
+	}
+	if email.implicitTLS || len(email.to) != 2 {
+		t.Errorf("Expected STARTTLS with 2 recipients, got implicitTLS=%v to=%v", email.implicitTLS, email.to)
+	}
+
+	for key := range credential {
+		missing := make(map[string]string)
+		for k, v := range credential {
+			if k != key {
+				missing[k] = v
+			}
+		}
+		_, err := NewNotificationManager([]config.NotificationConfig{{Type: "email", Credential: missing}})
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected error naming missing %s, got: %v", key, err)
+		}
+	}
+
+	credential["tls_mode"] = "ssl3"
+	if _, err := NewNotificationManager([]config.NotificationConfig{{Type: "email", Credential: credential}}); err == nil {
+		t.Error("Expected error for invalid tls_mode")
+	}
+}
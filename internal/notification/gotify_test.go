@@ -0,0 +1,183 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// gotifyMessage is the subset of the Gotify message payload checked by tests
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+	Extras   map[string]struct {
+		ContentType string `json:"contentType"`
+	} `json:"extras"`
+}
+
+// newGotifyServer starts a Gotify server recording the messages it receives
+func newGotifyServer(t *testing.T) (*httptest.Server, *[]gotifyMessage) {
+	t.Helper()
+	var messages []gotifyMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg gotifyMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		messages = append(messages, msg)
+	}))
+	t.Cleanup(server.Close)
+	return server, &messages
+}
+
+func newGotifyMarkdownManager(t *testing.T, contentType string) (*NotificationManager, *[]gotifyMessage) {
+	t.Helper()
+	server, messages := newGotifyServer(t)
+
+	credential := map[string]string{"token": "app-token"}
+	if contentType != "" {
+		credential["content_type"] = contentType
+	}
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "gotify", Endpoint: server.URL, Condense: true, Credential: credential},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	return manager, messages
+}
+
+var gotifyTestItems = []search.LiquorItem{
+	{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()},
+	{Name: "EAGLE RARE", Store: "Store B", Price: "$39.99", Date: time.Now()},
+}
+
+func TestGotifyNotifier_Markdown(t *testing.T) {
+	manager, messages := newGotifyMarkdownManager(t, "text/markdown")
+
+	if err := manager.NotifyFoundItems(context.Background(), gotifyTestItems); err != nil {
+		t.Fatalf("Expected notification to succeed, got: %v", err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(*messages))
+	}
+	msg := (*messages)[0]
+	if got := msg.Extras["client::display"].ContentType; got != "text/markdown" {
+		t.Errorf("Expected client::display contentType text/markdown, got %q", got)
+	}
+	for _, want := range []string{"1. **BLANTON'S** at Store A for $64.95\n", "2. **EAGLE RARE** at Store B for $39.99\n"} {
+		if !strings.Contains(msg.Message, want) {
+			t.Errorf("Expected markdown list item %q, got: %s", want, msg.Message)
+		}
+	}
+}
+
+func TestGotifyNotifier_PlainText(t *testing.T) {
+	manager, messages := newGotifyMarkdownManager(t, "")
+
+	if err := manager.NotifyFoundItems(context.Background(), gotifyTestItems); err != nil {
+		t.Fatalf("Expected notification to succeed, got: %v", err)
+	}
+
+	msg := (*messages)[0]
+	if msg.Extras != nil {
+		t.Errorf("Expected no extras without content_type, got %v", msg.Extras)
+	}
+	if strings.Contains(msg.Message, "**") {
+		t.Errorf("Expected plain text message, got: %s", msg.Message)
+	}
+}
+
+func TestNewNotificationManager_GotifyContentType(t *testing.T) {
+	_, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "gotify", Endpoint: "https://gotify.example.com", Credential: map[string]string{"token": "t", "content_type": "text/html"}},
+	})
+	if err == nil {
+		t.Error("Expected error for unsupported content_type")
+	}
+}
+
+// TestNewNotificationManager_MixedCondenseChannels tests that each configured
+// channel honors its own condense setting rather than the first config's
+func TestNewNotificationManager_MixedCondenseChannels(t *testing.T) {
+	individualServer, individual := newGotifyServer(t)
+	condensedServer, condensed := newGotifyServer(t)
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "gotify", Endpoint: individualServer.URL, Condense: false, Credential: map[string]string{"token": "individual"}},
+		{Type: "gotify", Endpoint: condensedServer.URL, Condense: true, Credential: map[string]string{"token": "condensed"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	if err := manager.NotifyFoundItems(context.Background(), gotifyTestItems); err != nil {
+		t.Fatalf("Expected notification to succeed, got: %v", err)
+	}
+
+	if len(*individual) != 2 {
+		t.Fatalf("Expected 2 individual messages, got %d", len(*individual))
+	}
+	if (*individual)[0].Title != "GFL - Found BLANTON'S!" || (*individual)[1].Title != "GFL - Found EAGLE RARE!" {
+		t.Errorf("Expected one message per item, got %+v", *individual)
+	}
+	if len(*condensed) != 1 || (*condensed)[0].Title != "GFL - Found 2 items!" {
+		t.Errorf("Expected 1 condensed message, got %+v", *condensed)
+	}
+}
+
+func TestNewNotificationManager_GotifyPriority(t *testing.T) {
+	testCases := []struct {
+		name     string
+		priority string
+		expected int
+	}{
+		{name: "default", expected: 5},
+		{name: "configured", priority: "8", expected: 8},
+		{name: "lowest", priority: "0", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, messages := newGotifyServer(t)
+			credential := map[string]string{"token": "app-token"}
+			if tc.priority != "" {
+				credential["priority"] = tc.priority
+			}
+			manager, err := NewNotificationManager([]config.NotificationConfig{
+				{Type: "gotify", Endpoint: server.URL, Credential: credential},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create notification manager: %v", err)
+			}
+
+			if err := manager.NotifyFound(context.Background(), gotifyTestItems[0]); err != nil {
+				t.Fatalf("Expected notification to succeed, got: %v", err)
+			}
+			if len(*messages) != 1 || (*messages)[0].Priority != tc.expected {
+				t.Errorf("Expected 1 message with priority %d, got %+v", tc.expected, *messages)
+			}
+		})
+	}
+}
+
+func TestNewNotificationManager_GotifyInvalidPriority(t *testing.T) {
+	for _, priority := range []string{"high", "-1", "11", "5.5"} {
+		_, err := NewNotificationManager([]config.NotificationConfig{
+			{Type: "gotify", Endpoint: "https://gotify.example.com", Credential: map[string]string{"token": "t", "priority": priority}},
+		})
+		if err == nil || !strings.Contains(err.Error(), "invalid gotify priority") {
+			t.Errorf("Expected invalid priority error for %q, got: %v", priority, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// matrixMessage is the Matrix m.room.message content sent by the Matrix service
+type matrixMessage struct {
+	MsgType string `json:"msgtype"`
+	Body    string `json:"body"`
+}
+
+func newMatrixManager(t *testing.T, condense bool) (*NotificationManager, *[]matrixMessage, *[]*http.Request) {
+	t.Helper()
+	var messages []matrixMessage
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg matrixMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		messages = append(messages, msg)
+		requests = append(requests, r)
+		_, _ = w.Write([]byte(`{"event_id": "$event"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{
+			Type:     "matrix",
+			Condense: condense,
+			Credential: map[string]string{
+				"homeserver":   server.URL,
+				"user_id":      "@gfl:example.com",
+				"room_id":      "!hunt:example.com",
+				"access_token": "syt_secret",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	return manager, &messages, &requests
+}
+
+func TestMatrixNotifier_Notify(t *testing.T) {
+	manager, messages, requests := newMatrixManager(t, false)
+
+	item := search.LiquorItem{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()}
+	if err := manager.NotifyFound(context.Background(), item); err != nil {
+		t.Fatalf("Expected Matrix notification to succeed, got: %v", err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(*messages))
+	}
+	req := (*requests)[0]
+	if req.Method != http.MethodPut || !strings.HasPrefix(req.URL.EscapedPath(), "/_matrix/client/v3/rooms/%21hunt:example.com/send/m.room.message/") {
+		t.Errorf("Expected PUT to the room's send endpoint, got %s %s", req.Method, req.URL.EscapedPath())
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer syt_secret" {
+		t.Errorf("Expected bearer access token, got %q", got)
+	}
+
+	msg := (*messages)[0]
+	if msg.MsgType != "m.text" {
+		t.Errorf("Expected a text message, got %+v", msg)
+	}
+	if !strings.HasPrefix(msg.Body, "GFL - Found BLANTON'S!\n\nFound BLANTON'S at Store A") {
+		t.Errorf("Expected body with subject and message, got %q", msg.Body)
+	}
+}
+
+func TestMatrixNotifier_CondensedList(t *testing.T) {
+	manager, messages, _ := newMatrixManager(t, true)
+
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()},
+		{Name: "EAGLE RARE", Store: "Store B & Annex", Price: "$39.99", Date: time.Now()},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected Matrix notification to succeed, got: %v", err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("Expected 1 condensed message, got %d", len(*messages))
+	}
+	msg := (*messages)[0]
+	want := "1. BLANTON'S at Store A for $64.95\n2. EAGLE RARE at Store B & Annex for $39.99"
+	if !strings.Contains(msg.Body, want) {
+		t.Errorf("Expected condensed items as a numbered list, got: %s", msg.Body)
+	}
+	if strings.Contains(msg.Body, "**") {
+		t.Errorf("Expected plain body without markdown, got: %s", msg.Body)
+	}
+}
+
+func TestNewNotificationManager_MatrixInvalidHomeserver(t *testing.T) {
+	_, err := NewNotificationManager([]config.NotificationConfig{{
+		Type: "matrix",
+		Credential: map[string]string{
+			"homeserver":   "://matrix.example.com",
+			"user_id":      "@gfl:example.com",
+			"room_id":      "!hunt:example.com",
+			"access_token": "syt_secret",
+		},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "invalid matrix homeserver") {
+		t.Errorf("Expected invalid homeserver error, got: %v", err)
+	}
+}
+
+func TestNewNotificationManager_MatrixMissingCredentials(t *testing.T) {
+	full := map[string]string{
+		"homeserver":   "https://matrix.example.com",
+		"user_id":      "@gfl:example.com",
+		"room_id":      "!hunt:example.com",
+		"access_token": "syt_secret",
+	}
+
+	for key := range full {
+		credential := make(map[string]string)
+		for k, v := range full {
+			if k != key {
+				credential[k] = v
+			}
+		}
+		_, err := NewNotificationManager([]config.NotificationConfig{{Type: "matrix", Credential: credential}})
+		if err == nil || !strings.Contains(err.Error(), "matrix requires "+key) {
+			t.Errorf("Expected missing %s error, got: %v", key, err)
+		}
+	}
+}
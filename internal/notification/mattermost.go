@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MattermostNotifier posts markdown notifications to a Mattermost incoming webhook
+type MattermostNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// mattermostPayload is the JSON body of a Mattermost incoming webhook post
+type mattermostPayload struct {
+	Text string `json:"text"`
+}
+
+// NewMattermostNotifier creates a new Mattermost notifier posting to webhookURL
+func NewMattermostNotifier(webhookURL string) *MattermostNotifier {
+	return &MattermostNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Markdown reports that Mattermost renders messages as markdown
+func (m *MattermostNotifier) Markdown() bool {
+	return true
+}
+
+// Notify posts the subject as a heading followed by the message
+func (m *MattermostNotifier) Notify(ctx context.Context, subject, message string) error {
+	jsonData, err := json.Marshal(mattermostPayload{Text: "#### " + subject + "\n\n" + message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.webhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req) // #nosec G704 -- webhook URL is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("mattermost returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+func TestMattermostNotifier_NotifyFoundItems(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "mattermost", Condense: true, Credential: map[string]string{"webhook_url": server.URL}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()},
+		{Name: "EAGLE RARE", Store: "Store B", Price: "$39.99", Date: time.Now()},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected Mattermost notification to succeed, got: %v", err)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 post, got %d", len(payloads))
+	}
+	if len(payloads[0]) != 1 {
+		t.Errorf("Expected only a text field, got %v", payloads[0])
+	}
+	text, _ := payloads[0]["text"].(string)
+	for _, want := range []string{"#### GFL - Found 2 items!\n\n", "1. **BLANTON'S** at Store A for $64.95", "2. **EAGLE RARE** at Store B for $39.99"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text to contain %q, got: %s", want, text)
+		}
+	}
+}
+
+func TestMattermostNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := NewMattermostNotifier(server.URL).Notify(context.Background(), "subject", "message")
+	if err == nil || !strings.Contains(err.Error(), "status code 404") {
+		t.Errorf("Expected status code error, got: %v", err)
+	}
+}
+
+func TestNewNotificationManager_WebhookURLRequired(t *testing.T) {
+	for _, notificationType := range []string{"teams", "mattermost"} {
+		_, err := NewNotificationManager([]config.NotificationConfig{{Type: notificationType, Credential: map[string]string{}}})
+		if err == nil || !strings.Contains(err.Error(), notificationType+" requires webhook_url") {
+			t.Errorf("Expected missing webhook_url error for %s, got: %v", notificationType, err)
+		}
+	}
+}
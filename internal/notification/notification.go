@@ -4,44 +4,87 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
+	"unicode"
 
 	"github.com/nikoksr/notify"
 	"github.com/nikoksr/notify/service/discord"
+	"github.com/nikoksr/notify/service/matrix"
 	"github.com/nikoksr/notify/service/pushbullet"
-	"github.com/nikoksr/notify/service/pushover"
 	"github.com/nikoksr/notify/service/slack"
 	"github.com/nikoksr/notify/service/telegram"
-	log "github.com/sirupsen/logrus"
+	"maunium.net/go/mautrix/id"
 
+	"github.com/toozej/go-find-liquor/internal/logging"
 	"github.com/toozej/go-find-liquor/internal/search"
 	"github.com/toozej/go-find-liquor/pkg/config"
 )
 
+// logger is the notification component logger, whose level can be set separately
+var logger = logging.For(logging.Notification)
+
+// ErrUndelivered is returned by NotifyFoundItems when no notifier delivered
+// any of the found items, so callers can keep the find from being lost
+var ErrUndelivered = errors.New("found items were not delivered by any notifier")
+
 // Notifier is an interface for sending notifications
 type Notifier interface {
 	Notify(ctx context.Context, subject, message string) error
 }
 
+// ItemNotifier is implemented by notifiers that send the found items themselves
+// along with the formatted message
+type ItemNotifier interface {
+	NotifyWithItems(ctx context.Context, subject, message string, items []search.LiquorItem) error
+}
+
+// MarkdownNotifier is implemented by notifiers that can render markdown messages
+type MarkdownNotifier interface {
+	Markdown() bool
+}
+
+// AlertNotifier is implemented by notifiers that support a per-notification priority and sound
+type AlertNotifier interface {
+	NotifyWithAlert(ctx context.Context, subject, message string, alert config.ItemAlert) error
+}
+
 // GotifyNotifier implements direct Gotify API integration
 type GotifyNotifier struct {
 	endpoint string
 	token    string
+	// contentType sets how Gotify clients display messages, e.g. "text/markdown"
+	contentType string
+	// priority of sent messages, from 0 (lowest) to 10
+	priority int
 	client   *http.Client
 }
 
+// defaultGotifyPriority is the priority of Gotify messages when none is configured
+const defaultGotifyPriority = 5
+
 // NewGotifyNotifier creates a new Gotify notifier
 func NewGotifyNotifier(endpoint, token string) *GotifyNotifier {
 	return &GotifyNotifier{
 		endpoint: strings.TrimSuffix(endpoint, "/"),
 		token:    token,
+		priority: defaultGotifyPriority,
 		client:   &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
+// Markdown reports whether messages are displayed as markdown
+func (g *GotifyNotifier) Markdown() bool {
+	return g.contentType == "text/markdown"
+}
+
 // Notify sends a notification to Gotify
 func (g *GotifyNotifier) Notify(ctx context.Context, subject, message string) error {
 	url := fmt.Sprintf("%s/message?token=%s", g.endpoint, g.token)
@@ -49,7 +92,12 @@ func (g *GotifyNotifier) Notify(ctx context.Context, subject, message string) er
 	payload := map[string]interface{}{
 		"title":    subject,
 		"message":  message,
-		"priority": 5,
+		"priority": g.priority,
+	}
+	if g.contentType != "" {
+		payload["extras"] = map[string]interface{}{
+			"client::display": map[string]string{"contentType": g.contentType},
+		}
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -71,7 +119,7 @@ func (g *GotifyNotifier) Notify(ctx context.Context, subject, message string) er
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("gotify returned status code %d", resp.StatusCode)
+		return statusError("gotify", resp)
 	}
 
 	return nil
@@ -93,14 +141,14 @@ func NewNikoksrNotifier() *NikoksrNotifier {
 func (n *NikoksrNotifier) AddSlack(token string, channelID string) {
 	service := slack.New(token)
 	service.AddReceivers(channelID)
-	n.notifier.UseServices(service)
+	n.use("slack", service)
 }
 
 // AddTelegram adds Telegram notification service
 func (n *NikoksrNotifier) AddTelegram(token string, chatID int64) {
 	service, _ := telegram.New(token)
 	service.AddReceivers(chatID)
-	n.notifier.UseServices(service)
+	n.use("telegram", service)
 }
 
 // AddDiscord adds Discord notification service
@@ -108,284 +156,955 @@ func (n *NikoksrNotifier) AddDiscord(token string, channelID string) {
 	service := discord.New()
 	_ = service.AuthenticateWithBotToken(token)
 	service.AddReceivers(channelID)
-	n.notifier.UseServices(service)
+	n.use("discord", service)
 }
 
-// AddPushover adds Pushover notification service
-func (n *NikoksrNotifier) AddPushover(token string, recipientID string) {
-	service := pushover.New(token)
-	service.AddReceivers(recipientID)
-	n.notifier.UseServices(service)
-}
-
-// AddPushover adds Pushover notification service
+// AddPushbullet adds Pushbullet notification service
 func (n *NikoksrNotifier) AddPushbullet(token string, deviceNickname string) {
 	service := pushbullet.New(token)
 	service.AddReceivers(deviceNickname)
-	n.notifier.UseServices(service)
+	n.use("pushbullet", service)
+}
+
+// AddMatrix adds Matrix notification service, posting to roomID on homeServer
+// as userID
+func (n *NikoksrNotifier) AddMatrix(userID, roomID, homeServer, accessToken string) error {
+	service, err := matrix.New(id.UserID(userID), id.RoomID(roomID), homeServer, accessToken)
+	if err != nil {
+		return fmt.Errorf("invalid matrix homeserver: %w", err)
+	}
+	n.use("matrix", &matrixService{service: service})
+	return nil
+}
+
+// matrixService sends the subject as the first line of the message, which the
+// nikoksr/notify Matrix service would otherwise drop
+type matrixService struct {
+	service *matrix.Matrix
+}
+
+// Send sends the subject and message to the Matrix room
+func (m *matrixService) Send(ctx context.Context, subject, message string) error {
+	return m.service.Send(ctx, "", subject+"\n\n"+message)
+}
+
+// use adds a service, guarded so that a panic while sending is returned as an error
+func (n *NikoksrNotifier) use(name string, service notify.Notifier) {
+	n.notifier.UseServices(&recoveringService{name: name, service: service})
 }
 
 // Notify sends a notification using nikoksr/notify
-func (n *NikoksrNotifier) Notify(ctx context.Context, subject, message string) error {
+func (n *NikoksrNotifier) Notify(ctx context.Context, subject, message string) (err error) {
+	defer recoverSendPanic("nikoksr/notify", &err)
 	return n.notifier.Send(ctx, subject, message)
 }
 
+// recoveringService wraps a nikoksr/notify service, converting a panic in Send
+// into an error. nikoksr/notify sends to each service in its own goroutine, so
+// the panic has to be recovered there rather than in NikoksrNotifier.Notify.
+type recoveringService struct {
+	name    string
+	service notify.Notifier
+}
+
+// Send sends through the wrapped service
+func (r *recoveringService) Send(ctx context.Context, subject, message string) (err error) {
+	defer recoverSendPanic(r.name, &err)
+	return r.service.Send(ctx, subject, message)
+}
+
+// recoverSendPanic recovers a panic while sending through service, logging it
+// and storing it in *err. It must be called directly by defer.
+func recoverSendPanic(service string, err *error) {
+	if p := recover(); p != nil {
+		logger.WithField("service", service).Errorf("Recovered from panic while sending notification: %v\n%s", p, debug.Stack())
+		*err = fmt.Errorf("%s notification panicked: %v", service, p)
+	}
+}
+
+// defaultHeartbeatTemplate renders the heartbeat message when no custom template is configured
+var defaultHeartbeatTemplate = template.Must(template.New("heartbeat").Parse(`GFL is still running and searching for {{.ItemsWatched}} item(s) across {{.Users}} user(s). ` +
+	`Last find: {{if .LastFind.IsZero}}never{{else}}{{.LastFind.Format "2006-01-02 15:04:05"}}{{end}}. Uptime: {{.Uptime}}` +
+	`{{if .HealthCheckItem}}. Health check: searched for '{{.HealthCheckItem}}' ` +
+	`{{if .HealthCheckFound}}and found it in stock{{else}}but it was not found{{end}}{{end}}`))
+
+// HeartbeatStats holds the values available to the heartbeat message template
+type HeartbeatStats struct {
+	User             string
+	Users            int
+	ItemsWatched     int
+	LastFind         time.Time
+	Uptime           time.Duration
+	HealthCheckItem  string
+	HealthCheckFound bool
+}
+
 // NotificationManager manages multiple notification providers
 type NotificationManager struct {
 	notifiers []Notifier
 	condense  bool
+	// condensed holds the condense setting of notifiers built from notification
+	// configs, keyed by index in notifiers. Other notifiers use condense.
+	condensed map[int]bool
+	// templates holds the message templates of notifiers built from notification
+	// configs that set them, keyed by index in notifiers
+	templates         map[int]*itemTemplates
+	heartbeatTemplate *template.Template
+	missingPrice      string
+	location          *time.Location
+	itemAlerts        map[string]config.ItemAlert
+	allowedTypes      map[string]bool
+	retries           int
+	retryDelay        time.Duration
+	condensedFallback bool
+	// groupStores lists an item found at more than this many stores once in
+	// condensed notifications (0 = disabled), with every store if listStores
+	groupStores     int
+	listStores      bool
+	itemDetails     bool
+	productNameCase string
+	dryRun          bool
+}
+
+// ManagerOption configures optional NotificationManager behavior
+type ManagerOption func(*NotificationManager) error
+
+// WithHeartbeatTemplate renders heartbeat messages with a custom text/template
+// executed against HeartbeatStats. An empty text keeps the default message.
+func WithHeartbeatTemplate(text string) ManagerOption {
+	return func(m *NotificationManager) error {
+		if text == "" {
+			return nil
+		}
+		tmpl, err := template.New("heartbeat").Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid heartbeat template: %w", err)
+		}
+		m.heartbeatTemplate = tmpl
+		return nil
+	}
+}
+
+// WithNotifiers adds notifiers in addition to those built from the notification configs
+func WithNotifiers(notifiers ...Notifier) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.notifiers = append(m.notifiers, notifiers...)
+		return nil
+	}
+}
+
+// WithMissingPrice sets the text shown in place of the price clause for items
+// listed without a bottle price, e.g. "(price N/A)". By default the clause is omitted.
+func WithMissingPrice(placeholder string) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.missingPrice = strings.TrimSpace(placeholder)
+		return nil
+	}
+}
+
+// WithItemDetails includes each found item's size and proof in found notifications
+func WithItemDetails(enabled bool) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.itemDetails = enabled
+		return nil
+	}
+}
+
+// WithStoreGrouping lists an item found at more than threshold stores once in
+// condensed notifications, with its nearest store and price, and every store
+// below it if listStores is set. A threshold of 0 disables grouping.
+func WithStoreGrouping(threshold int, listStores bool) ManagerOption {
+	return func(m *NotificationManager) error {
+		if threshold < 0 {
+			return fmt.Errorf("store grouping threshold must not be negative")
+		}
+		m.groupStores = threshold
+		m.listStores = listStores
+		return nil
+	}
+}
+
+// WithProductNameCase sets how product names are cased in notifications:
+// "title-case", "lower", or "as-is" (the default) to keep OLCC's names.
+// Items keep their raw names for matching item alerts.
+func WithProductNameCase(mode string) ManagerOption {
+	return func(m *NotificationManager) error {
+		switch mode {
+		case "", "as-is", "title-case", "lower":
+			m.productNameCase = mode
+			return nil
+		default:
+			return fmt.Errorf("invalid product name case %q, must be one of as-is, title-case, lower", mode)
+		}
+	}
+}
+
+// WithDryRun logs notifications instead of sending them
+func WithDryRun(enabled bool) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.dryRun = enabled
+		return nil
+	}
+}
+
+// WithLocation formats timestamps in notifications in loc instead of the
+// timestamps' own location
+func WithLocation(loc *time.Location) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.location = loc
+		return nil
+	}
+}
+
+// WithItemAlerts sets the priority and sound of found notifications per item,
+// keyed by the item's search term (case-insensitive)
+func WithItemAlerts(alerts map[string]config.ItemAlert) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.itemAlerts = make(map[string]config.ItemAlert, len(alerts))
+		for item, alert := range alerts {
+			m.itemAlerts[strings.ToLower(strings.TrimSpace(item))] = alert
+		}
+		return nil
+	}
+}
+
+// WithAllowedTypes restricts the notification types that may be configured.
+// An empty list allows every supported type.
+func WithAllowedTypes(types []string) ManagerOption {
+	return func(m *NotificationManager) error {
+		if len(types) == 0 {
+			return nil
+		}
+		m.allowedTypes = make(map[string]bool, len(types))
+		for _, t := range types {
+			m.allowedTypes[strings.ToLower(strings.TrimSpace(t))] = true
+		}
+		return nil
+	}
+}
+
+// notificationKey identifies the channel a notification config sends to, by
+// its type, endpoint and credentials
+func notificationKey(nc config.NotificationConfig) string {
+	keys := make([]string, 0, len(nc.Credential))
+	for k := range nc.Credential {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\x00%s", strings.ToLower(nc.Type), nc.Endpoint)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%s", k, nc.Credential[k])
+	}
+	return b.String()
 }
 
 // NewNotificationManager creates a notification manager from config
-func NewNotificationManager(notificationConfigs []config.NotificationConfig) (*NotificationManager, error) {
+func NewNotificationManager(notificationConfigs []config.NotificationConfig, opts ...ManagerOption) (*NotificationManager, error) {
 	manager := &NotificationManager{}
+	for _, opt := range opts {
+		if err := opt(manager); err != nil {
+			return nil, err
+		}
+	}
 
-	// Determine condense setting from first notification config (all should have same setting per user)
+	// Notifiers added with WithNotifiers use the condense setting of the first notification config
 	if len(notificationConfigs) > 0 {
 		manager.condense = notificationConfigs[0].Condense
 	}
 
-	// Add nicoksr notify for handling multiple services
-	nikoksrNotifier := NewNikoksrNotifier()
-	nikoksrAdded := false
+	// nikoksr/notify handles several services per notifier, so services are
+	// grouped into one notifier per condense setting and message templates
+	type nikoksrGroup struct {
+		condense      bool
+		subject, body string
+	}
+	nikoksrNotifiers := make(map[nikoksrGroup]*NikoksrNotifier)
+	nikoksrTemplates := make(map[nikoksrGroup]*itemTemplates)
+	var nikoksrOrder []nikoksrGroup
+	nikoksrFor := func(nc config.NotificationConfig, templates *itemTemplates) *NikoksrNotifier {
+		group := nikoksrGroup{condense: nc.Condense, subject: nc.SubjectTemplate, body: nc.BodyTemplate}
+		if nikoksrNotifiers[group] == nil {
+			nikoksrNotifiers[group] = NewNikoksrNotifier()
+			nikoksrTemplates[group] = templates
+			nikoksrOrder = append(nikoksrOrder, group)
+		}
+		return nikoksrNotifiers[group]
+	}
 
-	for _, nc := range notificationConfigs {
-		switch strings.ToLower(nc.Type) {
-		case "gotify":
-			token, ok := nc.Credential["token"]
-			if !ok {
-				return nil, fmt.Errorf("gotify requires token in credentials")
-			}
+	seen := make(map[string]bool)
+	for i, nc := range notificationConfigs {
+		// The same channel listed twice would send every notification twice
+		key := notificationKey(nc)
+		if seen[key] {
+			logger.Warnf("Skipping notification %d (%s): duplicates an earlier notification with the same endpoint and credentials", i+1, nc.Type)
+			continue
+		}
+		seen[key] = true
 
-			gotify := NewGotifyNotifier(nc.Endpoint, token)
-			manager.notifiers = append(manager.notifiers, gotify)
+		if manager.allowedTypes != nil && !manager.allowedTypes[strings.ToLower(nc.Type)] {
+			return nil, fmt.Errorf("notification type %q is not allowed on this server", nc.Type)
+		}
 
-		case "slack":
-			token, ok := nc.Credential["token"]
-			if !ok {
-				return nil, fmt.Errorf("slack requires token in credentials")
-			}
+		templates, err := parseItemTemplates(nc)
+		if err != nil {
+			return nil, fmt.Errorf("%s notification: %w", nc.Type, err)
+		}
+
+		if key := nc.MissingCredential(); key != "" {
+			return nil, fmt.Errorf("%s requires %s in credentials", strings.ToLower(nc.Type), key)
+		}
 
-			channelIDStr, ok := nc.Credential["channel_id"]
-			if !ok {
-				return nil, fmt.Errorf("slack requires channel_id in credentials")
+		switch strings.ToLower(nc.Type) {
+		case "gotify":
+			gotify := NewGotifyNotifier(nc.Endpoint, nc.Credential["token"])
+			switch contentType := nc.Credential["content_type"]; contentType {
+			case "":
+			case "text/plain", "text/markdown":
+				gotify.contentType = contentType
+			default:
+				return nil, fmt.Errorf("invalid gotify content_type %q, must be text/plain or text/markdown", contentType)
+			}
+			if priorityStr, ok := nc.Credential["priority"]; ok {
+				priority, err := strconv.Atoi(priorityStr)
+				if err != nil || priority < 0 || priority > 10 {
+					return nil, fmt.Errorf("invalid gotify priority %q, must be an integer from 0 to 10", priorityStr)
+				}
+				gotify.priority = priority
 			}
+			manager.addNotifier(gotify, nc.Condense, templates)
 
+		case "slack":
 			var channelID string
-			_, err := fmt.Sscanf(channelIDStr, "%s", &channelID)
+			_, err := fmt.Sscanf(nc.Credential["channel_id"], "%s", &channelID)
 			if err != nil {
 				return nil, fmt.Errorf("invalid Slack channel_id: %w", err)
 			}
 
-			nikoksrNotifier.AddSlack(token, channelID)
-			nikoksrAdded = true
+			nikoksrFor(nc, templates).AddSlack(nc.Credential["token"], channelID)
 
 		case "telegram":
-			token, ok := nc.Credential["token"]
-			if !ok {
-				return nil, fmt.Errorf("telegram requires token in credentials")
-			}
-
-			chatIDStr, ok := nc.Credential["chat_id"]
-			if !ok {
-				return nil, fmt.Errorf("telegram requires chat_id in credentials")
-			}
-
 			var chatID int64
-			_, err := fmt.Sscanf(chatIDStr, "%d", &chatID)
+			_, err := fmt.Sscanf(nc.Credential["chat_id"], "%d", &chatID)
 			if err != nil {
 				return nil, fmt.Errorf("invalid telegram chat_id: %w", err)
 			}
 
-			nikoksrNotifier.AddTelegram(token, chatID)
-			nikoksrAdded = true
+			nikoksrFor(nc, templates).AddTelegram(nc.Credential["token"], chatID)
 
 		case "discord":
-			token, ok := nc.Credential["token"]
-			if !ok {
-				return nil, fmt.Errorf("discord requires bot token in credentials")
+			var channelID string
+			_, err := fmt.Sscanf(nc.Credential["channel_id"], "%s", &channelID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Slack channel_id: %w", err)
 			}
 
-			channelIDStr, ok := nc.Credential["channel_id"]
-			if !ok {
-				return nil, fmt.Errorf("discord requires channel_id in credentials")
+			nikoksrFor(nc, templates).AddDiscord(nc.Credential["token"], channelID)
+
+		case "pushover":
+			// Sent directly rather than through nikoksr/notify to support per-item priority and sound
+			pushover := NewPushoverNotifier(nc.Endpoint, nc.Credential["token"], nc.Credential["recipient_id"])
+			manager.addNotifier(pushover, nc.Condense, templates)
+
+		case "email":
+			to := splitAddresses(nc.Credential["to"])
+			if len(to) == 0 {
+				return nil, fmt.Errorf("email requires at least one address in to")
 			}
 
-			var channelID string
-			_, err := fmt.Sscanf(channelIDStr, "%s", &channelID)
+			email, err := NewEmailNotifier(nc.Credential["smtp_host"], nc.Credential["smtp_port"],
+				nc.Credential["username"], nc.Credential["password"], nc.Credential["from"], to, nc.Credential["tls_mode"])
 			if err != nil {
-				return nil, fmt.Errorf("invalid Slack channel_id: %w", err)
+				return nil, err
 			}
+			manager.addNotifier(email, nc.Condense, templates)
 
-			nikoksrNotifier.AddDiscord(token, channelID)
-			nikoksrAdded = true
+		case "ntfy":
+			endpoint := nc.Endpoint
+			if endpoint == "" {
+				endpoint = nc.Credential["endpoint"]
+			}
 
-		case "pushover":
-			token, ok := nc.Credential["token"]
-			if !ok {
-				return nil, fmt.Errorf("pushover requires token in credentials")
+			priority := nc.Credential["priority"]
+			switch priority {
+			case "", "1", "2", "3", "4", "5", "min", "low", "default", "high", "max", "urgent":
+			default:
+				return nil, fmt.Errorf("invalid ntfy priority %q, must be 1-5 or min, low, default, high, max", priority)
 			}
 
-			recipientID, ok := nc.Credential["recipient_id"]
-			if !ok {
-				return nil, fmt.Errorf("pushover requires recipient_id in credentials")
+			ntfy := NewNtfyNotifier(endpoint, nc.Credential["topic"], nc.Credential["token"], priority, nc.Credential["tags"])
+			manager.addNotifier(ntfy, nc.Condense, templates)
+
+		case "webhook":
+			var timeout time.Duration
+			if timeoutStr, ok := nc.Credential["timeout"]; ok {
+				var err error
+				timeout, err = time.ParseDuration(timeoutStr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid webhook timeout: %w", err)
+				}
 			}
 
-			nikoksrNotifier.AddPushover(token, recipientID)
-			nikoksrAdded = true
+			webhook := NewWebhookNotifier(nc.Credential["url"], nc.Credential["method"], webhookHeaders(nc.Credential), timeout)
+			manager.addNotifier(webhook, nc.Condense, templates)
 
-		case "pushbullet":
-			token, ok := nc.Credential["token"]
-			if !ok {
-				return nil, fmt.Errorf("pushbullet requires token in credentials")
+		case "teams":
+			manager.addNotifier(NewTeamsNotifier(nc.Credential["webhook_url"]), nc.Condense, templates)
+
+		case "mattermost":
+			manager.addNotifier(NewMattermostNotifier(nc.Credential["webhook_url"]), nc.Condense, templates)
+
+		case "matrix":
+			err := nikoksrFor(nc, templates).AddMatrix(nc.Credential["user_id"], nc.Credential["room_id"],
+				nc.Credential["homeserver"], nc.Credential["access_token"])
+			if err != nil {
+				return nil, err
 			}
 
-			deviceNickname, ok := nc.Credential["device_nickname"]
-			if !ok {
-				return nil, fmt.Errorf("pushbullet requires device_nickname in credentials")
+		case "apprise":
+			apprise, err := NewAppriseNotifier(nc.Endpoint, splitAppriseURLs(nc.Credential["urls"]))
+			if err != nil {
+				return nil, err
 			}
+			manager.addNotifier(apprise, nc.Condense, templates)
 
-			nikoksrNotifier.AddPushbullet(token, deviceNickname)
-			nikoksrAdded = true
+		case "pushbullet":
+			nikoksrFor(nc, templates).AddPushbullet(nc.Credential["token"], nc.Credential["device_nickname"])
 
 		default:
 			return nil, fmt.Errorf("unsupported notification type: %s", nc.Type)
 		}
 	}
 
-	// Add nikoksr notifier if any services were added to it
-	if nikoksrAdded {
-		manager.notifiers = append(manager.notifiers, nikoksrNotifier)
+	// Add nikoksr notifiers if any services were added to them, individual ones first
+	for _, condense := range []bool{false, true} {
+		for _, group := range nikoksrOrder {
+			if group.condense == condense {
+				manager.addNotifier(nikoksrNotifiers[group], condense, nikoksrTemplates[group])
+			}
+		}
 	}
 
 	return manager, nil
 }
 
+// addNotifier adds a notifier built from a notification config with its
+// condense setting and message templates (nil for the default format)
+func (m *NotificationManager) addNotifier(notifier Notifier, condense bool, templates *itemTemplates) {
+	if m.condensed == nil {
+		m.condensed = make(map[int]bool)
+	}
+	m.condensed[len(m.notifiers)] = condense
+	if templates != nil {
+		if m.templates == nil {
+			m.templates = make(map[int]*itemTemplates)
+		}
+		m.templates[len(m.notifiers)] = templates
+	}
+	m.notifiers = append(m.notifiers, notifier)
+}
+
+// notifierGroup is a set of notifiers sharing the same message templates
+type notifierGroup struct {
+	templates *itemTemplates
+	notifiers []Notifier
+}
+
+// groupByTemplates splits the notifiers at indices into groups sharing the same
+// message templates, in order of first appearance
+func (m *NotificationManager) groupByTemplates(indices []int) []notifierGroup {
+	var groups []notifierGroup
+	for _, i := range indices {
+		templates := m.templates[i]
+		found := false
+		for g := range groups {
+			if groups[g].templates == templates {
+				groups[g].notifiers = append(groups[g].notifiers, m.notifiers[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, notifierGroup{templates: templates, notifiers: []Notifier{m.notifiers[i]}})
+		}
+	}
+	return groups
+}
+
+// condenses reports whether the notifier at index i of notifiers combines
+// found items into a single notification
+func (m *NotificationManager) condenses(i int) bool {
+	if condense, ok := m.condensed[i]; ok {
+		return condense
+	}
+	return m.condense
+}
+
 // NotifyFound sends notifications for found liquor items
 func (m *NotificationManager) NotifyFound(ctx context.Context, item search.LiquorItem) error {
-	subject := fmt.Sprintf("GFL - Found %s!", item.Name)
-	message := fmt.Sprintf("Found %s at %s on %s at %s for %s",
-		item.Name,
-		item.Store,
-		item.Date.Format("2006-01-02"),
-		item.Date.Format("15:04:05"),
-		item.Price,
-	)
+	if m.dryRun {
+		m.logDryRun(m.foundMessage(item, nil))
+		return nil
+	}
 
-	log.Info(message)
+	indices := make([]int, len(m.notifiers))
+	for i := range m.notifiers {
+		indices[i] = i
+	}
 
 	var lastErr error
-	for _, notifier := range m.notifiers {
-		if err := notifier.Notify(ctx, subject, message); err != nil {
-			log.Errorf("Failed to send notification: %v", err)
+	for _, group := range m.groupByTemplates(indices) {
+		if _, err := m.notifyFound(ctx, group.notifiers, item, group.templates); err != nil {
 			lastErr = err
 		}
 	}
-
 	return lastErr
 }
 
+// notifyFound sends a found item notification formatted with templates through
+// notifiers, returning how many delivered it
+func (m *NotificationManager) notifyFound(ctx context.Context, notifiers []Notifier, item search.LiquorItem, templates *itemTemplates) (int, error) {
+	subject, message := m.foundMessage(item, templates)
+
+	logger.Info(message)
+
+	return m.sendAlertTo(ctx, notifiers, subject, message, m.alertFor(item), item)
+}
+
+// foundMessage formats the subject and message of a single found item
+// notification, with templates if set
+func (m *NotificationManager) foundMessage(item search.LiquorItem, templates *itemTemplates) (string, string) {
+	item = m.displayItem(item)
+	subject := templates.subjectFor(item, fmt.Sprintf("GFL - Found %s!", item.Name))
+	message := templates.bodyFor(item, m.defaultFoundMessage(item))
+	return subject, message
+}
+
+// defaultFoundMessage formats the message of a single found item notification
+func (m *NotificationManager) defaultFoundMessage(item search.LiquorItem) string {
+	return fmt.Sprintf("Found %s%s at %s on %s at %s%s%s",
+		item.Name,
+		m.detailsClause(item),
+		item.Store,
+		m.localTime(item.Date).Format("2006-01-02"),
+		m.localTime(item.Date).Format("15:04:05"),
+		m.priceClause(item.Price),
+		noteClause(item.Note),
+	)
+}
+
 // NotifyFoundItems sends notifications for multiple found liquor items
-// If condense is enabled, combines all items into a single notification
-// If condense is disabled, sends individual notifications for each item
+// Notifiers with condense enabled get all items combined into a single notification,
+// the others get individual notifications for each item.
+// If no notifier delivered anything, the error wraps ErrUndelivered.
 func (m *NotificationManager) NotifyFoundItems(ctx context.Context, items []search.LiquorItem) error {
 	if len(items) == 0 {
 		return nil // No items to notify about
 	}
+	if m.dryRun {
+		for _, item := range items {
+			m.logDryRun(m.foundMessage(item, nil))
+		}
+		return nil
+	}
 
-	if m.condense {
-		return m.sendCondensedNotification(ctx, items)
+	var condensed, individual []int
+	for i := range m.notifiers {
+		if m.condenses(i) {
+			condensed = append(condensed, i)
+		} else {
+			individual = append(individual, i)
+		}
 	}
 
-	// Send individual notifications
 	var lastErr error
-	for _, item := range items {
-		if err := m.NotifyFound(ctx, item); err != nil {
+	delivered := 0
+	for _, group := range m.groupByTemplates(condensed) {
+		sent, err := m.sendCondensedNotification(ctx, group.notifiers, items, group.templates)
+		delivered += sent
+		if err != nil {
 			lastErr = err
 		}
 	}
+
+	// Send individual notifications
+	individualGroups := m.groupByTemplates(individual)
+	for _, item := range items {
+		for _, group := range individualGroups {
+			sent, err := m.notifyFound(ctx, group.notifiers, item, group.templates)
+			delivered += sent
+			if err != nil {
+				lastErr = err
+			}
+		}
+	}
+
+	if delivered == 0 && lastErr != nil {
+		return fmt.Errorf("%w: %w", ErrUndelivered, lastErr)
+	}
 	return lastErr
 }
 
-// sendCondensedNotification creates and sends a single notification for multiple items
-func (m *NotificationManager) sendCondensedNotification(ctx context.Context, items []search.LiquorItem) error {
+// sendCondensedNotification creates and sends a single notification for multiple
+// items formatted with templates through notifiers, returning how many delivered it
+func (m *NotificationManager) sendCondensedNotification(ctx context.Context, notifiers []Notifier, items []search.LiquorItem, templates *itemTemplates) (int, error) {
 	if len(items) == 0 {
-		return nil
+		return 0, nil
 	}
 
-	var subject string
-	var message strings.Builder
-
+	var subject, messageStr, markdownStr string
 	if len(items) == 1 {
 		// Single item - use same format as individual notification
-		item := items[0]
-		subject = fmt.Sprintf("GFL - Found %s!", item.Name)
-		message.WriteString(fmt.Sprintf("Found %s at %s on %s at %s for %s",
-			item.Name,
-			item.Store,
-			item.Date.Format("2006-01-02"),
-			item.Date.Format("15:04:05"),
-			item.Price,
-		))
+		subject, messageStr = m.foundMessage(items[0], templates)
+		markdownStr = messageStr
 	} else {
 		// Multiple items - create condensed format
 		subject = fmt.Sprintf("GFL - Found %d items!", len(items))
-		message.WriteString(fmt.Sprintf("Found %d liquor items:\n\n", len(items)))
+		messageStr = m.condensedMessage(items, false, templates)
+		markdownStr = m.condensedMessage(items, true, templates)
+	}
+
+	logger.Info(messageStr)
+
+	alert := m.alertFor(items...)
+	var lastErr error
+	sent := 0
+	for _, notifier := range notifiers {
+		message := messageStr
+		if md, ok := notifier.(MarkdownNotifier); ok && md.Markdown() {
+			message = markdownStr
+		}
 
-		for i, item := range items {
-			message.WriteString(fmt.Sprintf("%d. %s at %s for %s\n",
-				i+1,
-				item.Name,
+		err := m.deliver(ctx, notifier, subject, message, alert, items...)
+		if err == nil {
+			sent++
+			continue
+		}
+		logger.Errorf("Failed to send condensed notification: %v", err)
+		if m.condensedFallback && len(items) > 1 {
+			logger.Warnf("Falling back to individual notifications for %d items", len(items))
+			err = m.deliverIndividually(ctx, notifier, items, templates)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+
+	return sent, lastErr
+}
+
+// condensedMessage formats the message of a condensed notification for several
+// items, as plain text or with item names in bold for markdown notifiers.
+// With a body template, each item is listed as its rendered body.
+func (m *NotificationManager) condensedMessage(items []search.LiquorItem, markdown bool, templates *itemTemplates) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("Found %d liquor items:\n\n", len(items)))
+
+	line := 0
+	for _, group := range m.groupByItem(items, templates) {
+		line++
+		item := m.displayItem(group[0])
+		if templates != nil && templates.body != nil {
+			message.WriteString(fmt.Sprintf("%d. %s\n", line, templates.bodyFor(item, m.defaultFoundMessage(item))))
+			continue
+		}
+
+		name := item.Name
+		if markdown {
+			name = "**" + name + "**"
+		}
+		if len(group) == 1 {
+			message.WriteString(fmt.Sprintf("%d. %s%s at %s%s%s\n",
+				line,
+				name,
+				m.detailsClause(item),
 				item.Store,
-				item.Price,
+				m.priceClause(item.Price),
+				noteClause(item.Note),
 			))
+			continue
 		}
 
-		// Add timestamp for the search
-		message.WriteString(fmt.Sprintf("\nSearch completed on %s at %s",
-			items[0].Date.Format("2006-01-02"),
-			items[0].Date.Format("15:04:05"),
-		))
+		nearest := item.Store
+		if price := strings.TrimSpace(item.Price); price != "" {
+			nearest += ", " + price
+		}
+		message.WriteString(fmt.Sprintf("%d. %s%s available at %d stores (nearest: %s)%s\n",
+			line, name, m.detailsClause(item), len(group), nearest, noteClause(item.Note)))
+		if m.listStores {
+			for _, store := range group {
+				message.WriteString(fmt.Sprintf("   - %s%s\n", store.Store, m.priceClause(store.Price)))
+			}
+		}
 	}
 
-	messageStr := message.String()
-	log.Info(messageStr)
+	// Add timestamp for the search
+	message.WriteString(fmt.Sprintf("\nSearch completed on %s at %s",
+		m.localTime(items[0].Date).Format("2006-01-02"),
+		m.localTime(items[0].Date).Format("15:04:05"),
+	))
+	return message.String()
+}
 
-	var lastErr error
-	for _, notifier := range m.notifiers {
-		if err := notifier.Notify(ctx, subject, messageStr); err != nil {
-			log.Errorf("Failed to send notification: %v", err)
-			lastErr = err
+// groupByItem splits items into the entries of a condensed message: each item
+// found at more than the store grouping threshold becomes one group of all its
+// stores, in the order found, and every other result is a group of its own.
+// Items are identified by code, or by name if they have none. Nothing is grouped
+// when grouping is disabled or a body template renders each result.
+func (m *NotificationManager) groupByItem(items []search.LiquorItem, templates *itemTemplates) [][]search.LiquorItem {
+	groups := make([][]search.LiquorItem, 0, len(items))
+	if m.groupStores <= 0 || (templates != nil && templates.body != nil) {
+		for _, item := range items {
+			groups = append(groups, []search.LiquorItem{item})
 		}
+		return groups
 	}
 
-	return lastErr
+	key := func(item search.LiquorItem) string {
+		if item.Code != "" {
+			return item.Code
+		}
+		return item.Name
+	}
+	stores := make(map[string][]search.LiquorItem)
+	for _, item := range items {
+		stores[key(item)] = append(stores[key(item)], item)
+	}
+
+	listed := make(map[string]bool)
+	for _, item := range items {
+		k := key(item)
+		if len(stores[k]) <= m.groupStores {
+			groups = append(groups, []search.LiquorItem{item})
+			continue
+		}
+		if !listed[k] {
+			listed[k] = true
+			groups = append(groups, stores[k])
+		}
+	}
+	return groups
+}
+
+// NotifyDrySpell sends a status notification that item has not been found in stock
+// for the given duration. lastFound is zero if the item hasn't been found since GFL started.
+func (m *NotificationManager) NotifyDrySpell(ctx context.Context, item string, drySpell time.Duration, lastFound time.Time) error {
+	subject := fmt.Sprintf("GFL - Still searching for %s", item)
+
+	days := int(drySpell.Hours() / 24)
+	var message string
+	if lastFound.IsZero() {
+		message = fmt.Sprintf("Still searching for %s: no stock found in %d day(s) since GFL started searching", item, days)
+	} else {
+		message = fmt.Sprintf("Still searching for %s: no stock found in %d day(s), last found on %s",
+			item, days, m.localTime(lastFound).Format("2006-01-02"))
+	}
+
+	logger.Info(message)
+
+	return m.send(ctx, subject, message)
+}
+
+// NotifyZeroFinds sends an advisory that none of items were found for the given
+// number of consecutive search cycles, which may mean the watch list has a mistake
+func (m *NotificationManager) NotifyZeroFinds(ctx context.Context, cycles int, items []string) error {
+	subject := "GFL - Check your watch list"
+	message := fmt.Sprintf("None of your items have been found in %d searches in a row. "+
+		"If that's unexpected, double-check the item names or codes in your watch list: %s",
+		cycles, strings.Join(items, ", "))
+
+	logger.Info(message)
+
+	return m.send(ctx, subject, message)
+}
+
+// NotifyRecovered sends a notification that searches are working again after
+// failures consecutive failed search cycles
+func (m *NotificationManager) NotifyRecovered(ctx context.Context, failures int) error {
+	subject := "GFL - Searches recovered"
+	message := fmt.Sprintf("Searches are working again after %d failed searches in a row", failures)
+
+	logger.Info(message)
+
+	return m.send(ctx, subject, message)
+}
+
+// NotifySearchFailing sends an alert that every search for user has failed for
+// failures search cycles in a row, with cause the last search error if known
+func (m *NotificationManager) NotifySearchFailing(ctx context.Context, user string, failures int, cause error) error {
+	subject := fmt.Sprintf("GFL - Search failing for user %s", user)
+	message := fmt.Sprintf("Searches for %s have failed %d times in a row", user, failures)
+	if cause != nil {
+		message += fmt.Sprintf(", last error: %v", cause)
+	}
+	message += ". You'll be notified when searches work again."
+
+	logger.Info(message)
+
+	return m.send(ctx, subject, message)
+}
+
+// NotifyTest sends a clearly labeled test notification, to check that every
+// channel's credentials work
+func (m *NotificationManager) NotifyTest(ctx context.Context) error {
+	return m.send(ctx, "GFL - Test notification", "go-find-liquor test notification")
 }
 
 // NotifyHeartbeat sends notifications for nothing found but still trying.
-// If healthCheckItem is non-empty, it indicates a random common item was searched
-// as a health check, and healthCheckFound indicates whether it was found in stock.
-func (m *NotificationManager) NotifyHeartbeat(ctx context.Context, healthCheckItem string, healthCheckFound bool) error {
+// The message is rendered from the heartbeat template using stats. If
+// stats.HealthCheckItem is non-empty, it indicates a random common item was searched
+// as a health check, and stats.HealthCheckFound indicates whether it was found in stock.
+func (m *NotificationManager) NotifyHeartbeat(ctx context.Context, stats HeartbeatStats) error {
 	subject := "GFL - Heartbeat"
-	message := "GFL is still running and searching"
 
-	if healthCheckItem != "" {
-		if healthCheckFound {
-			message = fmt.Sprintf("%s. Health check: searched for '%s' and found it in stock", message, healthCheckItem)
-		} else {
-			message = fmt.Sprintf("%s. Health check: searched for '%s' but it was not found", message, healthCheckItem)
+	tmpl := m.heartbeatTemplate
+	if tmpl == nil {
+		tmpl = defaultHeartbeatTemplate
+	}
+
+	stats.LastFind = m.localTime(stats.LastFind)
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, stats); err != nil {
+		return fmt.Errorf("failed to render heartbeat template: %w", err)
+	}
+	message := rendered.String()
+
+	logger.Info(message)
+
+	return m.send(ctx, subject, message)
+}
+
+// localTime converts t to the configured location, if any
+func (m *NotificationManager) localTime(t time.Time) time.Time {
+	if m.location == nil || t.IsZero() {
+		return t
+	}
+	return t.In(m.location)
+}
+
+// displayItem returns item with its name cased for display in notifications
+func (m *NotificationManager) displayItem(item search.LiquorItem) search.LiquorItem {
+	switch m.productNameCase {
+	case "title-case":
+		item.Name = titleCase(item.Name)
+	case "lower":
+		item.Name = strings.ToLower(item.Name)
+	}
+	return item
+}
+
+// titleCase capitalizes the first letter of each word of name and lowercases
+// the rest, so "MICHTER'S STRAIGHT RYE" becomes "Michter's Straight Rye"
+func titleCase(name string) string {
+	var b strings.Builder
+	wordStart := true
+	for _, r := range strings.ToLower(name) {
+		if wordStart {
+			r = unicode.ToUpper(r)
 		}
+		b.WriteRune(r)
+		wordStart = unicode.IsSpace(r) || r == '-' || r == '/' || r == '('
+	}
+	return b.String()
+}
+
+// priceClause returns the " for <price>" part of a found item message, or the
+// missing price placeholder (if any) when the item has no price
+func (m *NotificationManager) priceClause(price string) string {
+	if price = strings.TrimSpace(price); price != "" {
+		return " for " + price
 	}
+	if m.missingPrice != "" {
+		return " " + m.missingPrice
+	}
+	return ""
+}
 
-	log.Info(message)
+// noteClause returns the " - <note>" part of a found item message for an item
+// with a note in the config
+func noteClause(note string) string {
+	if note = strings.TrimSpace(note); note != "" {
+		return " - " + note
+	}
+	return ""
+}
 
+// detailsClause returns the " (<size>, <proof> proof)" part of a found item
+// message when item details are enabled and known
+func (m *NotificationManager) detailsClause(item search.LiquorItem) string {
+	if !m.itemDetails {
+		return ""
+	}
+
+	var details []string
+	if size := strings.TrimSpace(item.Size); size != "" {
+		details = append(details, size)
+	}
+	if proof := strings.TrimSpace(item.Proof); proof != "" {
+		details = append(details, proof+" proof")
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
+
+// alertFor returns the configured alert for the found items, preferring the
+// highest priority when several items have one
+func (m *NotificationManager) alertFor(items ...search.LiquorItem) config.ItemAlert {
+	var alert config.ItemAlert
+	matched := false
+	for _, item := range items {
+		a, ok := m.itemAlerts[strings.ToLower(strings.TrimSpace(item.Query))]
+		if !ok {
+			a, ok = m.itemAlerts[strings.ToLower(strings.TrimSpace(item.Name))]
+		}
+		if ok && (!matched || a.Priority > alert.Priority) {
+			alert = a
+			matched = true
+		}
+	}
+	return alert
+}
+
+// logDryRun logs a notification that dry run mode keeps from being sent
+func (m *NotificationManager) logDryRun(subject, message string) {
+	logger.Infof("Dry run, not sending %q: %s", subject, message)
+}
+
+// send delivers a notification through every configured notifier, returning the last error
+func (m *NotificationManager) send(ctx context.Context, subject, message string) error {
+	return m.sendAlert(ctx, subject, message, config.ItemAlert{})
+}
+
+// sendAlert delivers a notification through every configured notifier, passing
+// alert to those that support it, and returns the last error
+func (m *NotificationManager) sendAlert(ctx context.Context, subject, message string, alert config.ItemAlert, items ...search.LiquorItem) error {
+	if m.dryRun {
+		m.logDryRun(subject, message)
+		return nil
+	}
+	_, err := m.sendAlertTo(ctx, m.notifiers, subject, message, alert, items...)
+	return err
+}
+
+// sendAlertTo delivers a notification through notifiers like sendAlert,
+// returning how many delivered it
+func (m *NotificationManager) sendAlertTo(ctx context.Context, notifiers []Notifier, subject, message string, alert config.ItemAlert, items ...search.LiquorItem) (int, error) {
 	var lastErr error
-	for _, notifier := range m.notifiers {
-		if err := notifier.Notify(ctx, subject, message); err != nil {
-			log.Errorf("Failed to send notification: %v", err)
+	sent := 0
+	for _, notifier := range notifiers {
+		if err := m.deliver(ctx, notifier, subject, message, alert, items...); err != nil {
+			logger.Errorf("Failed to send notification: %v", err)
 			lastErr = err
+			continue
 		}
+		sent++
 	}
 
-	return lastErr
+	return sent, lastErr
 }
@@ -2,7 +2,10 @@ package notification
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -236,6 +239,435 @@ func TestNotificationManager_NotifyFoundItems_MultipleItems_Condensed(t *testing
 	}
 }
 
+func TestNotificationManager_NotifyFoundItems_MixedCondense(t *testing.T) {
+	testTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "Blanton's", Store: "Store A", Date: testTime, Price: "$59.99"},
+		{Name: "Eagle Rare", Store: "Store C", Date: testTime, Price: "$39.99"},
+	}
+
+	condensed, individual := &MockNotifier{}, &MockNotifier{}
+	manager := &NotificationManager{
+		notifiers: []Notifier{condensed, individual},
+		condensed: map[int]bool{0: true, 1: false},
+	}
+
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := condensed.GetNotifications(); len(got) != 1 || got[0].Subject != "GFL - Found 2 items!" {
+		t.Errorf("Expected 1 condensed notification, got %+v", got)
+	}
+	got := individual.GetNotifications()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 individual notifications, got %d", len(got))
+	}
+	if got[0].Subject != "GFL - Found Blanton's!" || got[1].Subject != "GFL - Found Eagle Rare!" {
+		t.Errorf("Expected one notification per item, got %+v", got)
+	}
+}
+
+func TestNotificationManager_NotifyFoundItems_MissingPrice(t *testing.T) {
+	testTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "Blanton's", Store: "Store A", Date: testTime, Price: ""},
+		{Name: "Eagle Rare", Store: "Store C", Date: testTime, Price: "$39.99"},
+	}
+
+	testCases := []struct {
+		name        string
+		condense    bool
+		placeholder string
+		expected    []string
+	}{
+		{
+			name:     "individual, price omitted",
+			expected: []string{"Found Blanton's at Store A on 2024-01-15 at 14:30:00"},
+		},
+		{
+			name:        "individual, placeholder",
+			placeholder: "(price N/A)",
+			expected:    []string{"Found Blanton's at Store A on 2024-01-15 at 14:30:00 (price N/A)"},
+		},
+		{
+			name:     "condensed, price omitted",
+			condense: true,
+			expected: []string{"1. Blanton's at Store A\n", "2. Eagle Rare at Store C for $39.99\n"},
+		},
+		{
+			name:        "condensed, placeholder",
+			condense:    true,
+			placeholder: "(price N/A)",
+			expected:    []string{"1. Blanton's at Store A (price N/A)\n", "2. Eagle Rare at Store C for $39.99\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, mockNotifier := createTestNotificationManager(tc.condense)
+			if err := WithMissingPrice(tc.placeholder)(manager); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			message := mockNotifier.GetNotifications()[0].Message
+			if tc.condense {
+				for _, want := range tc.expected {
+					if !strings.Contains(message, want) {
+						t.Errorf("Expected message to contain %q, got: %s", want, message)
+					}
+				}
+			} else if message != tc.expected[0] {
+				t.Errorf("Expected message %q, got %q", tc.expected[0], message)
+			}
+			if strings.Contains(message, "for \n") || strings.HasSuffix(message, "for ") {
+				t.Errorf("Expected no dangling price clause, got: %s", message)
+			}
+		})
+	}
+}
+
+func TestNotificationManager_DryRun(t *testing.T) {
+	items := []search.LiquorItem{
+		{Name: "Blanton's", Store: "Store A", Price: "$64.95", Date: time.Now()},
+		{Name: "Eagle Rare", Store: "Store C", Price: "$39.99", Date: time.Now()},
+	}
+
+	for _, condense := range []bool{false, true} {
+		manager, mockNotifier := createTestNotificationManager(condense)
+		if err := WithDryRun(true)(manager); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx := context.Background()
+		if err := manager.NotifyFoundItems(ctx, items); err != nil {
+			t.Errorf("Expected no error from NotifyFoundItems, got: %v", err)
+		}
+		if err := manager.NotifyFound(ctx, items[0]); err != nil {
+			t.Errorf("Expected no error from NotifyFound, got: %v", err)
+		}
+		if err := manager.NotifyHeartbeat(ctx, HeartbeatStats{Users: 1, ItemsWatched: 2}); err != nil {
+			t.Errorf("Expected no error from NotifyHeartbeat, got: %v", err)
+		}
+		if err := manager.NotifyRecovered(ctx, 3); err != nil {
+			t.Errorf("Expected no error from NotifyRecovered, got: %v", err)
+		}
+
+		if got := mockNotifier.GetNotifications(); len(got) != 0 {
+			t.Errorf("Expected no notifications in dry run (condense=%v), got %+v", condense, got)
+		}
+	}
+}
+
+func TestNotificationManager_NotifyTest(t *testing.T) {
+	manager, mockNotifier := createTestNotificationManager(false)
+
+	if err := manager.NotifyTest(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	got := mockNotifier.GetNotifications()
+	if len(got) != 1 || got[0].Message != "go-find-liquor test notification" {
+		t.Errorf("Expected one test notification, got %+v", got)
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	testCases := map[string]string{
+		"MICHTER'S STRAIGHT RYE":              "Michter's Straight Rye",
+		"BLANTON'S":                           "Blanton's",
+		"EAGLE RARE 10 YR":                    "Eagle Rare 10 Yr",
+		"  E.H. TAYLOR  SMALL BATCH":          "  E.h. Taylor  Small Batch",
+		"OLD FORESTER 1920 PROHIBITION-STYLE": "Old Forester 1920 Prohibition-Style",
+		"":                                    "",
+	}
+
+	for input, want := range testCases {
+		if got := titleCase(input); got != want {
+			t.Errorf("titleCase(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNotificationManager_NotifyFoundItems_ProductNameCase(t *testing.T) {
+	testTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "MICHTER'S STRAIGHT RYE", Store: "Store A", Date: testTime, Price: "$44.95"},
+		{Name: "BLANTON'S", Store: "Store C", Date: testTime, Price: "$64.95"},
+	}
+
+	testCases := []struct {
+		name     string
+		mode     string
+		condense bool
+		expected []string
+	}{
+		{name: "as-is", mode: "as-is", expected: []string{"GFL - Found MICHTER'S STRAIGHT RYE!", "Found MICHTER'S STRAIGHT RYE at Store A"}},
+		{name: "title-case", mode: "title-case", expected: []string{"GFL - Found Michter's Straight Rye!", "Found Michter's Straight Rye at Store A"}},
+		{name: "lower", mode: "lower", expected: []string{"GFL - Found michter's straight rye!", "Found michter's straight rye at Store A"}},
+		{name: "title-case condensed", mode: "title-case", condense: true, expected: []string{"GFL - Found 2 items!", "1. Michter's Straight Rye at Store A", "2. Blanton's at Store C"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, mockNotifier := createTestNotificationManager(tc.condense)
+			if err := WithProductNameCase(tc.mode)(manager); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			notification := mockNotifier.GetNotifications()[0]
+			got := notification.Subject + "\n" + notification.Message
+			for _, want := range tc.expected {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected notification to contain %q, got: %s", want, got)
+				}
+			}
+		})
+	}
+
+	if items[0].Name != "MICHTER'S STRAIGHT RYE" {
+		t.Errorf("Expected the raw item name to be kept, got %q", items[0].Name)
+	}
+	if err := WithProductNameCase("upper")(&NotificationManager{}); err == nil {
+		t.Error("Expected an error for an unknown product name case")
+	}
+}
+
+func TestNotificationManager_NotifyFoundItems_StoreGrouping(t *testing.T) {
+	testTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "Blanton's", Code: "0171B", Store: "Store A", Date: testTime, Price: "$59.99"},
+		{Name: "Eagle Rare", Code: "0044B", Store: "Store B", Date: testTime, Price: "$39.99"},
+		{Name: "Blanton's", Code: "0171B", Store: "Store C", Date: testTime, Price: "$61.99"},
+		{Name: "Blanton's", Code: "0171B", Store: "Store D", Date: testTime},
+		{Name: "Eagle Rare", Code: "0044B", Store: "Store E", Date: testTime, Price: "$39.99"},
+	}
+
+	testCases := []struct {
+		name       string
+		threshold  int
+		listStores bool
+		expected   string
+	}{
+		{
+			name: "disabled",
+			expected: "Found 5 liquor items:\n\n" +
+				"1. Blanton's at Store A for $59.99\n" +
+				"2. Eagle Rare at Store B for $39.99\n" +
+				"3. Blanton's at Store C for $61.99\n" +
+				"4. Blanton's at Store D\n" +
+				"5. Eagle Rare at Store E for $39.99\n",
+		},
+		{
+			name:      "grouped above threshold",
+			threshold: 2,
+			expected: "Found 5 liquor items:\n\n" +
+				"1. Blanton's available at 3 stores (nearest: Store A, $59.99)\n" +
+				"2. Eagle Rare at Store B for $39.99\n" +
+				"3. Eagle Rare at Store E for $39.99\n",
+		},
+		{
+			name:      "all grouped",
+			threshold: 1,
+			expected: "Found 5 liquor items:\n\n" +
+				"1. Blanton's available at 3 stores (nearest: Store A, $59.99)\n" +
+				"2. Eagle Rare available at 2 stores (nearest: Store B, $39.99)\n",
+		},
+		{
+			name:       "store list",
+			threshold:  2,
+			listStores: true,
+			expected: "Found 5 liquor items:\n\n" +
+				"1. Blanton's available at 3 stores (nearest: Store A, $59.99)\n" +
+				"   - Store A for $59.99\n" +
+				"   - Store C for $61.99\n" +
+				"   - Store D\n" +
+				"2. Eagle Rare at Store B for $39.99\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, mockNotifier := createTestNotificationManager(true)
+			if err := WithStoreGrouping(tc.threshold, tc.listStores)(manager); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			notification := mockNotifier.GetNotifications()[0]
+			if notification.Subject != "GFL - Found 5 items!" {
+				t.Errorf("Expected subject 'GFL - Found 5 items!', got '%s'", notification.Subject)
+			}
+			if !strings.HasPrefix(notification.Message, tc.expected) {
+				t.Errorf("Expected message to start with:\n%s\ngot:\n%s", tc.expected, notification.Message)
+			}
+		})
+	}
+
+	if err := WithStoreGrouping(-1, false)(&NotificationManager{}); err == nil {
+		t.Error("Expected an error for a negative store grouping threshold")
+	}
+}
+
+func TestNotificationManager_NotifyFoundItems_ItemDetails(t *testing.T) {
+	testTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "Blanton's", Store: "Store A", Date: testTime, Price: "$64.95", Size: "750 ML", Proof: "93.0"},
+		{Name: "Eagle Rare", Store: "Store C", Date: testTime, Price: "$39.99", Size: "1.75 L"},
+	}
+
+	testCases := []struct {
+		name     string
+		condense bool
+		details  bool
+		expected []string
+	}{
+		{
+			name:     "individual, details disabled",
+			expected: []string{"Found Blanton's at Store A on 2024-01-15 at 14:30:00 for $64.95"},
+		},
+		{
+			name:     "individual, details enabled",
+			details:  true,
+			expected: []string{"Found Blanton's (750 ML, 93.0 proof) at Store A on 2024-01-15 at 14:30:00 for $64.95"},
+		},
+		{
+			name:     "condensed, details enabled",
+			condense: true,
+			details:  true,
+			expected: []string{"1. Blanton's (750 ML, 93.0 proof) at Store A for $64.95\n", "2. Eagle Rare (1.75 L) at Store C for $39.99\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, mockNotifier := createTestNotificationManager(tc.condense)
+			if err := WithItemDetails(tc.details)(manager); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			message := mockNotifier.GetNotifications()[0].Message
+			if tc.condense {
+				for _, want := range tc.expected {
+					if !strings.Contains(message, want) {
+						t.Errorf("Expected message to contain %q, got: %s", want, message)
+					}
+				}
+			} else if message != tc.expected[0] {
+				t.Errorf("Expected message %q, got %q", tc.expected[0], message)
+			}
+		})
+	}
+}
+
+func TestNotificationManager_NotifyFoundItems_Note(t *testing.T) {
+	testTime := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "Weller 12", Store: "Store A", Date: testTime, Price: "$44.99", Note: "shelf price only"},
+		{Name: "Eagle Rare", Store: "Store C", Date: testTime, Price: "$39.99"},
+	}
+
+	manager, mockNotifier := createTestNotificationManager(false)
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	notifications := mockNotifier.GetNotifications()
+	if want := "Found Weller 12 at Store A on 2024-01-15 at 14:30:00 for $44.99 - shelf price only"; notifications[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, notifications[0].Message)
+	}
+	if strings.Contains(notifications[1].Message, " - ") {
+		t.Errorf("Expected no note for an item without one, got %q", notifications[1].Message)
+	}
+
+	manager, mockNotifier = createTestNotificationManager(true)
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	message := mockNotifier.GetNotifications()[0].Message
+	if !strings.Contains(message, "1. Weller 12 at Store A for $44.99 - shelf price only\n2. Eagle Rare at Store C for $39.99\n") {
+		t.Errorf("Expected the condensed message to include the note, got: %s", message)
+	}
+}
+
+func TestNotificationManager_WithLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+	found := time.Date(2024, 1, 15, 22, 30, 0, 0, time.UTC)
+
+	manager, mockNotifier := createTestNotificationManager(false)
+	if err := WithLocation(loc)(manager); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	item := search.LiquorItem{Name: "Blanton's", Store: "Store A", Date: found, Price: "$59.99"}
+	if err := manager.NotifyFoundItems(context.Background(), []search.LiquorItem{item}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "Found Blanton's at Store A on 2024-01-15 at 17:30:00 for $59.99"
+	if got := mockNotifier.GetNotifications()[0].Message; got != expected {
+		t.Errorf("Expected message %q, got %q", expected, got)
+	}
+
+	if err := manager.NotifyHeartbeat(context.Background(), HeartbeatStats{LastFind: found}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := mockNotifier.GetNotifications()[1].Message; !strings.Contains(got, "Last find: 2024-01-15 17:30:00") {
+		t.Errorf("Expected heartbeat last find in the configured zone, got %q", got)
+	}
+}
+
+// panickingService is a nikoksr/notify service stub that panics on send
+type panickingService struct{}
+
+func (panickingService) Send(ctx context.Context, subject, message string) error {
+	var chatIDs map[int64]bool
+	chatIDs[0] = true // assignment to nil map panics
+	return nil
+}
+
+// countingService is a nikoksr/notify service stub that counts sends
+type countingService struct{ sent int32 }
+
+func (c *countingService) Send(ctx context.Context, subject, message string) error {
+	atomic.AddInt32(&c.sent, 1)
+	return nil
+}
+
+func TestNikoksrNotifier_RecoversServicePanic(t *testing.T) {
+	n := NewNikoksrNotifier()
+	healthy := &countingService{}
+	n.use("stub", panickingService{})
+	n.use("healthy", healthy)
+
+	err := n.Notify(context.Background(), "subject", "message")
+	if err == nil {
+		t.Fatal("Expected the panic to be returned as an error")
+	}
+	if !strings.Contains(err.Error(), "stub notification panicked") {
+		t.Errorf("Expected error to name the panicking service, got: %v", err)
+	}
+	if atomic.LoadInt32(&healthy.sent) != 1 {
+		t.Errorf("Expected the other service to still send, got %d sends", healthy.sent)
+	}
+}
+
 func TestNewNotificationManager_CondenseField(t *testing.T) {
 	testCases := []struct {
 		name             string
@@ -292,10 +724,137 @@ func TestNewNotificationManager_CondenseField(t *testing.T) {
 	}
 }
 
+// TestNewNotificationManager_CredentialTable tests that every notification type
+// in the config credential table is supported, and that each required
+// credential key is enforced
+func TestNewNotificationManager_CredentialTable(t *testing.T) {
+	values := map[string]string{
+		"chat_id":    "12345",
+		"smtp_port":  "587",
+		"from":       "gfl@example.com",
+		"to":         "alice@example.com",
+		"homeserver": "https://matrix.example.com",
+		"url":        "https://example.com/hook",
+	}
+
+	for _, notificationType := range config.NotificationTypes() {
+		t.Run(notificationType, func(t *testing.T) {
+			keys, _ := config.NotificationCredentials(notificationType)
+			credential := make(map[string]string)
+			for _, key := range keys.Required {
+				credential[key] = "value"
+				if v, ok := values[key]; ok {
+					credential[key] = v
+				}
+			}
+
+			nc := config.NotificationConfig{Type: notificationType, Endpoint: "https://example.com", Credential: credential}
+			// Telegram contacts the bot API when added, so it is only checked for missing keys
+			if notificationType != "telegram" {
+				if _, err := NewNotificationManager([]config.NotificationConfig{nc}); err != nil {
+					t.Fatalf("Expected %s with its required credentials to be supported, got: %v", notificationType, err)
+				}
+			}
+
+			for _, key := range keys.Required {
+				missing := make(map[string]string)
+				for k, v := range credential {
+					if k != key {
+						missing[k] = v
+					}
+				}
+				nc.Credential = missing
+				_, err := NewNotificationManager([]config.NotificationConfig{nc})
+				if err == nil || !strings.Contains(err.Error(), notificationType+" requires "+key) {
+					t.Errorf("Expected an error for missing %s, got: %v", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewNotificationManager_AllowedTypes(t *testing.T) {
+	gotify := config.NotificationConfig{
+		Type:       "gotify",
+		Endpoint:   "https://gotify.example.com",
+		Credential: map[string]string{"token": "test-token"},
+	}
+	telegram := config.NotificationConfig{
+		Type:       "telegram",
+		Credential: map[string]string{"token": "test-token", "chat_id": "12345"},
+	}
+
+	testCases := []struct {
+		name        string
+		allowed     []string
+		configs     []config.NotificationConfig
+		expectError bool
+	}{
+		{name: "empty allowlist allows all", allowed: nil, configs: []config.NotificationConfig{gotify}},
+		{name: "allowed type", allowed: []string{"Gotify", "pushover"}, configs: []config.NotificationConfig{gotify}},
+		{name: "disallowed type", allowed: []string{"gotify"}, configs: []config.NotificationConfig{gotify, telegram}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewNotificationManager(tc.configs, WithAllowedTypes(tc.allowed))
+			if tc.expectError {
+				if err == nil || !strings.Contains(err.Error(), `notification type "telegram" is not allowed`) {
+					t.Errorf("Expected disallowed type error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewNotificationManager_DuplicateBlocks(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer server.Close()
+
+	gotify := config.NotificationConfig{
+		Type:       "gotify",
+		Endpoint:   server.URL,
+		Credential: map[string]string{"token": "test-token"},
+	}
+	duplicate := gotify
+	duplicate.Type = "Gotify"
+	duplicate.Credential = map[string]string{"token": "test-token"}
+	otherToken := gotify
+	otherToken.Credential = map[string]string{"token": "other-token"}
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{gotify, duplicate, otherToken})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	if len(manager.notifiers) != 2 {
+		t.Errorf("Expected the duplicate block to be skipped leaving 2 notifiers, got %d", len(manager.notifiers))
+	}
+
+	items := []search.LiquorItem{{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()}}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected notification to succeed, got: %v", err)
+	}
+	// One notification per distinct channel: the original and the other token
+	if got := atomic.LoadInt32(&received); got != 2 {
+		t.Errorf("Expected 2 notifications, got %d", got)
+	}
+}
+
 func TestNotificationManager_NotifyHeartbeat_NoHealthCheck(t *testing.T) {
 	manager, mockNotifier := createTestNotificationManager(false)
 
-	err := manager.NotifyHeartbeat(context.Background(), "", false)
+	err := manager.NotifyHeartbeat(context.Background(), HeartbeatStats{
+		Users:        2,
+		ItemsWatched: 3,
+		Uptime:       90 * time.Minute,
+	})
 
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
@@ -311,7 +870,7 @@ func TestNotificationManager_NotifyHeartbeat_NoHealthCheck(t *testing.T) {
 		t.Errorf("Expected subject '%s', got '%s'", expectedSubject, notifications[0].Subject)
 	}
 
-	expectedMessage := "GFL is still running and searching"
+	expectedMessage := "GFL is still running and searching for 3 item(s) across 2 user(s). Last find: never. Uptime: 1h30m0s"
 	if notifications[0].Message != expectedMessage {
 		t.Errorf("Expected message '%s', got '%s'", expectedMessage, notifications[0].Message)
 	}
@@ -320,7 +879,10 @@ func TestNotificationManager_NotifyHeartbeat_NoHealthCheck(t *testing.T) {
 func TestNotificationManager_NotifyHeartbeat_HealthCheckFound(t *testing.T) {
 	manager, mockNotifier := createTestNotificationManager(false)
 
-	err := manager.NotifyHeartbeat(context.Background(), "TITO'S HANDMADE VODKA", true)
+	err := manager.NotifyHeartbeat(context.Background(), HeartbeatStats{
+		HealthCheckItem:  "TITO'S HANDMADE VODKA",
+		HealthCheckFound: true,
+	})
 
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
@@ -343,7 +905,10 @@ func TestNotificationManager_NotifyHeartbeat_HealthCheckFound(t *testing.T) {
 func TestNotificationManager_NotifyHeartbeat_HealthCheckNotFound(t *testing.T) {
 	manager, mockNotifier := createTestNotificationManager(false)
 
-	err := manager.NotifyHeartbeat(context.Background(), "JACK DANIEL'S OLD NO 7", false)
+	err := manager.NotifyHeartbeat(context.Background(), HeartbeatStats{
+		HealthCheckItem:  "JACK DANIEL'S OLD NO 7",
+		HealthCheckFound: false,
+	})
 
 	if err != nil {
 		t.Errorf("Expected no error, got: %v", err)
@@ -362,3 +927,63 @@ func TestNotificationManager_NotifyHeartbeat_HealthCheckNotFound(t *testing.T) {
 		t.Errorf("Expected message to indicate item not found, got: %s", notifications[0].Message)
 	}
 }
+
+func TestNotificationManager_NotifyHeartbeat_CustomTemplate(t *testing.T) {
+	manager, err := NewNotificationManager(nil, WithHeartbeatTemplate(
+		`{{.User}}: watching {{.ItemsWatched}} items for {{.Users}} users, last find {{.LastFind.Format "2006-01-02"}}, up {{.Uptime}}`))
+	if err != nil {
+		t.Fatalf("Expected no error creating notification manager, got: %v", err)
+	}
+	mockNotifier := &MockNotifier{}
+	manager.notifiers = []Notifier{mockNotifier}
+
+	err = manager.NotifyHeartbeat(context.Background(), HeartbeatStats{
+		User:         "alice",
+		Users:        3,
+		ItemsWatched: 5,
+		LastFind:     time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+		Uptime:       26 * time.Hour,
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	notifications := mockNotifier.GetNotifications()
+	if len(notifications) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(notifications))
+	}
+
+	expectedMessage := "alice: watching 5 items for 3 users, last find 2024-01-15, up 26h0m0s"
+	if notifications[0].Message != expectedMessage {
+		t.Errorf("Expected message '%s', got '%s'", expectedMessage, notifications[0].Message)
+	}
+}
+
+func TestNotificationManager_NotifyHeartbeat_DefaultIncludesLastFind(t *testing.T) {
+	manager, mockNotifier := createTestNotificationManager(false)
+
+	err := manager.NotifyHeartbeat(context.Background(), HeartbeatStats{
+		Users:        1,
+		ItemsWatched: 2,
+		LastFind:     time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC),
+		Uptime:       time.Hour,
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	notifications := mockNotifier.GetNotifications()
+	if len(notifications) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(notifications))
+	}
+
+	if !strings.Contains(notifications[0].Message, "Last find: 2024-01-15 14:30:00") {
+		t.Errorf("Expected message to contain last find time, got: %s", notifications[0].Message)
+	}
+}
+
+func TestWithHeartbeatTemplate_Invalid(t *testing.T) {
+	if _, err := NewNotificationManager(nil, WithHeartbeatTemplate("{{.Users")); err == nil {
+		t.Error("Expected error for invalid heartbeat template")
+	}
+}
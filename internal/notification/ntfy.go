@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultNtfyEndpoint is the public ntfy server used when no endpoint is configured
+const defaultNtfyEndpoint = "https://ntfy.sh"
+
+// NtfyNotifier implements direct ntfy API integration
+type NtfyNotifier struct {
+	endpoint string
+	topic    string
+	token    string
+	priority string
+	tags     string
+	client   *http.Client
+}
+
+// NewNtfyNotifier creates a new ntfy notifier. An empty endpoint uses the public
+// ntfy.sh server; token, priority and tags are optional.
+func NewNtfyNotifier(endpoint, topic, token, priority, tags string) *NtfyNotifier {
+	if endpoint == "" {
+		endpoint = defaultNtfyEndpoint
+	}
+	return &NtfyNotifier{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		topic:    topic,
+		token:    token,
+		priority: priority,
+		tags:     tags,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify publishes a notification to the ntfy topic
+func (n *NtfyNotifier) Notify(ctx context.Context, subject, message string) error {
+	topicURL := n.endpoint + "/" + url.PathEscape(n.topic)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", topicURL, strings.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Title", subject)
+	if n.priority != "" {
+		req.Header.Set("Priority", n.priority)
+	}
+	if n.tags != "" {
+		req.Header.Set("Tags", n.tags)
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req) // #nosec G704 -- ntfy URL is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ntfy returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+func TestNtfyNotifier_Notify(t *testing.T) {
+	var path, body string
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		header = r.Header
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{
+			Type: "ntfy",
+			Credential: map[string]string{
+				"endpoint": server.URL,
+				"topic":    "gfl-finds",
+				"token":    "tk_secret",
+				"priority": "high",
+				"tags":     "tumbler_glass,moneybag",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	if err := manager.notifiers[0].Notify(context.Background(), "GFL - Found Blanton's!", "Found Blanton's at Store A"); err != nil {
+		t.Fatalf("Expected ntfy publish to succeed, got: %v", err)
+	}
+
+	if path != "/gfl-finds" {
+		t.Errorf("Expected publish to /gfl-finds, got %s", path)
+	}
+	if body != "Found Blanton's at Store A" {
+		t.Errorf("Expected message as body, got %q", body)
+	}
+	expected := map[string]string{
+		"Title":         "GFL - Found Blanton's!",
+		"Priority":      "high",
+		"Tags":          "tumbler_glass,moneybag",
+		"Authorization": "Bearer tk_secret",
+	}
+	for name, want := range expected {
+		if got := header.Get(name); got != want {
+			t.Errorf("Expected %s header %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestNtfyNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewNtfyNotifier(server.URL, "gfl", "", "", "").Notify(context.Background(), "subject", "message")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected status code error, got: %v", err)
+	}
+}
+
+func TestNewNotificationManager_NtfyValidation(t *testing.T) {
+	manager, err := NewNotificationManager([]config.NotificationConfig{{Type: "ntfy", Credential: map[string]string{"topic": "gfl"}}})
+	if err != nil {
+		t.Fatalf("Expected topic alone to be valid, got: %v", err)
+	}
+	if ntfy := manager.notifiers[0].(*NtfyNotifier); ntfy.endpoint != defaultNtfyEndpoint {
+		t.Errorf("Expected default endpoint %s, got %s", defaultNtfyEndpoint, ntfy.endpoint)
+	}
+
+	if _, err := NewNotificationManager([]config.NotificationConfig{{Type: "ntfy", Credential: map[string]string{}}}); err == nil {
+		t.Error("Expected error for missing topic")
+	}
+	if _, err := NewNotificationManager([]config.NotificationConfig{{Type: "ntfy", Credential: map[string]string{"topic": "gfl", "priority": "9"}}}); err == nil {
+		t.Error("Expected error for invalid priority")
+	}
+}
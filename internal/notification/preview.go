@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// Message is a rendered notification
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// previewNotifier collects notifications instead of sending them
+type previewNotifier struct {
+	messages []Message
+}
+
+// Notify records the notification
+func (p *previewNotifier) Notify(ctx context.Context, subject, message string) error {
+	p.messages = append(p.messages, Message{Subject: subject, Body: message})
+	return nil
+}
+
+// PreviewFoundItems renders the notifications a channel configured by nc would
+// send for items, using the same formatting and condense logic, without sending anything
+func PreviewFoundItems(nc config.NotificationConfig, items []search.LiquorItem, opts ...ManagerOption) ([]Message, error) {
+	manager, preview, err := newPreviewManager(nc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		return nil, fmt.Errorf("failed to render found item notifications: %w", err)
+	}
+	return preview.messages, nil
+}
+
+// PreviewHeartbeat renders the heartbeat notification a channel configured by nc
+// would send for stats, without sending anything
+func PreviewHeartbeat(nc config.NotificationConfig, stats HeartbeatStats, opts ...ManagerOption) (Message, error) {
+	manager, preview, err := newPreviewManager(nc, opts...)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if err := manager.NotifyHeartbeat(context.Background(), stats); err != nil {
+		return Message{}, err
+	}
+	return preview.messages[0], nil
+}
+
+// newPreviewManager creates a manager for a single channel whose only notifier records messages
+func newPreviewManager(nc config.NotificationConfig, opts ...ManagerOption) (*NotificationManager, *previewNotifier, error) {
+	templates, err := parseItemTemplates(nc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preview := &previewNotifier{}
+	manager := &NotificationManager{condense: nc.Condense}
+	for _, opt := range opts {
+		if err := opt(manager); err != nil {
+			return nil, nil, err
+		}
+	}
+	manager.addNotifier(preview, nc.Condense, templates)
+	return manager, preview, nil
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+func previewItems() []search.LiquorItem {
+	date := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	return []search.LiquorItem{
+		{Name: "BLANTON'S", Store: "1001 - Portland", Date: date, Price: "$64.95"},
+		{Name: "WELLER SPECIAL RESERVE", Store: "1003 - Gresham", Date: date, Price: "$29.95"},
+	}
+}
+
+func TestPreviewFoundItems_Individual(t *testing.T) {
+	messages, err := PreviewFoundItems(config.NotificationConfig{Type: "gotify"}, previewItems())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Message{
+		{Subject: "GFL - Found BLANTON'S!", Body: "Found BLANTON'S at 1001 - Portland on 2026-01-02 at 15:04:05 for $64.95"},
+		{Subject: "GFL - Found WELLER SPECIAL RESERVE!", Body: "Found WELLER SPECIAL RESERVE at 1003 - Gresham on 2026-01-02 at 15:04:05 for $29.95"},
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d", len(expected), len(messages))
+	}
+	for i := range expected {
+		if messages[i] != expected[i] {
+			t.Errorf("Message %d: expected %+v, got %+v", i, expected[i], messages[i])
+		}
+	}
+}
+
+func TestPreviewFoundItems_Condensed(t *testing.T) {
+	messages, err := PreviewFoundItems(config.NotificationConfig{Type: "slack", Condense: true}, previewItems())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 condensed message, got %d", len(messages))
+	}
+	if messages[0].Subject != "GFL - Found 2 items!" {
+		t.Errorf("Expected condensed subject, got %q", messages[0].Subject)
+	}
+	if !strings.Contains(messages[0].Body, "2. WELLER SPECIAL RESERVE at 1003 - Gresham for $29.95") {
+		t.Errorf("Expected condensed body to list items, got %q", messages[0].Body)
+	}
+}
+
+func TestPreviewHeartbeat_CustomTemplate(t *testing.T) {
+	message, err := PreviewHeartbeat(config.NotificationConfig{Type: "gotify"},
+		HeartbeatStats{User: "alice", ItemsWatched: 2},
+		WithHeartbeatTemplate("{{.User}} is watching {{.ItemsWatched}} item(s)"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if message.Body != "alice is watching 2 item(s)" {
+		t.Errorf("Expected custom heartbeat body, got %q", message.Body)
+	}
+}
@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+const (
+	// defaultPushoverEndpoint is the Pushover API used when no endpoint is configured
+	defaultPushoverEndpoint = "https://api.pushover.net"
+	// Emergency priority notifications repeat every pushoverRetry until acknowledged or pushoverExpire passes
+	pushoverEmergency = 2
+	pushoverRetry     = 60 * time.Second
+	pushoverExpire    = time.Hour
+)
+
+// PushoverNotifier implements direct Pushover API integration, supporting
+// per-notification priority and sound
+type PushoverNotifier struct {
+	endpoint string
+	token    string
+	user     string
+	client   *http.Client
+}
+
+// NewPushoverNotifier creates a new Pushover notifier. An empty endpoint uses the public Pushover API.
+func NewPushoverNotifier(endpoint, token, user string) *PushoverNotifier {
+	if endpoint == "" {
+		endpoint = defaultPushoverEndpoint
+	}
+	return &PushoverNotifier{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		user:     user,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends a notification to Pushover with the default priority and sound
+func (p *PushoverNotifier) Notify(ctx context.Context, subject, message string) error {
+	return p.NotifyWithAlert(ctx, subject, message, config.ItemAlert{})
+}
+
+// NotifyWithAlert sends a notification to Pushover with the alert's priority and sound
+func (p *PushoverNotifier) NotifyWithAlert(ctx context.Context, subject, message string, alert config.ItemAlert) error {
+	form := url.Values{}
+	form.Set("token", p.token)
+	form.Set("user", p.user)
+	form.Set("title", subject)
+	form.Set("message", message)
+	if alert.Priority != 0 {
+		form.Set("priority", strconv.Itoa(alert.Priority))
+	}
+	if alert.Priority >= pushoverEmergency {
+		form.Set("retry", strconv.Itoa(int(pushoverRetry.Seconds())))
+		form.Set("expire", strconv.Itoa(int(pushoverExpire.Seconds())))
+	}
+	if alert.Sound != "" {
+		form.Set("sound", alert.Sound)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint+"/1/messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req) // #nosec G704 -- Pushover URL is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pushover returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// newPushoverServer records the form payload of every message posted to it
+func newPushoverServer(t *testing.T) (*httptest.Server, func() []url.Values) {
+	t.Helper()
+	var mu sync.Mutex
+	var payloads []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/messages.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		payloads = append(payloads, r.PostForm)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]url.Values(nil), payloads...)
+	}
+}
+
+func newPushoverManager(t *testing.T, endpoint string, condense bool) *NotificationManager {
+	t.Helper()
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{
+			Type:       "pushover",
+			Endpoint:   endpoint,
+			Condense:   condense,
+			Credential: map[string]string{"token": "app-token", "recipient_id": "user-key"},
+		},
+	}, WithItemAlerts(map[string]config.ItemAlert{
+		"Blanton's":  {Priority: 1, Sound: "cashregister"},
+		"Eagle Rare": {Priority: 2, Sound: "siren"},
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	return manager
+}
+
+func TestPushover_ItemAlertInPayload(t *testing.T) {
+	server, payloads := newPushoverServer(t)
+	manager := newPushoverManager(t, server.URL, false)
+
+	date := time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC)
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Query: "blanton's", Store: "Store A", Date: date, Price: "$59.99"},
+		{Name: "BUFFALO TRACE", Query: "Buffalo Trace", Store: "Store B", Date: date, Price: "$24.99"},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := payloads()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 Pushover messages, got %d", len(sent))
+	}
+
+	if sent[0].Get("token") != "app-token" || sent[0].Get("user") != "user-key" {
+		t.Errorf("Expected credentials in payload, got %v", sent[0])
+	}
+	if sent[0].Get("title") != "GFL - Found BLANTON'S!" {
+		t.Errorf("Expected title for the found item, got %q", sent[0].Get("title"))
+	}
+	if sent[0].Get("sound") != "cashregister" || sent[0].Get("priority") != "1" {
+		t.Errorf("Expected configured sound and priority for matching item, got sound=%q priority=%q",
+			sent[0].Get("sound"), sent[0].Get("priority"))
+	}
+
+	if sent[1].Has("sound") || sent[1].Has("priority") {
+		t.Errorf("Expected default sound and priority for item without an alert, got %v", sent[1])
+	}
+}
+
+func TestPushover_CondensedUsesHighestPriority(t *testing.T) {
+	server, payloads := newPushoverServer(t)
+	manager := newPushoverManager(t, server.URL, true)
+
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Query: "Blanton's", Store: "Store A", Price: "$59.99"},
+		{Name: "EAGLE RARE", Query: "Eagle Rare", Store: "Store B", Price: "$39.99"},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sent := payloads()
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 condensed Pushover message, got %d", len(sent))
+	}
+	if sent[0].Get("sound") != "siren" || sent[0].Get("priority") != "2" {
+		t.Errorf("Expected highest priority item's alert, got sound=%q priority=%q", sent[0].Get("sound"), sent[0].Get("priority"))
+	}
+	if sent[0].Get("retry") == "" || sent[0].Get("expire") == "" {
+		t.Errorf("Expected retry and expire for emergency priority, got %v", sent[0])
+	}
+}
@@ -0,0 +1,144 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// maxRetryDelay caps the backoff between notification attempts
+const maxRetryDelay = 5 * time.Minute
+
+// WithRetry retries a failed send to a notifier up to retries more times. The
+// wait before each retry starts at baseDelay and doubles every attempt, plus up
+// to 50% random jitter so many users don't retry in lockstep.
+func WithRetry(retries int, baseDelay time.Duration) ManagerOption {
+	return func(m *NotificationManager) error {
+		if retries < 0 {
+			return fmt.Errorf("notification retries must not be negative")
+		}
+		m.retries = retries
+		m.retryDelay = baseDelay
+		return nil
+	}
+}
+
+// WithCondensedFallback sends each item as its own notification to any notifier
+// a condensed notification could not be delivered to, so that a persistent
+// failure of the combined message doesn't lose every find in the batch
+func WithCondensedFallback(enabled bool) ManagerOption {
+	return func(m *NotificationManager) error {
+		m.condensedFallback = enabled
+		return nil
+	}
+}
+
+// deliver sends a notification through a single notifier, passing alert and the
+// found items to it if supported, and retrying failures with jittered exponential backoff
+func (m *NotificationManager) deliver(ctx context.Context, notifier Notifier, subject, message string, alert config.ItemAlert, items ...search.LiquorItem) error {
+	for attempt := 0; ; attempt++ {
+		var err error
+		if itemNotifier, ok := notifier.(ItemNotifier); ok && len(items) > 0 {
+			err = itemNotifier.NotifyWithItems(ctx, subject, message, items)
+		} else if alertNotifier, ok := notifier.(AlertNotifier); ok && alert != (config.ItemAlert{}) {
+			err = alertNotifier.NotifyWithAlert(ctx, subject, message, alert)
+		} else {
+			err = notifier.Notify(ctx, subject, message)
+		}
+		if err == nil || attempt >= m.retries {
+			return err
+		}
+
+		delay := retryBackoff(m.retryDelay, attempt)
+		var rateLimited *RateLimitError
+		if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+			delay = min(rateLimited.RetryAfter, maxRetryDelay)
+		}
+		logger.Warnf("Notification attempt %d of %d failed, retrying in %s: %v", attempt+1, m.retries+1, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// RateLimitError is returned by notifiers whose service rejected a send as
+// rate limited. RetryAfter is how long the service asked to wait (0 = unknown).
+type RateLimitError struct {
+	Service    string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s returned status code %d, retry after %s", e.Service, e.StatusCode, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s returned status code %d", e.Service, e.StatusCode)
+}
+
+// statusError returns the error for a failed response from service: a
+// RateLimitError for 429 and 503 responses, otherwise a status code error
+func statusError(service string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return &RateLimitError{Service: service, StatusCode: resp.StatusCode, RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	}
+	return fmt.Errorf("%s returned status code %d", service, resp.StatusCode)
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date,
+// into a wait from now. It returns 0 if the header is missing or invalid.
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// deliverIndividually sends each item as its own found notification formatted
+// with templates through notifier, returning the last error
+func (m *NotificationManager) deliverIndividually(ctx context.Context, notifier Notifier, items []search.LiquorItem, templates *itemTemplates) error {
+	var lastErr error
+	for _, item := range items {
+		subject, message := m.foundMessage(item, templates)
+		if err := m.deliver(ctx, notifier, subject, message, m.alertFor(item), item); err != nil {
+			logger.Errorf("Failed to send notification: %v", err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// retryBackoff returns the wait before retry number attempt+1: base doubled
+// attempt times, capped at maxRetryDelay, plus up to 50% random jitter
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	delay := min(base, maxRetryDelay)
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	jitter, err := rand.Int(rand.Reader, big.NewInt(int64(delay/2)+1))
+	if err == nil {
+		delay += time.Duration(jitter.Int64())
+	}
+	return delay
+}
@@ -0,0 +1,162 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// flakyNotifier fails its first `failures` sends, or every condensed send if
+// failCondensed is set, recording the subjects it delivered
+type flakyNotifier struct {
+	failures      int
+	failCondensed bool
+	attempts      int
+	delivered     []string
+}
+
+func (f *flakyNotifier) Notify(ctx context.Context, subject, message string) error {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return errors.New("temporary failure")
+	}
+	if f.failCondensed && strings.Contains(subject, "items!") {
+		return errors.New("message too long")
+	}
+	f.delivered = append(f.delivered, subject)
+	return nil
+}
+
+func newRetryTestManager(t *testing.T, notifier Notifier, opts ...ManagerOption) *NotificationManager {
+	t.Helper()
+	opts = append([]ManagerOption{WithNotifiers(notifier)}, opts...)
+	manager, err := NewNotificationManager(nil, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	manager.condense = true
+	return manager
+}
+
+var retryTestItems = []search.LiquorItem{
+	{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()},
+	{Name: "WELLER SPECIAL RESERVE", Store: "Store B", Price: "$29.95", Date: time.Now()},
+}
+
+func TestNotifyFoundItems_CondensedRetry(t *testing.T) {
+	notifier := &flakyNotifier{failures: 2}
+	manager := newRetryTestManager(t, notifier, WithRetry(2, time.Millisecond))
+
+	if err := manager.NotifyFoundItems(context.Background(), retryTestItems); err != nil {
+		t.Fatalf("Expected condensed send to succeed after retries, got: %v", err)
+	}
+	if notifier.attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", notifier.attempts)
+	}
+	if len(notifier.delivered) != 1 || notifier.delivered[0] != "GFL - Found 2 items!" {
+		t.Errorf("Expected a single condensed notification, got %v", notifier.delivered)
+	}
+}
+
+func TestNotifyFound_Retry(t *testing.T) {
+	notifier := &flakyNotifier{failures: 1}
+	manager := newRetryTestManager(t, notifier, WithRetry(1, time.Millisecond))
+
+	if err := manager.NotifyFound(context.Background(), retryTestItems[0]); err != nil {
+		t.Fatalf("Expected send to succeed after a retry, got: %v", err)
+	}
+	if notifier.attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", notifier.attempts)
+	}
+}
+
+func TestNotifyFoundItems_CondensedFallback(t *testing.T) {
+	notifier := &flakyNotifier{failCondensed: true}
+	manager := newRetryTestManager(t, notifier, WithRetry(1, time.Millisecond), WithCondensedFallback(true))
+
+	if err := manager.NotifyFoundItems(context.Background(), retryTestItems); err != nil {
+		t.Fatalf("Expected individual fallback to succeed, got: %v", err)
+	}
+	// Two condensed attempts, then one per item
+	if notifier.attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", notifier.attempts)
+	}
+	want := []string{"GFL - Found BLANTON'S!", "GFL - Found WELLER SPECIAL RESERVE!"}
+	if strings.Join(notifier.delivered, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected individual notifications %v, got %v", want, notifier.delivered)
+	}
+}
+
+func TestNotifyFoundItems_CondensedFailureWithoutFallback(t *testing.T) {
+	notifier := &flakyNotifier{failCondensed: true}
+	manager := newRetryTestManager(t, notifier, WithRetry(1, time.Millisecond))
+
+	if err := manager.NotifyFoundItems(context.Background(), retryTestItems); err == nil {
+		t.Error("Expected an error when the condensed send keeps failing")
+	}
+	if len(notifier.delivered) != 0 {
+		t.Errorf("Expected no individual notifications without fallback, got %v", notifier.delivered)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt, want := range []time.Duration{base, 2 * base, 4 * base} {
+		got := retryBackoff(base, attempt)
+		if got < want || got > want+want/2 {
+			t.Errorf("retryBackoff(%s, %d) = %s, want between %s and %s", base, attempt, got, want, want+want/2)
+		}
+	}
+
+	if got := retryBackoff(time.Minute, 100); got > maxRetryDelay+maxRetryDelay/2 {
+		t.Errorf("Expected backoff to be capped, got %s", got)
+	}
+	if got := retryBackoff(0, 3); got != 0 {
+		t.Errorf("Expected no wait for a zero base delay, got %s", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNotifyFoundItems_Undelivered(t *testing.T) {
+	failing := &flakyNotifier{failures: 100}
+	manager := newRetryTestManager(t, failing)
+	err := manager.NotifyFoundItems(context.Background(), retryTestItems)
+	if !errors.Is(err, ErrUndelivered) {
+		t.Errorf("Expected ErrUndelivered when every notifier fails, got: %v", err)
+	}
+
+	// One notifier delivering is enough for the find not to be lost
+	manager, err = NewNotificationManager(nil, WithNotifiers(&flakyNotifier{failures: 100}, &flakyNotifier{}))
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	err = manager.NotifyFoundItems(context.Background(), retryTestItems)
+	if err == nil || errors.Is(err, ErrUndelivered) {
+		t.Errorf("Expected a plain error when some notifier delivered, got: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// teamsThemeColor is the accent color of GFL's Teams cards
+const teamsThemeColor = "0076D7"
+
+// TeamsNotifier posts notifications to a Microsoft Teams incoming webhook as
+// MessageCards, with a section per found item
+type TeamsNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// teamsCard is a Teams MessageCard
+type teamsCard struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	Summary    string         `json:"summary"`
+	ThemeColor string         `json:"themeColor"`
+	Title      string         `json:"title"`
+	Text       string         `json:"text,omitempty"`
+	Sections   []teamsSection `json:"sections,omitempty"`
+}
+
+// teamsSection describes one found item of a MessageCard
+type teamsSection struct {
+	ActivityTitle string      `json:"activityTitle"`
+	Facts         []teamsFact `json:"facts"`
+}
+
+// teamsFact is a name/value pair shown in a MessageCard section
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewTeamsNotifier creates a new Teams notifier posting to webhookURL
+func NewTeamsNotifier(webhookURL string) *TeamsNotifier {
+	return &TeamsNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends a card with the message as its text
+func (t *TeamsNotifier) Notify(ctx context.Context, subject, message string) error {
+	return t.post(ctx, teamsMessageCard(subject, message, nil))
+}
+
+// NotifyWithItems sends a card listing each found item with its store and price
+func (t *TeamsNotifier) NotifyWithItems(ctx context.Context, subject, message string, items []search.LiquorItem) error {
+	return t.post(ctx, teamsMessageCard(subject, message, items))
+}
+
+// teamsMessageCard builds the card for a notification. Found items are listed
+// as sections in place of the message text.
+func teamsMessageCard(subject, message string, items []search.LiquorItem) teamsCard {
+	card := teamsCard{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		Summary:    subject,
+		ThemeColor: teamsThemeColor,
+		Title:      subject,
+	}
+	if len(items) == 0 {
+		card.Text = message
+		return card
+	}
+
+	for _, item := range items {
+		facts := []teamsFact{{Name: "Store", Value: item.Store}}
+		if price := strings.TrimSpace(item.Price); price != "" {
+			facts = append(facts, teamsFact{Name: "Price", Value: price})
+		}
+		card.Sections = append(card.Sections, teamsSection{ActivityTitle: item.Name, Facts: facts})
+	}
+	return card
+}
+
+// post sends card to the webhook
+func (t *TeamsNotifier) post(ctx context.Context, card teamsCard) error {
+	jsonData, err := json.Marshal(card)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.webhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req) // #nosec G704 -- webhook URL is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("teams returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
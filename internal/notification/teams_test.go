@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// newTeamsServer returns a server recording the cards posted to it
+func newTeamsServer(t *testing.T) (*httptest.Server, *[]teamsCard) {
+	t.Helper()
+	var cards []teamsCard
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "expected a JSON POST", http.StatusBadRequest)
+			return
+		}
+		var card teamsCard
+		if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cards = append(cards, card)
+	}))
+	t.Cleanup(server.Close)
+	return server, &cards
+}
+
+func TestTeamsNotifier_NotifyFoundItems(t *testing.T) {
+	server, cards := newTeamsServer(t)
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "teams", Condense: true, Credential: map[string]string{"webhook_url": server.URL}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Date: time.Now()},
+		{Name: "EAGLE RARE", Store: "Store B", Date: time.Now()},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected Teams notification to succeed, got: %v", err)
+	}
+
+	if len(*cards) != 1 {
+		t.Fatalf("Expected 1 card, got %d", len(*cards))
+	}
+	card := (*cards)[0]
+	if card.Type != "MessageCard" || card.Context != "http://schema.org/extensions" {
+		t.Errorf("Expected a MessageCard, got @type %q @context %q", card.Type, card.Context)
+	}
+	if card.Title != "GFL - Found 2 items!" || card.Summary != card.Title {
+		t.Errorf("Expected subject as title and summary, got %q / %q", card.Title, card.Summary)
+	}
+	if len(card.Sections) != 2 {
+		t.Fatalf("Expected a section per item, got %+v", card.Sections)
+	}
+
+	first := card.Sections[0]
+	if first.ActivityTitle != "BLANTON'S" {
+		t.Errorf("Expected item name as section title, got %q", first.ActivityTitle)
+	}
+	wantFacts := []teamsFact{{Name: "Store", Value: "Store A"}, {Name: "Price", Value: "$64.95"}}
+	if len(first.Facts) != len(wantFacts) || first.Facts[0] != wantFacts[0] || first.Facts[1] != wantFacts[1] {
+		t.Errorf("Expected facts %+v, got %+v", wantFacts, first.Facts)
+	}
+	if second := card.Sections[1]; len(second.Facts) != 1 {
+		t.Errorf("Expected no price fact for an item without a price, got %+v", second.Facts)
+	}
+}
+
+func TestTeamsNotifier_Notify(t *testing.T) {
+	server, cards := newTeamsServer(t)
+
+	if err := NewTeamsNotifier(server.URL).Notify(context.Background(), "GFL - Heartbeat", "GFL is still running"); err != nil {
+		t.Fatalf("Expected Teams notification to succeed, got: %v", err)
+	}
+
+	if len(*cards) != 1 {
+		t.Fatalf("Expected 1 card, got %d", len(*cards))
+	}
+	if card := (*cards)[0]; card.Text != "GFL is still running" || len(card.Sections) != 0 {
+		t.Errorf("Expected message as card text without sections, got %+v", card)
+	}
+}
+
+func TestTeamsNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewTeamsNotifier(server.URL).Notify(context.Background(), "subject", "message")
+	if err == nil || !strings.Contains(err.Error(), "status code 400") {
+		t.Errorf("Expected status code error, got: %v", err)
+	}
+}
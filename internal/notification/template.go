@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// sampleItem is rendered when parsing message templates, so templates that
+// refer to unknown fields fail when the manager is created rather than when notifying
+var sampleItem = search.LiquorItem{
+	Name:     "BLANTON'S",
+	Code:     "0171B",
+	Store:    "1001 - Portland",
+	Date:     time.Now(),
+	Price:    "$64.95",
+	Quantity: 1,
+	Size:     "750 ML",
+	Proof:    "93.0",
+	Note:     "for the holidays",
+}
+
+// itemTemplates render the subject and body of a channel's found item
+// notifications from a search.LiquorItem. A nil template uses the default format.
+type itemTemplates struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// parseItemTemplates parses the message templates of a notification config,
+// returning nil if it sets none
+func parseItemTemplates(nc config.NotificationConfig) (*itemTemplates, error) {
+	if nc.SubjectTemplate == "" && nc.BodyTemplate == "" {
+		return nil, nil
+	}
+
+	var t itemTemplates
+	var err error
+	if t.subject, err = parseItemTemplate("subject_template", nc.SubjectTemplate); err != nil {
+		return nil, err
+	}
+	if t.body, err = parseItemTemplate("body_template", nc.BodyTemplate); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseItemTemplate parses text and checks it renders for a sample item,
+// returning nil if text is empty
+func parseItemTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, sampleItem); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// render executes tmpl for item, returning fallback if tmpl is nil or fails
+func render(tmpl *template.Template, item search.LiquorItem, fallback string) string {
+	if tmpl == nil {
+		return fallback
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, item); err != nil {
+		logger.Errorf("Failed to render %s, using the default format: %v", tmpl.Name(), err)
+		return fallback
+	}
+	return b.String()
+}
+
+// subjectFor renders the subject for item, or returns fallback without a subject template
+func (t *itemTemplates) subjectFor(item search.LiquorItem, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	return render(t.subject, item, fallback)
+}
+
+// bodyFor renders the body for item, or returns fallback without a body template
+func (t *itemTemplates) bodyFor(item search.LiquorItem, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	return render(t.body, item, fallback)
+}
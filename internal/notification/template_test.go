@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+var templateTestItems = []search.LiquorItem{
+	{Name: "BLANTON'S", Store: "Store A", Price: "$64.95", Size: "750 ML", Proof: "93.0", Date: time.Now()},
+	{Name: "EAGLE RARE", Store: "Store B", Price: "$39.99", Size: "1.75 L", Proof: "90.0", Date: time.Now()},
+}
+
+func TestNewNotificationManager_MessageTemplates(t *testing.T) {
+	templatedServer, templated := newGotifyServer(t)
+	defaultServer, defaults := newGotifyServer(t)
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{
+			Type:            "gotify",
+			Endpoint:        templatedServer.URL,
+			Credential:      map[string]string{"token": "templated"},
+			SubjectTemplate: "{{.Name}} is in!",
+			BodyTemplate:    "{{.Name}} ({{.Size}}) for {{.Price}} at {{.Store}}",
+		},
+		{Type: "gotify", Endpoint: defaultServer.URL, Credential: map[string]string{"token": "default"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	if err := manager.NotifyFoundItems(context.Background(), templateTestItems); err != nil {
+		t.Fatalf("Expected notification to succeed, got: %v", err)
+	}
+
+	if len(*templated) != 2 {
+		t.Fatalf("Expected 2 templated messages, got %d", len(*templated))
+	}
+	first := (*templated)[0]
+	if first.Title != "BLANTON'S is in!" {
+		t.Errorf("Expected templated subject, got %q", first.Title)
+	}
+	if first.Message != "BLANTON'S (750 ML) for $64.95 at Store A" {
+		t.Errorf("Expected templated body with size and price, got %q", first.Message)
+	}
+
+	// Channels without templates keep the default format
+	if len(*defaults) != 2 || (*defaults)[0].Title != "GFL - Found BLANTON'S!" ||
+		!strings.HasPrefix((*defaults)[0].Message, "Found BLANTON'S at Store A on ") {
+		t.Errorf("Expected the default format without templates, got %+v", *defaults)
+	}
+}
+
+func TestNewNotificationManager_CondensedBodyTemplate(t *testing.T) {
+	server, messages := newGotifyServer(t)
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{
+			Type:         "gotify",
+			Endpoint:     server.URL,
+			Condense:     true,
+			Credential:   map[string]string{"token": "test-token"},
+			BodyTemplate: "{{.Name}} {{.Proof}} proof, {{.Price}}",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	if err := manager.NotifyFoundItems(context.Background(), templateTestItems); err != nil {
+		t.Fatalf("Expected notification to succeed, got: %v", err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("Expected 1 condensed message, got %d", len(*messages))
+	}
+	msg := (*messages)[0]
+	if msg.Title != "GFL - Found 2 items!" {
+		t.Errorf("Expected the default condensed subject, got %q", msg.Title)
+	}
+	for _, want := range []string{"1. BLANTON'S 93.0 proof, $64.95\n", "2. EAGLE RARE 90.0 proof, $39.99\n"} {
+		if !strings.Contains(msg.Message, want) {
+			t.Errorf("Expected condensed message to list %q, got: %s", want, msg.Message)
+		}
+	}
+}
+
+func TestNewNotificationManager_InvalidTemplates(t *testing.T) {
+	testCases := []struct {
+		name    string
+		subject string
+		body    string
+	}{
+		{name: "syntax error", body: "{{.Name"},
+		{name: "unknown field", subject: "{{.Bottle}}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewNotificationManager([]config.NotificationConfig{
+				{
+					Type:            "gotify",
+					Endpoint:        "https://gotify.example.com",
+					Credential:      map[string]string{"token": "test-token"},
+					SubjectTemplate: tc.subject,
+					BodyTemplate:    tc.body,
+				},
+			})
+			if err == nil || !strings.Contains(err.Error(), "_template") {
+				t.Errorf("Expected an invalid template error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestPreviewFoundItems_Templates(t *testing.T) {
+	nc := config.NotificationConfig{
+		Type:            "gotify",
+		SubjectTemplate: "{{.Name}}",
+		BodyTemplate:    "{{.Name}} at {{.Store}}",
+	}
+
+	messages, err := PreviewFoundItems(nc, templateTestItems[:1])
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Subject != "BLANTON'S" || messages[0].Body != "BLANTON'S at Store A" {
+		t.Errorf("Expected templated preview, got %+v", messages)
+	}
+}
@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+const (
+	// defaultWebhookTimeout is used when no timeout credential is configured
+	defaultWebhookTimeout = 10 * time.Second
+	// webhookHeaderPrefix marks credential keys that are sent as request headers,
+	// e.g. "header_Authorization"
+	webhookHeaderPrefix = "header_"
+)
+
+// WebhookNotifier sends notifications as JSON to an arbitrary HTTP endpoint
+type WebhookNotifier struct {
+	url     string
+	method  string
+	headers map[string]string
+	client  *http.Client
+}
+
+// webhookPayload is the JSON body sent by WebhookNotifier
+type webhookPayload struct {
+	Subject string              `json:"subject"`
+	Message string              `json:"message"`
+	Items   []search.LiquorItem `json:"items,omitempty"`
+}
+
+// NewWebhookNotifier creates a new webhook notifier. An empty method uses POST
+// and a zero timeout uses defaultWebhookTimeout.
+func NewWebhookNotifier(url, method string, headers map[string]string, timeout time.Duration) *WebhookNotifier {
+	if method == "" {
+		method = http.MethodPost
+	}
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookNotifier{
+		url:     url,
+		method:  strings.ToUpper(method),
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify sends a notification without structured items
+func (w *WebhookNotifier) Notify(ctx context.Context, subject, message string) error {
+	return w.NotifyWithItems(ctx, subject, message, nil)
+}
+
+// NotifyWithItems sends a notification including the found items
+func (w *WebhookNotifier) NotifyWithItems(ctx context.Context, subject, message string, items []search.LiquorItem) error {
+	jsonData, err := json.Marshal(webhookPayload{Subject: subject, Message: message, Items: items})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.method, w.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req) // #nosec G704 -- webhook URL is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return statusError("webhook", resp)
+	}
+
+	return nil
+}
+
+// webhookHeaders extracts request headers from "header_<Name>" credential keys
+func webhookHeaders(credential map[string]string) map[string]string {
+	headers := make(map[string]string)
+	for key, value := range credential {
+		if name, ok := strings.CutPrefix(key, webhookHeaderPrefix); ok && name != "" {
+			headers[name] = value
+		}
+	}
+	return headers
+}
@@ -0,0 +1,185 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+func TestWebhookNotifier_NotifyFoundItems(t *testing.T) {
+	var method, auth string
+	var payloads []webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		auth = r.Header.Get("Authorization")
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{
+			Type:     "webhook",
+			Condense: true,
+			Credential: map[string]string{
+				"url":                  server.URL,
+				"method":               "put",
+				"header_Authorization": "Bearer secret",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Code: "0171B", Store: "Store A", Price: "$64.95", Date: time.Now()},
+		{Name: "EAGLE RARE", Code: "0223B", Store: "Store B", Price: "$39.99", Date: time.Now()},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected webhook to succeed, got: %v", err)
+	}
+
+	if method != http.MethodPut || auth != "Bearer secret" {
+		t.Errorf("Expected PUT with Authorization header, got method=%s auth=%q", method, auth)
+	}
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 condensed webhook call, got %d", len(payloads))
+	}
+	payload := payloads[0]
+	if payload.Subject != "GFL - Found 2 items!" || !strings.Contains(payload.Message, "BLANTON'S") {
+		t.Errorf("Expected subject and message in payload, got %+v", payload)
+	}
+	if len(payload.Items) != 2 || payload.Items[1].Code != "0223B" || payload.Items[1].Store != "Store B" {
+		t.Errorf("Expected structured items in payload, got %+v", payload.Items)
+	}
+
+	// Messages without items, such as heartbeats, omit the items field
+	if err := manager.NotifyHeartbeat(context.Background(), HeartbeatStats{Users: 1}); err != nil {
+		t.Fatalf("Expected heartbeat to succeed, got: %v", err)
+	}
+	if len(payloads) != 2 || payloads[1].Items != nil {
+		t.Errorf("Expected heartbeat without items, got %+v", payloads)
+	}
+}
+
+func TestNotifyFoundItems_CondensedAndIndividualBlocks(t *testing.T) {
+	newServer := func(payloads *[]webhookPayload) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload webhookPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*payloads = append(*payloads, payload)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	var summaries, archive []webhookPayload
+	summaryServer := newServer(&summaries)
+	archiveServer := newServer(&archive)
+
+	manager, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "webhook", Condense: true, Credential: map[string]string{"url": summaryServer.URL}},
+		{Type: "webhook", Condense: false, Credential: map[string]string{"url": archiveServer.URL}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Code: "0171B", Store: "Store A", Price: "$64.95", Date: time.Now()},
+		{Name: "EAGLE RARE", Code: "0223B", Store: "Store B", Price: "$39.99", Date: time.Now()},
+	}
+	if err := manager.NotifyFoundItems(context.Background(), items); err != nil {
+		t.Fatalf("Expected notifications to succeed, got: %v", err)
+	}
+
+	if len(summaries) != 1 || len(summaries[0].Items) != 2 {
+		t.Errorf("Expected 1 condensed summary with both items, got %+v", summaries)
+	}
+	if len(archive) != 2 {
+		t.Fatalf("Expected 2 individual archive calls, got %d", len(archive))
+	}
+	for i, payload := range archive {
+		if len(payload.Items) != 1 || payload.Items[0].Code != items[i].Code {
+			t.Errorf("Expected archive call %d to carry only %s, got %+v", i, items[i].Code, payload.Items)
+		}
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(server.URL, "", nil, 0)
+	err := webhook.Notify(context.Background(), "subject", "message")
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected status code error, got: %v", err)
+	}
+}
+
+// TestWebhookNotifier_RateLimited tests that a rate limited webhook send and
+// its Retry-After are retried after the wait asked for
+func TestWebhookNotifier_RateLimited(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookNotifier(server.URL, "", nil, 0)
+	err := webhook.Notify(context.Background(), "subject", "message")
+	var rateLimited *RateLimitError
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != time.Second {
+		t.Fatalf("Expected a rate limit error, got: %v", err)
+	}
+
+	// The retry waits for Retry-After rather than the hour-long backoff
+	manager := newRetryTestManager(t, webhook, WithRetry(1, time.Hour))
+	done := make(chan error, 1)
+	go func() { done <- manager.NotifyTest(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the retried send to succeed, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the retry to wait for Retry-After rather than the backoff")
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestNewNotificationManager_WebhookValidation(t *testing.T) {
+	if _, err := NewNotificationManager([]config.NotificationConfig{{Type: "webhook", Credential: map[string]string{}}}); err == nil {
+		t.Error("Expected error for missing url")
+	}
+
+	_, err := NewNotificationManager([]config.NotificationConfig{
+		{Type: "webhook", Credential: map[string]string{"url": "https://example.com", "timeout": "soon"}},
+	})
+	if err == nil {
+		t.Error("Expected error for invalid timeout")
+	}
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"context"
+	"time"
+)
+
+// dryStreak tracks how long an item has gone without being found in stock
+type dryStreak struct {
+	// since is when the item was last found, or when searching for it began
+	since time.Time
+	// found reports whether the item has been found at all
+	found bool
+	// alerted is the number of dry spell thresholds already notified for this streak
+	alerted int
+}
+
+// recordSearch updates an item's dry streak after it was searched at now
+func (ur *userRunner) recordSearch(item string, found bool, now time.Time) {
+	streak, ok := ur.dryStreaks[item]
+	if !ok {
+		streak = &dryStreak{since: now}
+		ur.dryStreaks[item] = streak
+	}
+
+	if found {
+		*streak = dryStreak{since: now, found: true}
+	}
+}
+
+// notifyDrySpells sends a status notification for each item whose time without
+// stock has crossed another multiple of the dry spell threshold since it was last notified
+func (ur *userRunner) notifyDrySpells(ctx context.Context, now time.Time) {
+	if ur.drySpell <= 0 {
+		return
+	}
+
+	for _, item := range ur.userConfig.ItemNames() {
+		streak, ok := ur.dryStreaks[item]
+		if !ok {
+			continue
+		}
+
+		dry := now.Sub(streak.since)
+		crossed := int(dry / ur.drySpell)
+		if crossed <= streak.alerted {
+			continue
+		}
+
+		notifyCtx, cancel, ok := ur.notifyContext(ctx)
+		if !ok {
+			cancel()
+			return
+		}
+
+		var lastFound time.Time
+		if streak.found {
+			lastFound = streak.since
+		}
+		if err := ur.notifier.NotifyDrySpell(notifyCtx, item, dry, lastFound); err != nil {
+			ur.log.Warnf("Failed to send dry spell notification: %v", err)
+		}
+		cancel()
+		streak.alerted = crossed
+	}
+}
@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// newDrySpellRunner creates a user runner recording its notifications, with the given dry spell threshold
+func newDrySpellRunner(t *testing.T, threshold time.Duration) (*userRunner, *recordingNotifier) {
+	t.Helper()
+	recorder := &recordingNotifier{}
+	userConfig := config.UserConfig{Name: "user1", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10}
+
+	ur, err := newUserRunner(userConfig, nil, time.Hour, "test-agent", nil, nil,
+		[]notification.ManagerOption{notification.WithNotifiers(recorder)})
+	if err != nil {
+		t.Fatalf("Failed to create user runner: %v", err)
+	}
+	ur.drySpell = threshold
+	return ur, recorder
+}
+
+// TestRunner_DrySpellFiresOncePerThreshold tests that a long gap notifies once per threshold crossed
+func TestRunner_DrySpellFiresOncePerThreshold(t *testing.T) {
+	threshold := 30 * 24 * time.Hour
+	ur, recorder := newDrySpellRunner(t, threshold)
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ur.recordSearch("Blanton's", true, start)
+
+	ur.recordSearch("Blanton's", false, start.Add(29*24*time.Hour))
+	ur.notifyDrySpells(ctx, start.Add(29*24*time.Hour))
+	if len(recorder.sent) != 0 {
+		t.Fatalf("Expected no notification before the threshold, got %d", len(recorder.sent))
+	}
+
+	ur.recordSearch("Blanton's", false, start.Add(31*24*time.Hour))
+	ur.notifyDrySpells(ctx, start.Add(31*24*time.Hour))
+	ur.notifyDrySpells(ctx, start.Add(32*24*time.Hour))
+	if len(recorder.sent) != 1 {
+		t.Fatalf("Expected 1 notification after crossing the threshold, got %d", len(recorder.sent))
+	}
+	if !strings.Contains(recorder.sent[0], "no stock found in 31 day(s), last found on 2026-01-01") {
+		t.Errorf("Expected dry spell message with days and last found date, got: %s", recorder.sent[0])
+	}
+
+	ur.notifyDrySpells(ctx, start.Add(61*24*time.Hour))
+	if len(recorder.sent) != 2 {
+		t.Errorf("Expected a second notification after crossing the threshold again, got %d", len(recorder.sent))
+	}
+}
+
+// TestRunner_DrySpellResetOnFind tests that finding an item starts a new dry streak
+func TestRunner_DrySpellResetOnFind(t *testing.T) {
+	threshold := 24 * time.Hour
+	ur, recorder := newDrySpellRunner(t, threshold)
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ur.recordSearch("Blanton's", false, start)
+	ur.notifyDrySpells(ctx, start.Add(25*time.Hour))
+	if len(recorder.sent) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(recorder.sent))
+	}
+	if !strings.Contains(recorder.sent[0], "since GFL started searching") {
+		t.Errorf("Expected never-found message, got: %s", recorder.sent[0])
+	}
+
+	ur.recordSearch("Blanton's", true, start.Add(26*time.Hour))
+	ur.notifyDrySpells(ctx, start.Add(49*time.Hour))
+	if len(recorder.sent) != 1 {
+		t.Errorf("Expected no notification within a threshold of the last find, got %d", len(recorder.sent))
+	}
+
+	ur.notifyDrySpells(ctx, start.Add(51*time.Hour))
+	if len(recorder.sent) != 2 {
+		t.Errorf("Expected a notification once the new streak crosses the threshold, got %d", len(recorder.sent))
+	}
+}
+
+// TestRunner_DrySpellSurvivesRestart tests that dry streaks are restored from
+// saved state, so a restart neither restarts the count nor repeats an alert
+func TestRunner_DrySpellSurvivesRestart(t *testing.T) {
+	threshold := 24 * time.Hour
+	path := filepath.Join(t.TempDir(), "runner_state.json")
+	ur, recorder := newDrySpellRunner(t, threshold)
+	ur.state = NewFileStateStore(path)
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ur.recordSearch("Blanton's", true, start)
+	ur.notifyDrySpells(ctx, start.Add(25*time.Hour))
+	if len(recorder.sent) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(recorder.sent))
+	}
+	ur.saveState()
+
+	// Simulate a restart: a new runner with state loaded from the file
+	restarted, recorder := newDrySpellRunner(t, threshold)
+	restarted.state = NewFileStateStore(path)
+	restarted.restoreState()
+
+	restarted.recordSearch("Blanton's", false, start.Add(26*time.Hour))
+	restarted.notifyDrySpells(ctx, start.Add(26*time.Hour))
+	if len(recorder.sent) != 0 {
+		t.Fatalf("Expected the alert sent before the restart not to repeat, got %d", len(recorder.sent))
+	}
+	restarted.notifyDrySpells(ctx, start.Add(49*time.Hour))
+	if len(recorder.sent) != 1 {
+		t.Fatalf("Expected a notification once the restored streak crosses the threshold again, got %d", len(recorder.sent))
+	}
+	if !strings.Contains(recorder.sent[0], "last found on 2026-01-01") {
+		t.Errorf("Expected the last find from before the restart, got: %s", recorder.sent[0])
+	}
+}
+
+// TestRunner_DrySpellDisabled tests that no dry spell notifications are sent without a threshold
+func TestRunner_DrySpellDisabled(t *testing.T) {
+	ur, recorder := newDrySpellRunner(t, 0)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ur.recordSearch("Blanton's", false, start)
+	ur.notifyDrySpells(context.Background(), start.Add(365*24*time.Hour))
+	if len(recorder.sent) != 0 {
+		t.Errorf("Expected no notifications when disabled, got %d", len(recorder.sent))
+	}
+}
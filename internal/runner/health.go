@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// userHealth is a user runner's entry in the health report
+type userHealth struct {
+	Name           string     `json:"name"`
+	Alive          bool       `json:"alive"`
+	LastSearchTime *time.Time `json:"last_search_time,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// healthReport is the body served at /healthz
+type healthReport struct {
+	Status string       `json:"status"`
+	Users  []userHealth `json:"users"`
+}
+
+// setAlive records whether the user runner's search loop is running
+func (ur *userRunner) setAlive(alive bool) {
+	ur.statusMu.Lock()
+	defer ur.statusMu.Unlock()
+	ur.alive = alive
+}
+
+// recordSearchResult records the outcome of a search cycle finishing at now.
+// A successful cycle updates the last search time and clears the last error.
+func (ur *userRunner) recordSearchResult(err error, now time.Time) {
+	ur.statusMu.Lock()
+	defer ur.statusMu.Unlock()
+	ur.lastSearchError = err
+	if err == nil {
+		ur.lastSearchTime = now
+	}
+}
+
+// health returns the user runner's entry in the health report
+func (ur *userRunner) health() userHealth {
+	ur.statusMu.Lock()
+	defer ur.statusMu.Unlock()
+
+	h := userHealth{Name: ur.userConfig.Name, Alive: ur.alive}
+	if !ur.lastSearchTime.IsZero() {
+		t := ur.lastSearchTime
+		h.LastSearchTime = &t
+	}
+	if ur.lastSearchError != nil {
+		h.LastError = ur.lastSearchError.Error()
+	}
+	return h
+}
+
+// health reports each user runner's status, and whether the runner is healthy:
+// running with at least one user runner alive
+func (sr *SearchRunner) health() (healthReport, bool) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	report := healthReport{Users: make([]userHealth, 0, len(sr.userRunners))}
+	alive := 0
+	for _, ur := range sr.userRunners {
+		h := ur.health()
+		if h.Alive {
+			alive++
+		}
+		report.Users = append(report.Users, h)
+	}
+	sort.Slice(report.Users, func(i, j int) bool {
+		return report.Users[i].Name < report.Users[j].Name
+	})
+
+	healthy := sr.running && alive > 0
+	report.Status = "unhealthy"
+	if healthy {
+		report.Status = "ok"
+	}
+	return report, healthy
+}
+
+// serveHealthz writes the health report as JSON, with status 200 when healthy and 503 otherwise
+func (sr *SearchRunner) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, healthy := sr.health()
+	w.Header().Set("Content-Type", "application/json")
+	if healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// serveHealth serves the runner's health report at /healthz on addr in the
+// background, returning a function that shuts the server down
+func serveHealth(addr string, sr *SearchRunner) (func(), error) {
+	return serveHTTP("health", addr, "/healthz", http.HandlerFunc(sr.serveHealthz))
+}
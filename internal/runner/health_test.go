@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getHealthz(t *testing.T, sr *SearchRunner) (int, healthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	sr.serveHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	var report healthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode health report %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, report
+}
+
+func TestHealthz(t *testing.T) {
+	ur, _ := newFixtureRunner(t, "search_results.html")
+	sr := &SearchRunner{userRunners: map[string]*userRunner{"user1": ur}, stopChan: make(chan struct{})}
+
+	// Not running yet
+	code, report := getHealthz(t, sr)
+	if code != http.StatusServiceUnavailable || report.Status != "unhealthy" {
+		t.Errorf("Expected 503 before starting, got %d %q", code, report.Status)
+	}
+
+	sr.setRunning(true)
+	ur.setAlive(true)
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	code, report = getHealthz(t, sr)
+	if code != http.StatusOK || report.Status != "ok" {
+		t.Fatalf("Expected 200 while running, got %d %q", code, report.Status)
+	}
+	if len(report.Users) != 1 {
+		t.Fatalf("Expected 1 user in the report, got %d", len(report.Users))
+	}
+	user := report.Users[0]
+	if user.Name != "user1" || !user.Alive || user.LastSearchTime == nil || user.LastError != "" {
+		t.Errorf("Unexpected user status after a successful search: %+v", user)
+	}
+
+	// A failed cycle is reported, keeping the last successful search time
+	ur.userConfig.Zipcode = ""
+	if err := ur.runOnce(context.Background()); err == nil {
+		t.Fatal("Expected runOnce to fail without a zipcode")
+	}
+	_, report = getHealthz(t, sr)
+	if user := report.Users[0]; user.LastError == "" || user.LastSearchTime == nil {
+		t.Errorf("Expected the last error and last successful search time, got %+v", user)
+	}
+
+	// No user runner alive
+	ur.setAlive(false)
+	if code, _ := getHealthz(t, sr); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 with no user runner alive, got %d", code)
+	}
+}
+
+func TestHealthz_MethodNotAllowed(t *testing.T) {
+	sr := &SearchRunner{userRunners: map[string]*userRunner{}, stopChan: make(chan struct{})}
+	rec := httptest.NewRecorder()
+	sr.serveHealthz(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
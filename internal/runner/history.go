@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"path/filepath"
+
+	"github.com/toozej/go-find-liquor/internal/history"
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// openPriceHistory opens the user's price history file in stateDir, identifying
+// items by keyFields (history.DefaultKeyFields if empty)
+func openPriceHistory(stateDir, userName string, keyFields []string) (*history.PriceHistory, error) {
+	return history.Open(filepath.Join(stateDir, safeFileName(userName)+".json"), history.WithKeyFields(keyFields))
+}
+
+// newOrCheaper returns the found items that newly appeared in stock at a store
+// or are cheaper there than last time, then records the results of every
+// searched item in the price history. Without a price history all found items
+// are returned.
+func (ur *userRunner) newOrCheaper(found []search.LiquorItem, searched []string) []search.LiquorItem {
+	if ur.prices == nil {
+		return found
+	}
+
+	var notify []search.LiquorItem
+	byQuery := make(map[string][]search.LiquorItem)
+	for _, item := range found {
+		if ur.prices.IsNewOrCheaper(item) {
+			if old, changed := ur.prices.DetectPriceChange(item); changed {
+				ur.log.Infof("Price of %s at %s dropped from %s to %s", item.Name, item.Store, old, item.Price)
+			}
+			notify = append(notify, item)
+		}
+		byQuery[item.Query] = append(byQuery[item.Query], item)
+	}
+
+	for _, query := range searched {
+		ur.prices.Record(query, byQuery[query])
+	}
+	if err := ur.prices.Save(); err != nil {
+		ur.log.Errorf("Failed to save price history: %v", err)
+	}
+
+	return notify
+}
@@ -0,0 +1,11 @@
+package runner
+
+import (
+	"github.com/toozej/go-find-liquor/internal/metrics"
+)
+
+// serveMetrics serves m at /metrics on addr in the background, returning a
+// function that shuts the server down
+func serveMetrics(addr string, m *metrics.Metrics) (func(), error) {
+	return serveHTTP("metrics", addr, "/metrics", m)
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/toozej/go-find-liquor/internal/metrics"
+)
+
+func TestRunner_SearchMetrics(t *testing.T) {
+	ur, _ := newFixtureRunner(t, "search_results.html")
+	ur.metrics = metrics.New()
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ur.metrics.Write(&buf); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`gfl_searches_attempted_total{user="user1"} 1`,
+		`gfl_searches_succeeded_total{user="user1"} 1`,
+		`gfl_items_in_stock{user="user1"} 2`,
+		`gfl_search_duration_seconds_count{user="user1"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	// Find a free port to serve on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	m := metrics.New()
+	m.SetItemsInStock("user1", 4)
+	stop, err := serveMetrics(addr, m)
+	if err != nil {
+		t.Fatalf("Failed to serve metrics: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `gfl_items_in_stock{user="user1"} 4`) {
+		t.Errorf("Expected served metrics, got: %s", body)
+	}
+
+	stop()
+	if resp, err := http.Get("http://" + addr + "/metrics"); err == nil {
+		resp.Body.Close()
+		t.Error("Expected the metrics server to be shut down")
+	}
+}
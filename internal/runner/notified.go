@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"slices"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// notifiedItem records when an item was last notified about
+type notifiedItem struct {
+	query string
+	at    time.Time
+}
+
+// notifiedKey identifies an item at a store, scoped to the search term that found it
+func notifiedKey(item search.LiquorItem) string {
+	return item.Query + "|" + item.Code + "|" + item.Store
+}
+
+// unnotified returns the found items that haven't been notified about yet, or
+// were last notified more than renotifyAfter ago (never, if zero), and marks
+// them as notified at now. Items that a search no longer finds are forgotten,
+// so they are notified again once they come back in stock. All items are
+// returned when newOnly is disabled.
+func (ur *userRunner) unnotified(found []search.LiquorItem, searched []string, now time.Time) []search.LiquorItem {
+	if !ur.newOnly {
+		return found
+	}
+	if ur.notified == nil {
+		ur.notified = make(map[string]notifiedItem)
+	}
+
+	inStock := make(map[string]bool, len(found))
+	for _, item := range found {
+		inStock[notifiedKey(item)] = true
+	}
+	for key, seen := range ur.notified {
+		if !inStock[key] && slices.Contains(searched, seen.query) {
+			delete(ur.notified, key)
+		}
+	}
+
+	var notify []search.LiquorItem
+	for _, item := range found {
+		key := notifiedKey(item)
+		if seen, ok := ur.notified[key]; ok && (ur.renotifyAfter <= 0 || now.Sub(seen.at) < ur.renotifyAfter) {
+			continue
+		}
+		ur.notified[key] = notifiedItem{query: item.Query, at: now}
+		notify = append(notify, item)
+	}
+	return notify
+}
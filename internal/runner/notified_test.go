@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// TestPipeline_NotifyNewOnly tests that a second cycle with identical results sends no found notifications
+func TestPipeline_NotifyNewOnly(t *testing.T) {
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+	ur.newOnly = true
+
+	countFound := func() int {
+		found := 0
+		for _, sent := range recorder.sent {
+			if strings.Contains(sent, "GFL - Found") {
+				found++
+			}
+		}
+		return found
+	}
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	first := countFound()
+	if first != 2 {
+		t.Fatalf("Expected 2 found notifications in the first cycle, got %d", first)
+	}
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	if got := countFound() - first; got != 0 {
+		t.Errorf("Expected no found notifications for identical results, got %d", got)
+	}
+}
+
+func TestUnnotified(t *testing.T) {
+	storeA := search.LiquorItem{Name: "BLANTON'S", Code: "0171B", Store: "Store A", Query: "blanton"}
+	storeB := search.LiquorItem{Name: "BLANTON'S", Code: "0171B", Store: "Store B", Query: "blanton"}
+	weller := search.LiquorItem{Name: "WELLER", Code: "0223B", Store: "Store A", Query: "weller"}
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	ur := &userRunner{newOnly: true, renotifyAfter: 24 * time.Hour}
+	if got := ur.unnotified([]search.LiquorItem{storeA, storeB, weller}, []string{"blanton", "weller"}, now); len(got) != 3 {
+		t.Fatalf("Expected all items to be new, got %d", len(got))
+	}
+
+	// Store B sells out and the weller search fails: weller stays remembered
+	if got := ur.unnotified([]search.LiquorItem{storeA}, []string{"blanton"}, now.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("Expected no repeat notifications, got %v", got)
+	}
+
+	// Store B restocks; weller is still in stock
+	got := ur.unnotified([]search.LiquorItem{storeA, storeB, weller}, []string{"blanton", "weller"}, now.Add(2*time.Hour))
+	if len(got) != 1 || got[0].Store != "Store B" {
+		t.Errorf("Expected only the restocked store to be notified, got %v", got)
+	}
+
+	// After the window, items still in stock are notified again
+	got = ur.unnotified([]search.LiquorItem{storeA, storeB, weller}, []string{"blanton", "weller"}, now.Add(25*time.Hour))
+	if len(got) != 2 {
+		t.Errorf("Expected the 2 items notified over 24h ago to be reminded, got %v", got)
+	}
+
+	// Disabled: everything is returned
+	ur = &userRunner{}
+	if got := ur.unnotified([]search.LiquorItem{storeA}, []string{"blanton"}, now); len(got) != 1 {
+		t.Errorf("Expected all items when disabled, got %d", len(got))
+	}
+}
@@ -0,0 +1,195 @@
+package runner
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// recordingNotifier captures every notification sent through it
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, subject, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, fmt.Sprintf("subject: %s\nmessage: %s\n", subject, message))
+	return nil
+}
+
+// newFixtureServer serves the OLCC age verification and search endpoints,
+// answering every search with the named fixture from testdata
+func newFixtureServer(t *testing.T, fixture string) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", fixture, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body><form></form></body></html>"))
+	})
+	mux.HandleFunc("/servlet/WelcomeController", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/servlet/FrontController", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("productSearchParam") == "" {
+			http.Error(w, "missing search parameters", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(body)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFixtureRunner creates a user runner searching the fixture server for one item
+// with a fixed clock, recording its notifications
+func newFixtureRunner(t *testing.T, fixture string) (*userRunner, *recordingNotifier) {
+	t.Helper()
+	server := newFixtureServer(t, fixture)
+	recorder := &recordingNotifier{}
+	foundAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	userConfig := config.UserConfig{
+		Name:     "user1",
+		Items:    config.NewItems("Jack Daniels"),
+		Zipcode:  "97201",
+		Distance: 10,
+	}
+	searchOpts := []search.SearcherOption{
+		search.WithBaseURL(server.URL),
+		search.WithClock(func() time.Time { return foundAt }),
+	}
+	notifyOpts := []notification.ManagerOption{
+		notification.WithNotifiers(recorder),
+		notification.WithHeartbeatTemplate("GFL is still running for {{.User}}, searching for {{.ItemsWatched}} item(s)"),
+	}
+
+	ur, err := newUserRunner(userConfig, nil, time.Hour, "test-agent", nil, searchOpts, notifyOpts)
+	if err != nil {
+		t.Fatalf("Failed to create user runner: %v", err)
+	}
+	return ur, recorder
+}
+
+// TestPipeline_FixtureToNotifications runs a full search cycle against fixture HTML
+// and compares the notifications sent with a golden file
+func TestPipeline_FixtureToNotifications(t *testing.T) {
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+	ur.heartbeatEvery = time.Hour
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	got := strings.Join(recorder.sent, "\n")
+	golden := filepath.Join("testdata", "pipeline.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0600); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Notifications do not match %s (run with -update to regenerate)\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+// TestPipeline_SkipUnchangedCycles tests that identical consecutive cycles notify only once
+func TestPipeline_SkipUnchangedCycles(t *testing.T) {
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+	ur.skipUnchanged = true
+
+	for i := 0; i < 2; i++ {
+		if err := ur.runOnce(context.Background()); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+	}
+
+	found := 0
+	for _, sent := range recorder.sent {
+		if strings.Contains(sent, "GFL - Found") {
+			found++
+		}
+	}
+	// The fixture has 2 stores in stock, notified individually in the first cycle only
+	if found != 2 {
+		t.Errorf("Expected 2 found notifications across identical cycles, got %d", found)
+	}
+}
+
+// TestPipeline_PriceHistory tests that items already notified at the same price
+// aren't notified again, including after a restart
+func TestPipeline_PriceHistory(t *testing.T) {
+	stateDir := t.TempDir()
+
+	found := 0
+	for i := 0; i < 2; i++ {
+		ur, recorder := newFixtureRunner(t, "search_results.html")
+		prices, err := openPriceHistory(stateDir, ur.userConfig.Name, nil)
+		if err != nil {
+			t.Fatalf("Failed to open price history: %v", err)
+		}
+		ur.prices = prices
+
+		if err := ur.runOnce(context.Background()); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+		for _, sent := range recorder.sent {
+			if strings.Contains(sent, "GFL - Found") {
+				found++
+			}
+		}
+	}
+
+	// The fixture has 2 stores in stock, notified on the first run only
+	if found != 2 {
+		t.Errorf("Expected 2 found notifications across runs with price history, got %d", found)
+	}
+}
+
+// TestPipeline_DelayBetweenDuplicateItems tests that the wait between searches
+// is skipped only after the last position, even when the last item is also listed earlier
+func TestPipeline_DelayBetweenDuplicateItems(t *testing.T) {
+	ur, _ := newFixtureRunner(t, "search_results.html")
+	ur.userConfig.Items = config.NewItems("Jack Daniels", "Weller", "Jack Daniels")
+
+	delays := 0
+	ur.searchDelay = func() time.Duration {
+		delays++
+		return 0
+	}
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	// One wait after each of the first two searches, none after the last
+	if delays != 2 {
+		t.Errorf("Expected 2 waits between 3 searches, got %d", delays)
+	}
+}
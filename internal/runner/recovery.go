@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultFailureAlertCooldown is the least time between failure alerts while
+// searches keep failing, unless failure_alert_cooldown is set
+const DefaultFailureAlertCooldown = 24 * time.Hour
+
+// recordOutcome tracks consecutive failed search cycles, where no item could be
+// searched. After failureAlert failures in a row a failure alert is sent, at
+// most once per cooldown while failures continue. The first successful cycle
+// after at least recoveryAlert failures in a row, or after a failure alert,
+// sends a notification that searches have recovered.
+func (ur *userRunner) recordOutcome(ctx context.Context, failed bool) {
+	if failed {
+		ur.failedCycles++
+		ur.alertFailing(ctx, time.Now())
+		return
+	}
+
+	failures := ur.failedCycles
+	alerted := !ur.lastFailureAlert.IsZero()
+	ur.failedCycles = 0
+	ur.lastFailureAlert = time.Time{}
+	ur.lastFailure = nil
+	if !alerted && (ur.recoveryAlert <= 0 || failures < ur.recoveryAlert) {
+		return
+	}
+
+	notifyCtx, cancel, ok := ur.notifyContext(ctx)
+	defer cancel()
+	if !ok {
+		return
+	}
+
+	ur.log.Infof("Searches recovered after %d failed cycles", failures)
+	if err := ur.notifier.NotifyRecovered(notifyCtx, failures); err != nil {
+		ur.log.Warnf("Failed to send recovery notification: %v", err)
+	}
+}
+
+// alertFailing sends a failure alert once the failure streak reaches
+// failureAlert, unless one was sent within the cooldown
+func (ur *userRunner) alertFailing(ctx context.Context, now time.Time) {
+	if ur.failureAlert <= 0 || ur.failedCycles < ur.failureAlert {
+		return
+	}
+	cooldown := ur.failureAlertCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultFailureAlertCooldown
+	}
+	if !ur.lastFailureAlert.IsZero() && now.Sub(ur.lastFailureAlert) < cooldown {
+		return
+	}
+
+	notifyCtx, cancel, ok := ur.notifyContext(ctx)
+	defer cancel()
+	if !ok {
+		return
+	}
+
+	ur.lastFailureAlert = now
+	ur.log.Warnf("Searches have failed %d cycles in a row, sending a failure alert", ur.failedCycles)
+	if err := ur.notifier.NotifySearchFailing(notifyCtx, ur.userConfig.Name, ur.failedCycles, ur.lastFailure); err != nil {
+		ur.log.Warnf("Failed to send failure alert: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// countRecoveries returns how many recovery notifications recorder received
+func countRecoveries(recorder *recordingNotifier) int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	count := 0
+	for _, sent := range recorder.sent {
+		if strings.Contains(sent, "GFL - Searches recovered") {
+			count++
+		}
+	}
+	return count
+}
+
+// newFailingRunner returns a fixture runner whose searches fail while the
+// returned flag is set
+func newFailingRunner(t *testing.T) (*userRunner, *recordingNotifier, *atomic.Bool) {
+	t.Helper()
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+
+	fixture := newFixtureServer(t, "search_results.html")
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() && r.URL.Path == "/servlet/FrontController" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fixture.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	ur.searcher = search.NewSearcher("test-agent", search.WithBaseURL(server.URL))
+	return ur, recorder, &failing
+}
+
+// TestRunner_RecoveryNotification tests that the first successful cycle after a
+// failure streak sends a recovery notification exactly once
+func TestRunner_RecoveryNotification(t *testing.T) {
+	ur, recorder, failing := newFailingRunner(t)
+	ur.recoveryAlert = 2
+	ctx := context.Background()
+
+	failing.Store(true)
+	for i := 0; i < 3; i++ {
+		if err := ur.runOnce(ctx); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+	}
+	if got := countRecoveries(recorder); got != 0 {
+		t.Fatalf("Expected no recovery notification while failing, got %d", got)
+	}
+
+	failing.Store(false)
+	for i := 0; i < 2; i++ {
+		if err := ur.runOnce(ctx); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+	}
+	if got := countRecoveries(recorder); got != 1 {
+		t.Errorf("Expected exactly 1 recovery notification, got %d", got)
+	}
+	if !strings.Contains(strings.Join(recorder.sent, ""), "after 3 failed searches in a row") {
+		t.Errorf("Expected the recovery notification to report the failure streak, got: %v", recorder.sent)
+	}
+}
+
+// TestRunner_RecoveryBelowThreshold tests that a short failure streak doesn't notify
+func TestRunner_RecoveryBelowThreshold(t *testing.T) {
+	ur, recorder := newDrySpellRunner(t, 0)
+	ur.recoveryAlert = 3
+	ctx := context.Background()
+
+	ur.recordOutcome(ctx, true)
+	ur.recordOutcome(ctx, true)
+	ur.recordOutcome(ctx, false)
+	if len(recorder.sent) != 0 {
+		t.Errorf("Expected no recovery notification below the threshold, got %d", len(recorder.sent))
+	}
+	if ur.failedCycles != 0 {
+		t.Errorf("Expected a successful cycle to reset the failure streak, got %d", ur.failedCycles)
+	}
+}
+
+// countSent returns how many notifications recorder received with subject
+func countSent(recorder *recordingNotifier, subject string) int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	count := 0
+	for _, sent := range recorder.sent {
+		if strings.HasPrefix(sent, "subject: "+subject+"\n") {
+			count++
+		}
+	}
+	return count
+}
+
+// TestRunner_FailureAlert tests that a failure streak alerts once it reaches
+// the threshold, again only after the cooldown, and is followed by a recovery
+// notification when searches work again
+func TestRunner_FailureAlert(t *testing.T) {
+	const failingSubject = "GFL - Search failing for user user1"
+	ur, recorder, failing := newFailingRunner(t)
+	ur.failureAlert = 2
+	ur.failureAlertCooldown = time.Hour
+	ctx := context.Background()
+
+	failing.Store(true)
+	if err := ur.runOnce(ctx); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	if got := countSent(recorder, failingSubject); got != 0 {
+		t.Fatalf("Expected no failure alert below the threshold, got %d", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := ur.runOnce(ctx); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+	}
+	if got := countSent(recorder, failingSubject); got != 1 {
+		t.Fatalf("Expected 1 failure alert within the cooldown, got %d", got)
+	}
+	if !strings.Contains(strings.Join(recorder.sent, ""), "503 Service Unavailable") {
+		t.Errorf("Expected the failure alert to include the last error, got: %v", recorder.sent)
+	}
+
+	// Once the cooldown has passed a still failing search alerts again
+	ur.lastFailureAlert = ur.lastFailureAlert.Add(-time.Hour)
+	if err := ur.runOnce(ctx); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	if got := countSent(recorder, failingSubject); got != 2 {
+		t.Errorf("Expected another failure alert after the cooldown, got %d", got)
+	}
+
+	// Recovery is notified even without recovery_alert set
+	failing.Store(false)
+	if err := ur.runOnce(ctx); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	if got := countRecoveries(recorder); got != 1 {
+		t.Errorf("Expected a recovery notification after a failure alert, got %d", got)
+	}
+}
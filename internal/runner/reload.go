@@ -0,0 +1,279 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// configReloadDelay batches the several events editors emit when saving a
+// file into a single reload
+const configReloadDelay = 500 * time.Millisecond
+
+// AddUser adds a runner for a new user, starting it right away if the runner is running
+func (sr *SearchRunner) AddUser(userConfig config.UserConfig) error {
+	sr.changeMu.Lock()
+	defer sr.changeMu.Unlock()
+
+	if sr.HasUser(userConfig.Name) {
+		return fmt.Errorf("user '%s' already exists", userConfig.Name)
+	}
+	if sr.usesUserFiles() {
+		sr.mu.RLock()
+		users := append(sr.userConfigs(), userConfig)
+		sr.mu.RUnlock()
+		if err := checkFileNames(users); err != nil {
+			return err
+		}
+	}
+
+	ur, err := sr.buildUserRunner(userConfig, sr.GetUserCount()+1)
+	if err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.setUser(userConfig.Name, ur)
+	return nil
+}
+
+// userConfigs returns the configs of the current users. The caller must hold sr.mu.
+func (sr *SearchRunner) userConfigs() []config.UserConfig {
+	users := make([]config.UserConfig, 0, len(sr.userRunners))
+	for _, ur := range sr.userRunners {
+		users = append(users, ur.userConfig)
+	}
+	return users
+}
+
+// UpdateUser replaces the runner of an existing user with one for its new
+// config, restarting the user's searches if the runner is running
+func (sr *SearchRunner) UpdateUser(userConfig config.UserConfig) error {
+	sr.changeMu.Lock()
+	defer sr.changeMu.Unlock()
+
+	if !sr.HasUser(userConfig.Name) {
+		return fmt.Errorf("user '%s' does not exist", userConfig.Name)
+	}
+
+	ur, err := sr.buildReplacement(userConfig, sr.GetUserCount())
+	if err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.setUser(userConfig.Name, ur)
+	return nil
+}
+
+// RemoveUser stops and removes the runner of a user, waiting for any search
+// it is running to finish
+func (sr *SearchRunner) RemoveUser(name string) error {
+	sr.changeMu.Lock()
+	defer sr.changeMu.Unlock()
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if _, exists := sr.userRunners[name]; !exists {
+		return fmt.Errorf("user '%s' does not exist", name)
+	}
+	sr.removeUser(name)
+	return nil
+}
+
+// removeUser stops the runner of a user, waits for it to finish and closes
+// it. The caller must hold sr.mu for writing.
+func (sr *SearchRunner) removeUser(name string) {
+	ur := sr.userRunners[name]
+	logger.Infof("Stopping user runner for '%s'", name)
+	ur.stop()
+	ur.wait()
+	ur.close()
+	delete(sr.userRunners, name)
+}
+
+// setUser sets the runner of a user, starting it if the runner is running.
+// A runner it replaces is stopped and waited for first, including any search
+// in progress, and ur takes over its files and state. ur must come from
+// buildReplacement if the user exists, and buildUserRunner otherwise. The
+// caller must hold sr.mu for writing.
+func (sr *SearchRunner) setUser(name string, ur *userRunner) {
+	if old, exists := sr.userRunners[name]; exists {
+		logger.Infof("Stopping user runner for '%s'", name)
+		old.stop()
+		old.wait()
+		ur.takeOver(old)
+		old.close()
+	}
+	sr.userRunners[name] = ur
+	if sr.runCtx != nil {
+		sr.launch(name, ur)
+	}
+}
+
+// Reload applies the users of cfg: new users are added, removed users are
+// stopped, and users whose config changed are restarted. Unchanged users keep
+// running undisturbed. Every new runner is built before anything is applied,
+// so a config that fails leaves the running users as they were. Global
+// settings are shared by every user, so changes to them need a restart.
+func (sr *SearchRunner) Reload(cfg config.Config) error {
+	if len(cfg.Users) == 0 {
+		return fmt.Errorf("no users configured")
+	}
+
+	sr.changeMu.Lock()
+	defer sr.changeMu.Unlock()
+
+	sr.mu.RLock()
+	current := make(map[string]config.UserConfig, len(sr.userRunners))
+	for name, ur := range sr.userRunners {
+		current[name] = ur.userConfig
+	}
+	oldGlobal := sr.config
+	sr.mu.RUnlock()
+
+	newGlobal := cfg
+	oldGlobal.Users, newGlobal.Users = nil, nil
+	if !reflect.DeepEqual(oldGlobal, newGlobal) {
+		logger.Warn("Global settings changed, restart GFL to apply them; only users are reloaded")
+	}
+	if sr.usesUserFiles() {
+		if err := checkFileNames(cfg.Users); err != nil {
+			return err
+		}
+	}
+
+	built := make(map[string]*userRunner)
+	keep := make(map[string]bool, len(cfg.Users))
+	for _, userConfig := range cfg.Users {
+		keep[userConfig.Name] = true
+		old, exists := current[userConfig.Name]
+		if exists && reflect.DeepEqual(old, userConfig) {
+			continue
+		}
+		build := sr.buildUserRunner
+		if exists {
+			build = sr.buildReplacement
+		}
+		ur, err := build(userConfig, len(cfg.Users))
+		if err != nil {
+			for _, ur := range built {
+				ur.close()
+			}
+			return err
+		}
+		built[userConfig.Name] = ur
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for name, ur := range built {
+		if _, exists := current[name]; exists {
+			logger.Infof("Reloading changed user '%s'", name)
+		} else {
+			logger.Infof("Adding user '%s'", name)
+		}
+		sr.setUser(name, ur)
+	}
+	for name := range sr.userRunners {
+		if keep[name] {
+			continue
+		}
+		logger.Infof("Removing user '%s'", name)
+		sr.removeUser(name)
+	}
+	sr.config.Users = cfg.Users
+	return nil
+}
+
+// WatchConfig reloads users whenever the config file at path, or a user's
+// items file, changes, until ctx is cancelled. load reads and validates the
+// changed configuration; if it fails, the change is ignored and the current
+// configuration is kept.
+func (sr *SearchRunner) WatchConfig(ctx context.Context, path string, load func() (config.Config, error)) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the file, since editors often save by
+	// replacing the file, which ends a watch on the file itself
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+	logger.Infof("Watching %s for configuration changes", absPath)
+	watched := sr.watchItemsFiles(watcher, absPath)
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			name := filepath.Clean(event.Name)
+			if name != absPath && !watched[name] || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			reload = time.After(configReloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warnf("Error watching config file: %v", err)
+		case <-reload:
+			reload = nil
+			logger.Infof("Configuration file %s changed, reloading", absPath)
+			cfg, err := load()
+			if err == nil {
+				err = sr.Reload(cfg)
+			}
+			if err != nil {
+				logger.Errorf("Ignoring invalid configuration change, keeping the current configuration: %v", err)
+			}
+			watched = sr.watchItemsFiles(watcher, absPath)
+		}
+	}
+}
+
+// watchItemsFiles adds the directories of the users' items files to watcher,
+// returning the items files' absolute paths
+func (sr *SearchRunner) watchItemsFiles(watcher *fsnotify.Watcher, configPath string) map[string]bool {
+	sr.mu.RLock()
+	users := sr.config.Users
+	sr.mu.RUnlock()
+
+	watched := make(map[string]bool)
+	for _, user := range users {
+		if user.ItemsFile == "" {
+			continue
+		}
+		path, err := config.ItemsFilePath(configPath, user.ItemsFile)
+		if err != nil {
+			logger.Warnf("Not watching items file of user '%s': %v", user.Name, err)
+			continue
+		}
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			logger.Warnf("Not watching items file of user '%s': %v", user.Name, err)
+			continue
+		}
+		watched[path] = true
+	}
+	return watched
+}
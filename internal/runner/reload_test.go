@@ -0,0 +1,342 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// reloadTestUser returns a user config that only searches once a year, so
+// running it makes no requests during the test
+func reloadTestUser(name string, items ...string) config.UserConfig {
+	return config.UserConfig{
+		Name:     name,
+		Items:    config.NewItems(items...),
+		Zipcode:  "97201",
+		Distance: 10,
+		Cron:     "0 0 1 1 *",
+		Notifications: []config.NotificationConfig{
+			{Type: "gotify", Endpoint: "http://localhost:8080", Credential: map[string]string{"token": "test-token"}},
+		},
+	}
+}
+
+func newReloadTestRunner(t *testing.T, users ...config.UserConfig) *SearchRunner {
+	t.Helper()
+	r, err := NewRunner(config.Config{
+		Interval:  time.Hour,
+		UserAgent: "test-agent",
+		Users:     users,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	return r.(*SearchRunner)
+}
+
+// userRunnerFor returns the current runner of a user
+func userRunnerFor(sr *SearchRunner, name string) *userRunner {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	return sr.userRunners[name]
+}
+
+func TestRunner_AddRemoveUpdateUser(t *testing.T) {
+	sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"))
+
+	if err := sr.AddUser(reloadTestUser("bob", "Eagle Rare")); err != nil {
+		t.Fatalf("AddUser failed: %v", err)
+	}
+	if sr.GetUserCount() != 2 || !sr.HasUser("bob") {
+		t.Fatalf("Expected bob to be added, got %d users", sr.GetUserCount())
+	}
+	if err := sr.AddUser(reloadTestUser("bob")); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an error adding an existing user, got: %v", err)
+	}
+
+	before := userRunnerFor(sr, "bob")
+	if err := sr.UpdateUser(reloadTestUser("bob", "Eagle Rare", "Weller")); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if after := userRunnerFor(sr, "bob"); after == before || len(after.userConfig.Items) != 2 {
+		t.Errorf("Expected bob's runner to be replaced with the new items, got %v", after.userConfig.Items)
+	}
+	if err := sr.UpdateUser(reloadTestUser("carol")); err == nil {
+		t.Error("Expected an error updating an unknown user")
+	}
+
+	if err := sr.RemoveUser("alice"); err != nil {
+		t.Fatalf("RemoveUser failed: %v", err)
+	}
+	if sr.GetUserCount() != 1 || sr.HasUser("alice") {
+		t.Errorf("Expected only bob to remain, got %d users", sr.GetUserCount())
+	}
+	if err := sr.RemoveUser("alice"); err == nil {
+		t.Error("Expected an error removing an unknown user")
+	}
+}
+
+func TestRunner_Reload(t *testing.T) {
+	sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"), reloadTestUser("bob", "Eagle Rare"))
+	alice := userRunnerFor(sr, "alice")
+	bob := userRunnerFor(sr, "bob")
+
+	cfg := sr.config
+	cfg.Users = []config.UserConfig{
+		reloadTestUser("alice", "Blanton's"),
+		reloadTestUser("bob", "Eagle Rare", "Weller"),
+		reloadTestUser("carol", "Stagg"),
+	}
+	if err := sr.Reload(cfg); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if sr.GetUserCount() != 3 || !sr.HasUser("carol") {
+		t.Fatalf("Expected carol to be added, got %d users", sr.GetUserCount())
+	}
+	if userRunnerFor(sr, "alice") != alice {
+		t.Error("Expected unchanged user alice to keep running undisturbed")
+	}
+	if userRunnerFor(sr, "bob") == bob {
+		t.Error("Expected changed user bob to be restarted")
+	}
+
+	cfg.Users = cfg.Users[1:]
+	if err := sr.Reload(cfg); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if sr.GetUserCount() != 2 || sr.HasUser("alice") {
+		t.Errorf("Expected alice to be removed, got %d users", sr.GetUserCount())
+	}
+
+	// A config that fails to build keeps the current users
+	invalid := cfg
+	invalid.Users = []config.UserConfig{reloadTestUser("dave")}
+	invalid.Users[0].Notifications = []config.NotificationConfig{{Type: "carrier-pigeon"}}
+	if err := sr.Reload(invalid); err == nil {
+		t.Fatal("Expected Reload to reject an invalid config")
+	}
+	if sr.GetUserCount() != 2 || !sr.HasUser("bob") || !sr.HasUser("carol") || sr.HasUser("dave") {
+		t.Errorf("Expected the previous users to be kept after an invalid reload, got %d users", sr.GetUserCount())
+	}
+}
+
+func TestRunner_AddUserWhileRunning(t *testing.T) {
+	sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- sr.Start(ctx)
+	}()
+	waitFor(t, "alice to start", func() bool { return userRunnerFor(sr, "alice").health().Alive })
+
+	if err := sr.AddUser(reloadTestUser("bob", "Eagle Rare")); err != nil {
+		t.Fatalf("AddUser failed: %v", err)
+	}
+	if sr.GetUserCount() != 2 {
+		t.Errorf("Expected 2 users, got %d", sr.GetUserCount())
+	}
+	waitFor(t, "bob to start", func() bool { return userRunnerFor(sr, "bob").health().Alive })
+
+	bob := userRunnerFor(sr, "bob")
+	if err := sr.RemoveUser("bob"); err != nil {
+		t.Fatalf("RemoveUser failed: %v", err)
+	}
+	waitFor(t, "bob to stop", func() bool { return !bob.health().Alive })
+
+	sr.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Start to return cleanly, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Runner did not stop")
+	}
+}
+
+// TestRunner_ReloadDuringSearch tests that reloading a user waits for the
+// search of their old runner to finish before the new runner starts, and that
+// the new runner takes over the old one's log file and price history
+func TestRunner_ReloadDuringSearch(t *testing.T) {
+	blocking := &blockingSearcher{started: make(chan struct{}, 1)}
+	alice := config.UserConfig{Name: "alice", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10}
+	cfg := config.Config{
+		Interval:      time.Hour,
+		PerUserLogs:   true,
+		PerUserLogDir: t.TempDir(),
+		StateDir:      t.TempDir(),
+		Users:         []config.UserConfig{alice},
+	}
+	r, err := NewRunner(cfg, WithSearcherFactory(func(config.UserConfig) Searcher { return blocking }))
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	sr := r.(*SearchRunner)
+	old := userRunnerFor(sr, "alice")
+	logFile, prices := old.logFile, old.prices
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- sr.Start(ctx)
+	}()
+	waitForSearch := func() {
+		t.Helper()
+		select {
+		case <-blocking.started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a search to start")
+		}
+	}
+	waitForSearch()
+
+	alice.Distance = 25
+	cfg.Users = []config.UserConfig{alice}
+	if err := sr.Reload(cfg); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !blocking.returned.Load() {
+		t.Error("Expected the old runner's search to have finished when Reload returned")
+	}
+
+	current := userRunnerFor(sr, "alice")
+	if current == old {
+		t.Fatal("Expected alice to be restarted")
+	}
+	if current.logFile != logFile || current.prices != prices {
+		t.Error("Expected the new runner to take over the log file and price history")
+	}
+	waitForSearch()
+
+	sr.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Start to return cleanly, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Runner did not stop")
+	}
+}
+
+func TestRunner_WatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("users: []\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"))
+	loads := make(chan struct{}, 10)
+	reloaded := sr.config
+	reloaded.Users = []config.UserConfig{reloadTestUser("alice", "Blanton's"), reloadTestUser("bob", "Eagle Rare")}
+	load := func() (config.Config, error) {
+		loads <- struct{}{}
+		return reloaded, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- sr.WatchConfig(ctx, path, load)
+	}()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("WatchConfig failed: %v", err)
+		}
+	}()
+
+	// Give the watcher time to start, then change the config file
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("users: [alice, bob]\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	waitFor(t, "the config to be reloaded", func() bool { return sr.GetUserCount() == 2 })
+	if len(loads) != 1 {
+		t.Errorf("Expected the change to be loaded once, got %d loads", len(loads))
+	}
+
+	// Changes to other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "other.yaml"), []byte("x: 1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write other file: %v", err)
+	}
+	time.Sleep(2 * configReloadDelay)
+	if len(loads) != 1 {
+		t.Errorf("Expected changes to other files to be ignored, got %d loads", len(loads))
+	}
+}
+
+// TestRunner_WatchConfigItemsFile tests that changing a user's items file
+// reloads the configuration
+func TestRunner_WatchConfigItemsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	itemsPath := filepath.Join(dir, "lists", "alice.txt")
+	if err := os.MkdirAll(filepath.Dir(itemsPath), 0750); err != nil {
+		t.Fatalf("Failed to create items directory: %v", err)
+	}
+	for _, file := range []string{path, itemsPath} {
+		if err := os.WriteFile(file, []byte("\n"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	alice := reloadTestUser("alice", "Blanton's")
+	alice.ItemsFile = "lists/alice.txt"
+	sr := newReloadTestRunner(t, alice)
+	loads := make(chan struct{}, 10)
+	reloaded := sr.config
+	updated := alice
+	updated.Items = config.NewItems("Blanton's", "Eagle Rare")
+	reloaded.Users = []config.UserConfig{updated}
+	load := func() (config.Config, error) {
+		loads <- struct{}{}
+		return reloaded, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- sr.WatchConfig(ctx, path, load)
+	}()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("WatchConfig failed: %v", err)
+		}
+	}()
+
+	// Give the watcher time to start, then change the items file
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(itemsPath, []byte("Eagle Rare\n"), 0600); err != nil {
+		t.Fatalf("Failed to write items file: %v", err)
+	}
+
+	waitFor(t, "the items file change to be reloaded", func() bool {
+		return len(userRunnerFor(sr, "alice").userConfig.Items) == 2
+	})
+	if len(loads) != 1 {
+		t.Errorf("Expected the change to be loaded once, got %d loads", len(loads))
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
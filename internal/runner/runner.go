@@ -3,173 +3,531 @@ package runner
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/toozej/go-find-liquor/internal/history"
+	"github.com/toozej/go-find-liquor/internal/logging"
+	"github.com/toozej/go-find-liquor/internal/metrics"
 	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/internal/schedule"
 	"github.com/toozej/go-find-liquor/internal/search"
 	"github.com/toozej/go-find-liquor/pkg/config"
 )
 
+// logger is the runner component logger, whose level can be set separately
+var logger = logging.For(logging.Runner)
+
+// notifyRetryDelay is the wait before the first retry of a failed notification
+// when notify_retry_delay is not set
+const notifyRetryDelay = 2 * time.Second
+
 // Runner interface defines the contract for all runner implementations
 type Runner interface {
 	Start(ctx context.Context) error
 	Stop()
+	// StopWithContext stops the runner and waits for its user runners to
+	// finish, giving up when ctx is done
+	StopWithContext(ctx context.Context) error
 	RunOnce(ctx context.Context) error
 	// GetUserCount returns the number of configured users (for testing)
 	GetUserCount() int
 	// HasUser returns true if a user with the given name is configured (for testing)
 	HasUser(name string) bool
+	// WatchConfig applies changes to the config file at path to the running users
+	WatchConfig(ctx context.Context, path string, load func() (config.Config, error)) error
+}
+
+// Searcher searches OLCC for an item near a zipcode. *search.Searcher is the
+// real implementation; tests can supply a fake returning canned items.
+type Searcher interface {
+	SearchItem(ctx context.Context, item string, zipcode string, distance int) ([]search.LiquorItem, error)
+}
+
+// sessionStarter is implemented by searchers that track search cycles, such as
+// *search.Searcher rotating its user agent per session
+type sessionStarter interface {
+	StartSession()
+}
+
+// RunnerOption configures optional SearchRunner behavior
+type RunnerOption func(*SearchRunner)
+
+// WithSearcherFactory makes each user search with the Searcher returned for
+// them instead of scraping OLCC, e.g. with a fake in tests
+func WithSearcherFactory(newSearcher func(user config.UserConfig) Searcher) RunnerOption {
+	return func(sr *SearchRunner) {
+		sr.newSearcher = newSearcher
+	}
 }
 
 // userRunner executes periodic searches for a single user (internal implementation)
 type userRunner struct {
-	userConfig  config.UserConfig
-	searcher    *search.Searcher
+	userConfig config.UserConfig
+	// log is the runner logger tagged with the user's name
+	log      *log.Entry
+	searcher Searcher
+	// searchSlots bounds the item searches running at once across all users (nil = unlimited)
+	searchSlots chan struct{}
 	notifier    *notification.NotificationManager
 	stopChan    chan struct{}
+	stopOnce    sync.Once
 	runningCh   chan struct{}
-	interval    time.Duration
+	// done is closed once the runner started by launch has finished (nil = never launched)
+	done     chan struct{}
+	interval time.Duration
+	// cron schedules searches at the times of a cron expression instead of every interval (nil = use interval)
+	cron        *schedule.Schedule
 	commonItems []string
+	findLog     *log.Logger
+	// logFile is the file findLog writes to, closed by close (nil = per-user logs disabled)
+	logFile     io.Closer
+	shuffle     bool
+	minStock    int
+	minStoreQty int
+	// heartbeatEvery is how often heartbeats are sent (0 = never), unless the user sets their own
+	heartbeatEvery time.Duration
+	lastHeartbeat  time.Time
+	flushOnStop    bool
+	drySpell       time.Duration
+	dryStreaks     map[string]*dryStreak
+	zeroAlert      int
+	zeroCycles     int
+	// recoveryAlert is how many failed cycles in a row make the next successful one notify (0 = disabled)
+	recoveryAlert int
+	failedCycles  int
+	// failureAlert is how many failed cycles in a row send a failure alert, at
+	// most once per failureAlertCooldown (0 = disabled)
+	failureAlert         int
+	failureAlertCooldown time.Duration
+	lastFailureAlert     time.Time
+	// lastFailure is the last search error of the current failure streak
+	lastFailure error
+	// searchDelay returns the wait between item searches
+	searchDelay func() time.Duration
+	// startupJitter and tickJitter bound a random wait before the first and each
+	// later search, so users' searches are staggered (0 = disabled)
+	startupJitter time.Duration
+	tickJitter    time.Duration
+	// jitter returns a random wait of up to its argument
+	jitter func(time.Duration) time.Duration
+	// skipUnchanged suppresses found notifications when a cycle's results match the previous cycle's
+	skipUnchanged bool
+	lastResults   string
+	// prices limits found notifications to items that are new in stock or cheaper (nil = disabled)
+	prices *history.PriceHistory
+	// newOnly suppresses repeat notifications for items still in stock, until renotifyAfter passes
+	newOnly       bool
+	renotifyAfter time.Duration
+	notified      map[string]notifiedItem
+	// state persists the user's state across restarts (nil = disabled)
+	state StateStore
+	// onNotifyFailure handles found items no notifier delivered: failureLog, failureFile or failureRetry
+	onNotifyFailure string
+	deadLetterFile  string
+	// metrics records search statistics (nil = disabled)
+	metrics *metrics.Metrics
+	// streamer posts each found item to a webhook as soon as it is found (nil = disabled)
+	streamer  *itemStreamer
+	userCount int
+	startedAt time.Time
+	lastFind  time.Time
+	// statusMu guards the status reported by the health and status endpoints
+	statusMu        sync.Mutex
+	alive           bool
+	lastSearchTime  time.Time
+	lastSearchError error
+	lastResultCount int
+	nextSearchTime  time.Time
 }
 
-// newUserRunner creates a new user runner with the given user configuration (internal function)
-func newUserRunner(userConfig config.UserConfig, interval time.Duration, userAgent string, commonItems []string) (*userRunner, error) {
+// newUserRunner creates a new user runner with the given user configuration (internal function).
+// A nil searcher defaults to scraping OLCC with a search.Searcher built from userAgent and searchOpts.
+func newUserRunner(userConfig config.UserConfig, searcher Searcher, interval time.Duration, userAgent string, commonItems []string,
+	searchOpts []search.SearcherOption, notifyOpts []notification.ManagerOption) (*userRunner, error) {
 	// Initialize the searcher
-	searcher := search.NewSearcher(userAgent)
+	if searcher == nil {
+		s := search.NewSearcher(userAgent, searchOpts...)
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("failed to create searcher for user '%s': %w", userConfig.Name, err)
+		}
+		searcher = s
+	}
 
 	// Initialize notification manager for this user
-	notifier, err := notification.NewNotificationManager(userConfig.Notifications)
+	notifier, err := notification.NewNotificationManager(userConfig.Notifications, notifyOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create notification manager for user '%s': %w", userConfig.Name, err)
 	}
 
 	return &userRunner{
 		userConfig:  userConfig,
+		log:         logger.WithField("user", userConfig.Name),
 		searcher:    searcher,
 		notifier:    notifier,
 		stopChan:    make(chan struct{}),
 		runningCh:   make(chan struct{}, 1),
 		interval:    interval,
 		commonItems: commonItems,
+		userCount:   1,
+		startedAt:   time.Now(),
+		dryStreaks:  make(map[string]*dryStreak),
+		searchDelay: randomSearchDelay,
+		jitter:      randomJitter,
 	}, nil
 }
 
+// randomSearchDelay returns a random wait of up to config.DefaultMaxItemDelay between item searches
+func randomSearchDelay() time.Duration {
+	return randomJitter(config.DefaultMaxItemDelay)
+}
+
+// randomDelayBetween returns a function giving a random wait between minDelay and maxDelay
+func randomDelayBetween(minDelay, maxDelay time.Duration) func() time.Duration {
+	return func() time.Duration {
+		return minDelay + randomJitter(maxDelay-minDelay)
+	}
+}
+
+// randomJitter returns a random wait of up to limit, or zero if limit is not positive
+func randomJitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(limit)))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n.Int64())
+}
+
+// waitJitter waits a random time of up to limit before a search, returning
+// false if the runner was stopped or its context cancelled while waiting
+func (ur *userRunner) waitJitter(ctx context.Context, limit time.Duration) bool {
+	wait := ur.jitter(limit)
+	if wait <= 0 {
+		return true
+	}
+	ur.log.Debugf("Waiting %s before searching", wait)
+
+	select {
+	case <-time.After(wait):
+		return true
+	case <-ur.stopChan:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // start begins periodic searches for this user (internal method)
 func (ur *userRunner) start(ctx context.Context) error {
-	log.Infof("Starting search runner for user '%s'", ur.userConfig.Name)
+	ur.log.Info("Starting search runner")
+	ur.setAlive(true)
+	defer ur.setAlive(false)
+	defer ur.setNextSearch(time.Time{})
 
-	// Initial search
-	go func() {
-		ur.runningCh <- struct{}{}
-		defer func() {
-			<-ur.runningCh
-		}()
-
-		if err := ur.runSearch(ctx, true); err != nil {
-			log.Errorf("Search failed for user '%s': %v", ur.userConfig.Name, err)
-		}
+	// Searches run in their own goroutines. Once the runner stops, cancel any
+	// search in progress and wait for it, so no search outlives start.
+	ctx, cancel := context.WithCancel(ctx)
+	var searches sync.WaitGroup
+	defer func() {
+		cancel()
+		searches.Wait()
 	}()
 
-	// Setup ticker for recurring searches
-	ticker := time.NewTicker(ur.interval)
-	defer ticker.Stop()
+	// Initial search, unless searches only run at the times of a cron schedule
+	if ur.cron == nil {
+		searches.Add(1)
+		go func() {
+			defer searches.Done()
+			ur.runningCh <- struct{}{}
+			defer func() {
+				<-ur.runningCh
+			}()
+
+			if !ur.waitJitter(ctx, ur.startupJitter) {
+				return
+			}
+			if err := ur.runSearch(ctx, true); err != nil {
+				ur.log.Errorf("Search failed: %v", err)
+			}
+		}()
+	}
+
+	// Setup ticker for recurring searches, or a timer reset to each cron time
+	var ticks <-chan time.Time
+	var cronTimer *time.Timer
+	if ur.cron != nil {
+		cronTimer = time.NewTimer(ur.untilNextCron(time.Now()))
+		defer cronTimer.Stop()
+		ticks = cronTimer.C
+	} else {
+		ticker := time.NewTicker(ur.interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+		ur.setNextSearch(time.Now().Add(ur.interval))
+	}
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticks:
+			if cronTimer != nil {
+				cronTimer.Reset(ur.untilNextCron(time.Now()))
+			} else {
+				ur.setNextSearch(time.Now().Add(ur.interval))
+			}
+
 			// Check if we're already running
 			select {
 			case ur.runningCh <- struct{}{}:
 				// We got the semaphore, run the search
+				searches.Add(1)
 				go func() {
+					defer searches.Done()
 					defer func() {
 						<-ur.runningCh
 					}()
 
+					if !ur.waitJitter(ctx, ur.tickJitter) {
+						return
+					}
 					if err := ur.runSearch(ctx, true); err != nil {
-						log.Errorf("Search failed for user '%s': %v", ur.userConfig.Name, err)
+						ur.log.Errorf("Search failed: %v", err)
 					}
 				}()
 			default:
 				// A search is already running, skip this tick
-				log.Warnf("Previous search still running for user '%s', skipping", ur.userConfig.Name)
+				ur.log.Warn("Previous search still running, skipping")
 			}
 		case <-ur.stopChan:
-			log.Infof("Stopping search runner for user '%s'", ur.userConfig.Name)
+			ur.log.Info("Stopping search runner")
 			return nil
 		case <-ctx.Done():
-			log.Infof("Context cancelled for user '%s'", ur.userConfig.Name)
+			ur.log.Info("Context cancelled")
 			return ctx.Err()
 		}
 	}
 }
 
+// untilNextCron returns how long until the cron schedule next fires after now
+func (ur *userRunner) untilNextCron(now time.Time) time.Duration {
+	next := ur.cron.Next(now)
+	ur.log.Infof("Next search at %s", next.Format(time.RFC1123))
+	ur.setNextSearch(next)
+	return next.Sub(now)
+}
+
+// nextSearch describes when the next search runs after now, for logging
+func (ur *userRunner) nextSearch(now time.Time) string {
+	if ur.cron != nil {
+		return "at " + ur.cron.Next(now).Format(time.RFC1123)
+	}
+	return "in " + ur.interval.String()
+}
+
+// newSearchSlots returns the semaphore bounding concurrent item searches to
+// max, or nil (no limit) when max is zero or negative
+func newSearchSlots(max int) chan struct{} {
+	if max <= 0 {
+		return nil
+	}
+	return make(chan struct{}, max)
+}
+
+// searchItem searches for an item once a search slot is free
+func (ur *userRunner) searchItem(ctx context.Context, item string, zipcode string, distance int) ([]search.LiquorItem, error) {
+	if ur.searchSlots != nil {
+		select {
+		case ur.searchSlots <- struct{}{}:
+			defer func() { <-ur.searchSlots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return ur.searcher.SearchItem(ctx, item, zipcode, distance)
+}
+
 // runSearch performs a single search for all items for this user
 // Collects all found items before sending notifications
 // If withHealthCheck is true, a random common item is also searched as a health check
-func (ur *userRunner) runSearch(ctx context.Context, withHealthCheck bool) error {
+func (ur *userRunner) runSearch(ctx context.Context, withHealthCheck bool) (err error) {
+	defer func() {
+		ur.recordSearchResult(err, time.Now())
+		ur.saveState()
+	}()
+
 	if len(ur.userConfig.Items) == 0 {
 		return fmt.Errorf("user '%s' has no items to search for", ur.userConfig.Name)
 	}
 
-	if ur.userConfig.Zipcode == "" {
+	if ur.userConfig.Zipcode == "" && !ur.userConfig.Statewide {
 		return fmt.Errorf("user '%s' has no zipcode configured", ur.userConfig.Name)
 	}
 
-	log.Infof("Starting search for user '%s': %d items within %d miles of %s",
-		ur.userConfig.Name, len(ur.userConfig.Items), ur.userConfig.Distance, ur.userConfig.Zipcode)
+	if ur.userConfig.Statewide {
+		ur.log.Infof("Starting search: %d items statewide", len(ur.userConfig.Items))
+	} else {
+		ur.log.Infof("Starting search: %d items within %d miles of %s",
+			len(ur.userConfig.Items), ur.userConfig.Distance, ur.userConfig.Zipcode)
+	}
+
+	if s, ok := ur.searcher.(sessionStarter); ok {
+		s.StartSession()
+	}
 
 	var allFoundItems []search.LiquorItem
+	var searched []string
+	var interrupted bool
+
+	items := ur.itemOrder()
+	for i, item := range items {
+		// With flush_on_stop, a shutdown ends the cycle early but still
+		// notifies about the items found so far
+		if ur.flushOnStop && ur.stopRequested(ctx) {
+			ur.log.Info("Shutdown in progress, skipping remaining searches")
+			interrupted = true
+			break
+		}
 
-	for _, item := range ur.userConfig.Items {
 		// Create a context with timeout for this item
 		itemCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
 		defer cancel()
 
-		log.Infof("User '%s' searching for item: %s", ur.userConfig.Name, item)
+		term := ur.searchTerm(item)
+		if term != item {
+			ur.log.Infof("Searching for item: %s (as %q)", item, term)
+		} else {
+			ur.log.Infof("Searching for item: %s", item)
+		}
 
 		// Search for the item
-		results, err := ur.searcher.SearchItem(itemCtx, item, ur.userConfig.Zipcode, ur.userConfig.Distance)
+		searchStart := time.Now()
+		results, err := ur.searchItem(itemCtx, term, ur.userConfig.Zipcode, ur.itemDistance(item))
+		ur.metrics.ObserveSearch(ur.userConfig.Name, time.Since(searchStart), err)
+		if errors.Is(err, search.ErrSiteMaintenance) {
+			// Back off for the rest of this cycle rather than concluding items are out of stock
+			ur.log.Warn("OLCC site is under maintenance, skipping remaining searches and notifications this cycle")
+			ur.lastFailure = err
+			ur.recordOutcome(ctx, true)
+			return fmt.Errorf("search for %s aborted: %w", item, err)
+		}
+		if errors.Is(err, search.ErrCircuitOpen) {
+			// The searcher logged the failures that opened it; keep the last of them as the cause
+			ur.log.Warnf("Skipping remaining searches and notifications this cycle: %v", err)
+			if ur.lastFailure == nil {
+				ur.lastFailure = err
+			}
+			ur.recordOutcome(ctx, true)
+			return fmt.Errorf("search for %s aborted: %w", item, err)
+		}
 		if err != nil {
-			log.Errorf("Failed to search for %s for user '%s': %v", item, ur.userConfig.Name, err)
+			ur.log.Errorf("Failed to search for %s: %v", item, err)
+			ur.lastFailure = err
 			continue
 		}
 
-		log.Infof("User '%s' found %d results for %s", ur.userConfig.Name, len(results), item)
+		ur.log.Infof("Found %d results for %s", len(results), item)
+		// Results belong to the item as written in the watch list, whatever term found them
+		ic, _ := ur.userConfig.Item(item)
+		for i := range results {
+			results[i].Query = item
+			results[i].Note = ic.Note
+		}
+		searched = append(searched, item)
+
+		// Stream found items right away, ahead of the end-of-cycle notifications
+		ur.streamFinds(ctx, results)
 
 		// Collect all found items
 		allFoundItems = append(allFoundItems, results...)
+		ur.logFinds(results)
+		ur.recordSearch(item, len(results) > 0, time.Now())
 
-		// Random wait between searches to avoid overwhelming the service
-		if len(ur.userConfig.Items) > 1 && item != ur.userConfig.Items[len(ur.userConfig.Items)-1] {
-			randTimeBig := new(big.Int)
-			randTimeBig.SetInt64(int64(30))
-			randTime, _ := rand.Int(rand.Reader, randTimeBig)
-			waitTime := time.Duration(randTime.Int64()) * time.Second
-			log.Debugf("User '%s' waiting %s before next search", ur.userConfig.Name, waitTime)
+		// Random wait between searches to avoid overwhelming the service.
+		// Compared by position, since the same item may be listed twice.
+		if i < len(items)-1 {
+			waitTime := ur.searchDelay()
+			ur.log.Debugf("Waiting %s before next search", waitTime)
 
 			select {
 			case <-time.After(waitTime):
 				// Continue to next item
 			case <-ctx.Done():
-				return ctx.Err()
+				if !ur.flushOnStop {
+					return ctx.Err()
+				}
 			}
 		}
 	}
 
+	// An interrupted cycle says nothing about failure streaks or finds over time
+	if !interrupted {
+		// A cycle where every item search failed counts towards a failure streak
+		ur.recordOutcome(ctx, len(searched) == 0)
+
+		if len(searched) > 0 {
+			ur.metrics.SetItemsInStock(ur.userConfig.Name, len(allFoundItems))
+			ur.recordResultCount(len(allFoundItems))
+		}
+
+		// Suggest checking the watch list after many cycles with nothing found at all
+		if len(searched) > 0 {
+			ur.recordCycle(ctx, len(allFoundItems) > 0)
+		}
+	}
+
+	// Drop stores listing an item outside its configured price range
+	allFoundItems = ur.filterByPrice(allFoundItems)
+
+	// Drop stores with fewer bottles than the threshold, then products whose
+	// combined stock across the remaining stores is below the threshold
+	allFoundItems = filterByStoreStock(allFoundItems, ur.minStoreStock())
+	allFoundItems = filterByTotalStock(allFoundItems, ur.minTotalStock())
+
+	// Only notify about items that are new in stock or cheaper, if price history is enabled
+	notifyItems := ur.newOrCheaper(allFoundItems, searched)
+
+	// Skip items already notified about that are still in stock
+	notifyItems = ur.unnotified(notifyItems, searched, time.Now())
+
 	// Send notifications for all found items (condensed or individual based on user config)
+	changed := ur.resultsChanged(allFoundItems)
 	if len(allFoundItems) > 0 {
-		if err := ur.notifier.NotifyFoundItems(ctx, allFoundItems); err != nil {
-			log.Warnf("Failed to send notifications for user '%s': %v", ur.userConfig.Name, err)
+		ur.lastFind = time.Now()
+		switch {
+		case ur.skipUnchanged && !changed:
+			ur.log.Info("Results are unchanged since the last search, skipping notifications")
+		case len(notifyItems) == 0:
+			ur.log.Info("No newly found items since the last search, skipping notifications")
+		default:
+			ur.notifyFoundItems(ctx, notifyItems)
 		}
 	}
+	if interrupted {
+		return fmt.Errorf("search interrupted by shutdown after %d of %d items", len(searched), len(items))
+	}
+
+	// Let the user know about items that have gone a long time without stock
+	ur.notifyDrySpells(ctx, time.Now())
 
-	// Send heartbeat notification with optional health check search result
+	// Send heartbeat notification with optional health check search result, when due
+	if !ur.heartbeatDue(time.Now()) {
+		ur.log.Infof("Search completed, next search %s", ur.nextSearch(time.Now()))
+		return nil
+	}
 	var healthCheckItem string
 	var healthCheckFound bool
 	if withHealthCheck {
@@ -177,30 +535,350 @@ func (ur *userRunner) runSearch(ctx context.Context, withHealthCheck bool) error
 		healthCtx, healthCancel := context.WithTimeout(ctx, 2*time.Minute)
 		defer healthCancel()
 
-		log.Infof("User '%s' running health check search for common item: %s", ur.userConfig.Name, healthCheckItem)
-		healthResults, err := ur.searcher.SearchItem(healthCtx, healthCheckItem, ur.userConfig.Zipcode, ur.userConfig.Distance)
+		ur.log.Infof("Running health check search for common item: %s", healthCheckItem)
+		healthResults, err := ur.searchItem(healthCtx, healthCheckItem, ur.userConfig.Zipcode, ur.userDistance())
 		if err != nil {
-			log.Warnf("Health check search failed for user '%s': %v", ur.userConfig.Name, err)
+			ur.log.Warnf("Health check search failed: %v", err)
 		} else {
 			healthCheckFound = len(healthResults) > 0
 			if healthCheckFound {
 				healthCheckItem = healthResults[0].Name
 			}
-			log.Infof("User '%s' health check: searched for '%s', found %d results", ur.userConfig.Name, healthCheckItem, len(healthResults))
+			ur.log.Infof("Health check: searched for '%s', found %d results", healthCheckItem, len(healthResults))
 		}
 	}
 
-	if err := ur.notifier.NotifyHeartbeat(ctx, healthCheckItem, healthCheckFound); err != nil {
-		log.Warnf("Failed to send heartbeat notification for user '%s': %v", ur.userConfig.Name, err)
-	}
+	ur.notifyHeartbeat(ctx, ur.heartbeatStats(healthCheckItem, healthCheckFound))
+	ur.lastHeartbeat = time.Now()
 
-	log.Infof("Search completed for user '%s', next search in %s", ur.userConfig.Name, ur.interval)
+	ur.log.Infof("Search completed, next search %s", ur.nextSearch(time.Now()))
 	return nil
 }
 
+// stopRequested reports whether the runner was stopped or its context cancelled
+func (ur *userRunner) stopRequested(ctx context.Context) bool {
+	select {
+	case <-ur.stopChan:
+		return true
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// notifyContext returns the context to send notifications with, and false if
+// notifications must be suppressed because a shutdown is in progress.
+// With flushOnStop, pending notifications are still sent during shutdown using
+// a context detached from cancellation but bounded by a timeout.
+func (ur *userRunner) notifyContext(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	if !ur.stopRequested(ctx) {
+		return ctx, func() {}, true
+	}
+	if !ur.flushOnStop {
+		ur.log.Info("Shutdown in progress, suppressing notifications")
+		return ctx, func() {}, false
+	}
+	ur.log.Info("Shutdown in progress, flushing pending notifications")
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	return flushCtx, cancel, true
+}
+
+// notifyFoundItems sends found item notifications unless a shutdown is in progress
+func (ur *userRunner) notifyFoundItems(ctx context.Context, items []search.LiquorItem) {
+	notifyCtx, cancel, ok := ur.notifyContext(ctx)
+	defer cancel()
+	if !ok {
+		return
+	}
+
+	if err := ur.notifier.NotifyFoundItems(notifyCtx, items); err != nil {
+		ur.log.Warnf("Failed to send notifications: %v", err)
+		if errors.Is(err, notification.ErrUndelivered) {
+			ur.handleUndelivered(items, err, time.Now())
+		}
+	}
+}
+
+// notifyHeartbeat sends a heartbeat notification unless a shutdown is in progress
+func (ur *userRunner) notifyHeartbeat(ctx context.Context, stats notification.HeartbeatStats) {
+	notifyCtx, cancel, ok := ur.notifyContext(ctx)
+	defer cancel()
+	if !ok {
+		return
+	}
+
+	if err := ur.notifier.NotifyHeartbeat(notifyCtx, stats); err != nil {
+		ur.log.Warnf("Failed to send heartbeat notification: %v", err)
+	}
+}
+
+// resultsChanged reports whether items differ from the previous cycle's results,
+// recording them for the next comparison. Search timestamps are ignored.
+func (ur *userRunner) resultsChanged(items []search.LiquorItem) bool {
+	hash := resultsHash(items)
+	changed := hash != ur.lastResults
+	ur.lastResults = hash
+	return changed
+}
+
+// resultsHash returns a digest of a result set that is independent of result
+// order and search time
+func resultsHash(items []search.LiquorItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, strings.Join([]string{item.Code, item.Name, item.Store, item.Price, strconv.Itoa(item.Quantity)}, "\x1f"))
+	}
+	sort.Strings(lines)
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// heartbeatStats collects the values rendered into this user's heartbeat message
+func (ur *userRunner) heartbeatStats(healthCheckItem string, healthCheckFound bool) notification.HeartbeatStats {
+	return notification.HeartbeatStats{
+		User:             ur.userConfig.Name,
+		Users:            ur.userCount,
+		ItemsWatched:     len(ur.userConfig.Items),
+		LastFind:         ur.lastFind,
+		Uptime:           time.Since(ur.startedAt).Round(time.Second),
+		HealthCheckItem:  healthCheckItem,
+		HealthCheckFound: healthCheckFound,
+	}
+}
+
+// heartbeatInterval returns how often the user gets a heartbeat, falling back to the global setting
+func (ur *userRunner) heartbeatInterval() time.Duration {
+	return ur.userConfig.EffectiveHeartbeatInterval(ur.heartbeatEvery)
+}
+
+// heartbeatDue reports whether a heartbeat should be sent at the end of the
+// cycle finishing at now: never without a heartbeat interval, otherwise on the
+// first cycle and then once the interval has passed since the last heartbeat
+func (ur *userRunner) heartbeatDue(now time.Time) bool {
+	interval := ur.heartbeatInterval()
+	if interval <= 0 {
+		return false
+	}
+	return ur.lastHeartbeat.IsZero() || now.Sub(ur.lastHeartbeat) >= interval
+}
+
+// minTotalStock returns the user's minimum total stock, falling back to the global setting
+func (ur *userRunner) minTotalStock() int {
+	if ur.userConfig.MinTotalStock > 0 {
+		return ur.userConfig.MinTotalStock
+	}
+	return ur.minStock
+}
+
+// minStoreStock returns the user's minimum stock per store, falling back to the global setting
+func (ur *userRunner) minStoreStock() int {
+	if ur.userConfig.MinStoreStock > 0 {
+		return ur.userConfig.MinStoreStock
+	}
+	return ur.minStoreQty
+}
+
+// filterByStoreStock keeps only items with at least minStock bottles at their
+// store. Items whose quantity couldn't be read (0) are kept, since they were
+// listed as in stock. A minStock of zero or less disables filtering.
+func filterByStoreStock(items []search.LiquorItem, minStock int) []search.LiquorItem {
+	if minStock <= 0 {
+		return items
+	}
+
+	var filtered []search.LiquorItem
+	for _, item := range items {
+		if item.Quantity == 0 || item.Quantity >= minStock {
+			filtered = append(filtered, item)
+		} else {
+			logger.Debugf("Suppressing %s at %s: %d in stock is below minimum of %d", item.Name, item.Store, item.Quantity, minStock)
+		}
+	}
+	return filtered
+}
+
+// filterByTotalStock keeps only items whose product has at least minStock bottles
+// summed across all stores. Products are identified by code, or by name when the
+// code is unknown. A product with a store whose quantity couldn't be read (0)
+// has an unknown total and is kept, as in filterByStoreStock. A minStock of zero
+// or less disables filtering.
+func filterByTotalStock(items []search.LiquorItem, minStock int) []search.LiquorItem {
+	if minStock <= 0 {
+		return items
+	}
+
+	productKey := func(item search.LiquorItem) string {
+		if item.Code != "" {
+			return item.Code
+		}
+		return item.Name
+	}
+
+	totals := make(map[string]int)
+	unknown := make(map[string]bool)
+	for _, item := range items {
+		totals[productKey(item)] += item.Quantity
+		if item.Quantity == 0 {
+			unknown[productKey(item)] = true
+		}
+	}
+
+	var filtered []search.LiquorItem
+	for _, item := range items {
+		if total := totals[productKey(item)]; unknown[productKey(item)] || total >= minStock {
+			filtered = append(filtered, item)
+		} else {
+			logger.Debugf("Suppressing %s at %s: %d total in stock is below minimum of %d", item.Name, item.Store, total, minStock)
+		}
+	}
+	return filtered
+}
+
+// filterByPrice drops items listed at a price outside the price limits of the
+// watched item that found them. Items without a readable price are kept, since
+// they can't be ruled out.
+func (ur *userRunner) filterByPrice(items []search.LiquorItem) []search.LiquorItem {
+	var filtered []search.LiquorItem
+	for _, item := range items {
+		limit, ok := ur.priceLimit(item.Query)
+		if !ok {
+			filtered = append(filtered, item)
+			continue
+		}
+		cents, err := history.ParseCents(item.Price)
+		if err != nil {
+			ur.log.Debugf("Keeping %s at %s: price %q can't be checked against its price limits", item.Name, item.Store, item.Price)
+			filtered = append(filtered, item)
+			continue
+		}
+		if withinPriceLimit(cents, limit) {
+			filtered = append(filtered, item)
+		} else {
+			ur.log.Debugf("Suppressing %s at %s: price %s is outside its price limits", item.Name, item.Store, item.Price)
+		}
+	}
+	return filtered
+}
+
+// priceLimit returns the price limits configured for a watched item. Its
+// max_price applies when price_limits sets no max for it.
+func (ur *userRunner) priceLimit(item string) (config.PriceLimit, bool) {
+	var limit config.PriceLimit
+	found := false
+	for name, l := range ur.userConfig.PriceLimits {
+		if strings.EqualFold(name, item) {
+			limit, found = l, true
+			break
+		}
+	}
+	if ic, ok := ur.userConfig.Item(item); ok && ic.MaxPrice > 0 && limit.Max == 0 {
+		limit.Max, found = ic.MaxPrice, true
+	}
+	return limit, found
+}
+
+// withinPriceLimit reports whether a price in cents is within limit
+func withinPriceLimit(cents int64, limit config.PriceLimit) bool {
+	if limit.Min > 0 && cents < int64(math.Round(limit.Min*100)) {
+		return false
+	}
+	if limit.Max > 0 && cents > int64(math.Round(limit.Max*100)) {
+		return false
+	}
+	return true
+}
+
+// searchTerm returns the query to send to OLCC for item: its search_terms
+// override if configured, or the item itself
+func (ur *userRunner) searchTerm(item string) string {
+	for name, term := range ur.userConfig.SearchTerms {
+		if strings.EqualFold(name, item) {
+			return strings.TrimSpace(term)
+		}
+	}
+	return item
+}
+
+// itemOrder returns the user's items in the order they should be searched this cycle
+func (ur *userRunner) itemOrder() []string {
+	if ur.shuffle {
+		return shuffleItems(ur.userConfig.ItemNames())
+	}
+	return ur.userConfig.ItemNames()
+}
+
+// userDistance returns the user's search radius, or search.Statewide if the
+// user searches every store
+func (ur *userRunner) userDistance() int {
+	if ur.userConfig.Statewide {
+		return search.Statewide
+	}
+	return ur.userConfig.Distance
+}
+
+// itemDistance returns the search radius for item: its own distance if set,
+// otherwise the user's
+func (ur *userRunner) itemDistance(item string) int {
+	if ic, ok := ur.userConfig.Item(item); ok && ic.Distance > 0 {
+		return ic.Distance
+	}
+	return ur.userDistance()
+}
+
+// shuffleItems returns a copy of items in random order so that no item is
+// systematically searched last
+func shuffleItems(items []string) []string {
+	shuffled := make([]string, len(items))
+	copy(shuffled, items)
+
+	// Fisher-Yates shuffle using crypto/rand
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return shuffled
+		}
+		shuffled[i], shuffled[j.Int64()] = shuffled[j.Int64()], shuffled[i]
+	}
+
+	return shuffled
+}
+
 // stop halts the user runner (internal method)
 func (ur *userRunner) stop() {
-	close(ur.stopChan)
+	ur.stopOnce.Do(func() { close(ur.stopChan) })
+}
+
+// wait blocks until the runner started by launch has finished, including any
+// search it was running
+func (ur *userRunner) wait() {
+	if ur.done != nil {
+		<-ur.done
+	}
+}
+
+// takeOver gives ur the per-user log file and price history of old, which
+// must have finished, and restores the state old saved, so no two runners of
+// a user use them at once
+func (ur *userRunner) takeOver(old *userRunner) {
+	ur.findLog, ur.logFile, ur.prices = old.findLog, old.logFile, old.prices
+	old.findLog, old.logFile, old.prices = nil, nil, nil
+	ur.restoreState()
+}
+
+// close releases the files the user runner holds open, once it is stopped
+func (ur *userRunner) close() {
+	if ur.logFile == nil {
+		return
+	}
+	if err := ur.logFile.Close(); err != nil {
+		ur.log.Warnf("Failed to close log file: %v", err)
+	}
 }
 
 // runOnce performs a single search and returns for this user (internal method)
@@ -208,23 +886,79 @@ func (ur *userRunner) runOnce(ctx context.Context) error {
 	return ur.runSearch(ctx, false)
 }
 
+// userLocation returns the time zone for a user's notification timestamps: the
+// user's own timezone, else the global one, else local time
+func userLocation(cfg config.Config, userConfig config.UserConfig) (*time.Location, error) {
+	name := userConfig.Timezone
+	if name == "" {
+		name = cfg.Timezone
+	}
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone for user '%s': %w", userConfig.Name, err)
+	}
+	return loc, nil
+}
+
+// MessageOptions returns the notification manager options that shape the
+// messages a user is sent, so that previews render them as the runner sends them
+func MessageOptions(cfg config.Config, userConfig config.UserConfig) ([]notification.ManagerOption, error) {
+	loc, err := userLocation(cfg, userConfig)
+	if err != nil {
+		return nil, err
+	}
+	return []notification.ManagerOption{
+		notification.WithHeartbeatTemplate(cfg.HeartbeatTemplate),
+		notification.WithMissingPrice(cfg.MissingPrice),
+		notification.WithItemDetails(cfg.ShowItemDetails),
+		notification.WithProductNameCase(cfg.ProductNameCase),
+		notification.WithLocation(loc),
+		notification.WithItemAlerts(userConfig.ItemAlerts),
+		notification.WithStoreGrouping(cfg.CondenseGroupStores, cfg.CondenseListStores),
+	}, nil
+}
+
 // SearchRunner manages search execution for one or more users
 type SearchRunner struct {
 	config      config.Config
 	userRunners map[string]*userRunner
 	stopChan    chan struct{}
+	stopOnce    sync.Once
 	mu          sync.RWMutex
+	// changeMu serializes changes to the users, so a reload replaces the
+	// runners it built replacements for
+	changeMu sync.Mutex
+	// metrics is served on config.MetricsAddr while running continuously (nil = disabled)
+	metrics *metrics.Metrics
+	// running is true while Start is running
+	running bool
+	// connLimiter, searchSlots, cache and commonItems are shared by every user runner
+	connLimiter *search.ConnectionLimiter
+	searchSlots chan struct{}
+	cache       *search.ResultCache
+	commonItems []string
+	// runCtx is the context user runners run with while Start is running, so
+	// users added at runtime are started too (nil when not running)
+	runCtx context.Context
+	// wg tracks the user runner goroutines started by Start
+	wg sync.WaitGroup
+	// state persists user runner state across restarts (nil = disabled)
+	state StateStore
+	// newSearcher returns the Searcher for a user (nil = scrape OLCC)
+	newSearcher func(user config.UserConfig) Searcher
 }
 
 // NewRunner creates a new runner with the given configuration
 // Supports both single-user and multi-user configurations
-func NewRunner(cfg config.Config) (Runner, error) {
+func NewRunner(cfg config.Config, opts ...RunnerOption) (Runner, error) {
 	if len(cfg.Users) == 0 {
 		return nil, fmt.Errorf("no users configured")
 	}
 
-	userRunners := make(map[string]*userRunner)
-
 	// Extract common item search strings from config (use code if set, otherwise name)
 	var commonItemSearches []string
 	for _, ci := range cfg.CommonItems {
@@ -235,20 +969,162 @@ func NewRunner(cfg config.Config) (Runner, error) {
 		}
 	}
 
+	sr := &SearchRunner{
+		config:      cfg,
+		userRunners: make(map[string]*userRunner),
+		stopChan:    make(chan struct{}),
+		// Connection limiter shared by every user's searcher
+		connLimiter: search.NewConnectionLimiter(cfg.MaxConnections),
+		// Search slots shared by every user runner
+		searchSlots: newSearchSlots(cfg.MaxConcurrentSearches),
+		// Search result cache shared by every user's searcher
+		cache:       search.NewResultCache(cfg.SearchCacheTTL),
+		commonItems: commonItemSearches,
+	}
+	for _, opt := range opts {
+		opt(sr)
+	}
+	if cfg.MetricsAddr != "" {
+		sr.metrics = metrics.New()
+	}
+	if path := statePath(cfg); path != "" {
+		sr.state = NewFileStateStore(path)
+	}
+	if sr.usesUserFiles() {
+		if err := checkFileNames(cfg.Users); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create userRunner for each user
 	for _, userConfig := range cfg.Users {
-		userRunner, err := newUserRunner(userConfig, cfg.Interval, cfg.UserAgent, commonItemSearches)
+		userRunner, err := sr.buildUserRunner(userConfig, len(cfg.Users))
 		if err != nil {
-			return nil, fmt.Errorf("failed to create user runner for '%s': %w", userConfig.Name, err)
+			sr.closeUsers()
+			return nil, err
 		}
-		userRunners[userConfig.Name] = userRunner
+		sr.userRunners[userConfig.Name] = userRunner
 	}
 
-	return &SearchRunner{
-		config:      cfg,
-		userRunners: userRunners,
-		stopChan:    make(chan struct{}),
-	}, nil
+	return sr, nil
+}
+
+// usesUserFiles reports whether users have files named after them: a log
+// file, or a price history
+func (sr *SearchRunner) usesUserFiles() bool {
+	return sr.config.PerUserLogs || sr.config.StateDir != ""
+}
+
+// closeUsers closes the files held by every user runner
+func (sr *SearchRunner) closeUsers() {
+	for _, ur := range sr.userRunners {
+		ur.close()
+	}
+}
+
+// buildUserRunner creates the runner for one user from the user's config and
+// the runner's global settings. userCount is the number of configured users.
+func (sr *SearchRunner) buildUserRunner(userConfig config.UserConfig, userCount int) (*userRunner, error) {
+	userRunner, err := sr.buildReplacement(userConfig, userCount)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := sr.config
+	if cfg.PerUserLogs {
+		findLog, logFile, err := newUserLogger(cfg.PerUserLogDir, userConfig.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create log file for user '%s': %w", userConfig.Name, err)
+		}
+		userRunner.findLog = findLog
+		userRunner.logFile = logFile
+	}
+	if cfg.StateDir != "" {
+		prices, err := openPriceHistory(cfg.StateDir, userConfig.Name, cfg.DedupKeyFields)
+		if err != nil {
+			userRunner.close()
+			return nil, fmt.Errorf("failed to load price history for user '%s': %w", userConfig.Name, err)
+		}
+		userRunner.prices = prices
+	}
+	userRunner.restoreState()
+	return userRunner, nil
+}
+
+// buildReplacement creates the runner for a user like buildUserRunner, but
+// without opening the user's log file and price history or restoring their
+// state: a replacement takes those over from the user's current runner once
+// it has finished, with takeOver.
+func (sr *SearchRunner) buildReplacement(userConfig config.UserConfig, userCount int) (*userRunner, error) {
+	cfg := sr.config
+	notifyOpts, err := MessageOptions(cfg, userConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	searchOpts := []search.SearcherOption{
+		search.WithMaintenanceMarkers(cfg.MaintenanceMarkers),
+		search.WithConnectionLimiter(sr.connLimiter),
+		search.WithResultCache(sr.cache),
+		search.WithHTTPLogging(cfg.LogHTTP),
+		search.WithUserAgentRotation(cfg.UserAgentRotation),
+		search.WithHeaderRotation(cfg.RotateHeaders),
+		search.WithAmbiguousResults(cfg.AmbiguousResults),
+		search.WithMaxListProducts(cfg.MaxListProducts),
+		search.WithItemCodeForm(cfg.ItemCodeForm),
+		search.WithProxy(userConfig.EffectiveProxyURL(cfg.ProxyURL)),
+		search.WithCircuitBreaker(cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+	}
+	retryDelay := cfg.NotifyRetryDelay
+	if retryDelay == 0 {
+		retryDelay = notifyRetryDelay
+	}
+	notifyOpts = append(notifyOpts,
+		notification.WithDryRun(cfg.DryRun),
+		notification.WithAllowedTypes(cfg.AllowedNotificationTypes),
+		notification.WithRetry(cfg.NotifyRetries, retryDelay),
+		notification.WithCondensedFallback(cfg.CondensedFallback),
+	)
+
+	var searcher Searcher
+	if sr.newSearcher != nil {
+		searcher = sr.newSearcher(userConfig)
+	}
+	userRunner, err := newUserRunner(userConfig, searcher, userConfig.EffectiveInterval(cfg.Interval), cfg.UserAgent, sr.commonItems, searchOpts, notifyOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user runner for '%s': %w", userConfig.Name, err)
+	}
+	userRunner.startupJitter = cfg.StartupJitter
+	userRunner.searchDelay = randomDelayBetween(userConfig.EffectiveItemDelay(cfg.MinItemDelay, cfg.MaxItemDelay))
+	userRunner.tickJitter = cfg.TickJitter
+	if userConfig.Cron != "" {
+		cron, err := schedule.Parse(userConfig.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron for user '%s': %w", userConfig.Name, err)
+		}
+		userRunner.cron = cron
+	}
+	userRunner.streamer = newItemStreamer(cfg.StreamWebhook)
+	userRunner.metrics = sr.metrics
+	userRunner.newOnly = cfg.NotifyNewOnly
+	userRunner.renotifyAfter = cfg.RenotifyAfter
+	userRunner.onNotifyFailure = cfg.OnNotifyFailure
+	userRunner.deadLetterFile = deadLetterPath(cfg)
+	userRunner.shuffle = cfg.ShuffleItems
+	userRunner.minStock = cfg.MinTotalStock
+	userRunner.minStoreQty = cfg.MinStoreStock
+	userRunner.heartbeatEvery = cfg.HeartbeatInterval
+	userRunner.flushOnStop = cfg.FlushOnStop
+	userRunner.drySpell = cfg.DrySpellAlert
+	userRunner.zeroAlert = cfg.ZeroFindAlert
+	userRunner.recoveryAlert = cfg.RecoveryAlert
+	userRunner.failureAlert = cfg.FailureAlert
+	userRunner.failureAlertCooldown = cfg.FailureAlertCooldown
+	userRunner.skipUnchanged = cfg.SkipUnchangedCycles
+	userRunner.userCount = userCount
+	userRunner.state = sr.state
+	userRunner.searchSlots = sr.searchSlots
+	return userRunner, nil
 }
 
 // Start begins concurrent searches for all users
@@ -257,70 +1133,131 @@ func (sr *SearchRunner) Start(ctx context.Context) error {
 	userCount := len(sr.userRunners)
 	sr.mu.RUnlock()
 
-	log.Infof("Starting search runner with %d users", userCount)
+	logger.Infof("Starting search runner with %d users", userCount)
+
+	if sr.metrics != nil {
+		stopMetrics, err := serveMetrics(sr.config.MetricsAddr, sr.metrics)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+	}
+
+	if sr.config.HealthAddr != "" {
+		stopHealth, err := serveHealth(sr.config.HealthAddr, sr)
+		if err != nil {
+			return err
+		}
+		defer stopHealth()
+	}
+
+	if sr.config.StatusAddr != "" {
+		stopStatus, err := serveStatus(sr.config.StatusAddr, sr)
+		if err != nil {
+			return err
+		}
+		defer stopStatus()
+	}
+
+	sr.setRunning(true)
+	defer sr.setRunning(false)
 
 	// Create a context that can be cancelled
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Channel to collect errors from user runners
-	errChan := make(chan error, userCount)
-
 	// Start each user runner in its own goroutine
-	sr.mu.RLock()
+	sr.mu.Lock()
+	sr.runCtx = ctx
 	for userName, ur := range sr.userRunners {
-		go func(name string, runner *userRunner) {
-			log.Infof("Starting user runner for '%s'", name)
-			if err := runner.start(ctx); err != nil {
-				log.Errorf("User runner for '%s' failed: %v", name, err)
-				errChan <- fmt.Errorf("user '%s': %w", name, err)
-			} else {
-				log.Infof("User runner for '%s' completed", name)
-				errChan <- nil
-			}
-		}(userName, ur)
+		sr.launch(userName, ur)
 	}
-	sr.mu.RUnlock()
+	sr.mu.Unlock()
 
 	// Wait for stop signal or context cancellation
 	select {
 	case <-sr.stopChan:
-		log.Info("SearchRunner received stop signal")
+		logger.Info("SearchRunner received stop signal")
 		cancel() // Cancel context to stop all user runners
 	case <-ctx.Done():
-		log.Info("SearchRunner context cancelled")
+		logger.Info("SearchRunner context cancelled")
 	}
 
 	// Stop all user runners
-	sr.mu.RLock()
+	sr.mu.Lock()
+	sr.runCtx = nil
 	for userName, ur := range sr.userRunners {
-		log.Infof("Stopping user runner for '%s'", userName)
+		logger.Infof("Stopping user runner for '%s'", userName)
 		ur.stop()
 	}
-	sr.mu.RUnlock()
+	sr.mu.Unlock()
 
 	// Wait for all user runners to complete (with timeout)
-	completedUsers := 0
-	for completedUsers < userCount {
-		select {
-		case err := <-errChan:
-			if err != nil {
-				log.Errorf("User runner error: %v", err)
-			}
-			completedUsers++
-		case <-time.After(30 * time.Second):
-			log.Warn("Timeout waiting for user runners to complete")
-			return fmt.Errorf("timeout waiting for user runners to complete")
-		}
+	waitCtx, cancelWait := context.WithTimeout(context.WithoutCancel(ctx), sr.config.EffectiveShutdownTimeout())
+	defer cancelWait()
+	if err := sr.wait(waitCtx); err != nil {
+		logger.Warn("Timeout waiting for user runners to complete")
+		return err
 	}
 
-	log.Info("All user runners stopped")
+	sr.mu.Lock()
+	sr.closeUsers()
+	sr.mu.Unlock()
+
+	logger.Info("All user runners stopped")
 	return nil
 }
 
-// Stop halts all user runners
+// wait blocks until every user runner started by Start has finished, or ctx is done
+func (sr *SearchRunner) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		sr.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timeout waiting for user runners to complete: %w", ctx.Err())
+	}
+}
+
+// launch starts ur in its own goroutine with the context of the running Start.
+// The caller must hold sr.mu for writing.
+func (sr *SearchRunner) launch(name string, ur *userRunner) {
+	ctx := sr.runCtx
+	ur.done = make(chan struct{})
+	sr.wg.Add(1)
+	go func() {
+		defer sr.wg.Done()
+		defer close(ur.done)
+		logger.Infof("Starting user runner for '%s'", name)
+		if err := ur.start(ctx); err != nil {
+			logger.Errorf("User runner for '%s' failed: %v", name, err)
+		} else {
+			logger.Infof("User runner for '%s' completed", name)
+		}
+	}()
+}
+
+// setRunning records whether Start is running
+func (sr *SearchRunner) setRunning(running bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.running = running
+}
+
+// Stop halts all user runners. It is safe to call more than once.
 func (sr *SearchRunner) Stop() {
-	close(sr.stopChan)
+	sr.stopOnce.Do(func() { close(sr.stopChan) })
+}
+
+// StopWithContext halts all user runners and waits until they, and any search
+// they were running, have finished, returning an error if ctx is done first
+func (sr *SearchRunner) StopWithContext(ctx context.Context) error {
+	sr.Stop()
+	return sr.wait(ctx)
 }
 
 // RunOnce performs a single search for all users and returns
@@ -329,7 +1266,7 @@ func (sr *SearchRunner) RunOnce(ctx context.Context) error {
 	userCount := len(sr.userRunners)
 	sr.mu.RUnlock()
 
-	log.Infof("Running single search for %d users", userCount)
+	logger.Infof("Running single search for %d users", userCount)
 
 	// Channel to collect errors from user runners
 	errChan := make(chan error, userCount)
@@ -338,12 +1275,12 @@ func (sr *SearchRunner) RunOnce(ctx context.Context) error {
 	sr.mu.RLock()
 	for userName, ur := range sr.userRunners {
 		go func(name string, runner *userRunner) {
-			log.Infof("Running single search for user '%s'", name)
+			logger.Infof("Running single search for user '%s'", name)
 			if err := runner.runOnce(ctx); err != nil {
-				log.Errorf("Single search failed for user '%s': %v", name, err)
+				logger.Errorf("Single search failed for user '%s': %v", name, err)
 				errChan <- fmt.Errorf("user '%s': %w", name, err)
 			} else {
-				log.Infof("Single search completed for user '%s'", name)
+				logger.Infof("Single search completed for user '%s'", name)
 				errChan <- nil
 			}
 		}(userName, ur)
@@ -357,7 +1294,7 @@ func (sr *SearchRunner) RunOnce(ctx context.Context) error {
 		select {
 		case err := <-errChan:
 			if err != nil {
-				log.Errorf("User search error: %v", err)
+				logger.Errorf("User search error: %v", err)
 				lastErr = err
 			}
 			completedUsers++
@@ -366,7 +1303,7 @@ func (sr *SearchRunner) RunOnce(ctx context.Context) error {
 		}
 	}
 
-	log.Info("All user searches completed")
+	logger.Info("All user searches completed")
 	return lastErr
 }
 
@@ -1,10 +1,24 @@
 package runner
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+	"github.com/toozej/go-find-liquor/internal/schedule"
+	"github.com/toozej/go-find-liquor/internal/search"
 	"github.com/toozej/go-find-liquor/pkg/config"
 )
 
@@ -23,7 +37,7 @@ func TestRunner_NewRunner(t *testing.T) {
 				Users: []config.UserConfig{
 					{
 						Name:     "user1",
-						Items:    []string{"item1", "item2"},
+						Items:    config.NewItems("item1", "item2"),
 						Zipcode:  "97201",
 						Distance: 10,
 						Notifications: []config.NotificationConfig{
@@ -39,7 +53,7 @@ func TestRunner_NewRunner(t *testing.T) {
 					},
 					{
 						Name:     "user2",
-						Items:    []string{"item3"},
+						Items:    config.NewItems("item3"),
 						Zipcode:  "97210",
 						Distance: 15,
 						Notifications: []config.NotificationConfig{
@@ -65,7 +79,7 @@ func TestRunner_NewRunner(t *testing.T) {
 				Users: []config.UserConfig{
 					{
 						Name:     "user1",
-						Items:    []string{"item1"},
+						Items:    config.NewItems("item1"),
 						Zipcode:  "97201",
 						Distance: 10,
 						Notifications: []config.NotificationConfig{
@@ -100,7 +114,7 @@ func TestRunner_NewRunner(t *testing.T) {
 				Users: []config.UserConfig{
 					{
 						Name:     "user1",
-						Items:    []string{"item1"},
+						Items:    config.NewItems("item1"),
 						Zipcode:  "97201",
 						Distance: 10,
 						Notifications: []config.NotificationConfig{
@@ -155,7 +169,7 @@ func TestRunner_RunOnce(t *testing.T) {
 		Users: []config.UserConfig{
 			{
 				Name:     "user1",
-				Items:    []string{"test-item-1"},
+				Items:    config.NewItems("test-item-1"),
 				Zipcode:  "97201",
 				Distance: 10,
 				Notifications: []config.NotificationConfig{
@@ -171,7 +185,7 @@ func TestRunner_RunOnce(t *testing.T) {
 			},
 			{
 				Name:     "user2",
-				Items:    []string{"test-item-2"},
+				Items:    config.NewItems("test-item-2"),
 				Zipcode:  "97210",
 				Distance: 15,
 				Notifications: []config.NotificationConfig{
@@ -215,7 +229,7 @@ func TestRunner_ConcurrentExecution(t *testing.T) {
 		Users: []config.UserConfig{
 			{
 				Name:     "user1",
-				Items:    []string{"test-item-1"},
+				Items:    config.NewItems("test-item-1"),
 				Zipcode:  "97201",
 				Distance: 10,
 				Notifications: []config.NotificationConfig{
@@ -231,7 +245,7 @@ func TestRunner_ConcurrentExecution(t *testing.T) {
 			},
 			{
 				Name:     "user2",
-				Items:    []string{"test-item-2"},
+				Items:    config.NewItems("test-item-2"),
 				Zipcode:  "97210",
 				Distance: 15,
 				Notifications: []config.NotificationConfig{
@@ -288,7 +302,7 @@ func TestRunner_UserIsolation(t *testing.T) {
 		Users: []config.UserConfig{
 			{
 				Name:     "user1",
-				Items:    []string{"item1", "item2"},
+				Items:    config.NewItems("item1", "item2"),
 				Zipcode:  "97201",
 				Distance: 10,
 				Notifications: []config.NotificationConfig{
@@ -304,7 +318,7 @@ func TestRunner_UserIsolation(t *testing.T) {
 			},
 			{
 				Name:     "user2",
-				Items:    []string{"item3", "item4"},
+				Items:    config.NewItems("item3", "item4"),
 				Zipcode:  "97210",
 				Distance: 20,
 				Notifications: []config.NotificationConfig{
@@ -350,7 +364,7 @@ func TestRunner_ProperCleanup(t *testing.T) {
 		Users: []config.UserConfig{
 			{
 				Name:     "user1",
-				Items:    []string{"test-item"},
+				Items:    config.NewItems("test-item"),
 				Zipcode:  "97201",
 				Distance: 10,
 				Notifications: []config.NotificationConfig{
@@ -407,7 +421,7 @@ func TestRunner_SingleUser(t *testing.T) {
 		Users: []config.UserConfig{
 			{
 				Name:     "single-user",
-				Items:    []string{"test-item"},
+				Items:    config.NewItems("test-item"),
 				Zipcode:  "97201",
 				Distance: 10,
 				Notifications: []config.NotificationConfig{
@@ -448,3 +462,1022 @@ func TestRunner_SingleUser(t *testing.T) {
 		t.Logf("RunOnce failed as expected (network calls): %v", err)
 	}
 }
+
+// TestShuffleItems tests that shuffled order varies across cycles while keeping every item
+func TestShuffleItems(t *testing.T) {
+	items := []string{"item1", "item2", "item3", "item4", "item5"}
+	orders := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		shuffled := shuffleItems(items)
+		if len(shuffled) != len(items) {
+			t.Fatalf("Expected %d items, got %d", len(items), len(shuffled))
+		}
+
+		seen := make(map[string]int)
+		for _, item := range shuffled {
+			seen[item]++
+		}
+		for _, item := range items {
+			if seen[item] != 1 {
+				t.Fatalf("Expected %q exactly once in shuffled order %v", item, shuffled)
+			}
+		}
+
+		orders[strings.Join(shuffled, ",")] = true
+	}
+
+	if len(orders) < 2 {
+		t.Errorf("Expected item order to vary across cycles, got %d distinct order(s)", len(orders))
+	}
+
+	if strings.Join(items, ",") != "item1,item2,item3,item4,item5" {
+		t.Errorf("shuffleItems modified the input slice: %v", items)
+	}
+}
+
+// TestRunner_ItemOrder tests that items are only shuffled when ShuffleItems is enabled
+func TestRunner_ItemOrder(t *testing.T) {
+	items := []string{"item1", "item2", "item3", "item4", "item5"}
+	ur := &userRunner{userConfig: config.UserConfig{Name: "user1", Items: config.NewItems(items...)}}
+
+	for i := 0; i < 10; i++ {
+		if got := strings.Join(ur.itemOrder(), ","); got != strings.Join(items, ",") {
+			t.Fatalf("Expected config order when shuffling is disabled, got %s", got)
+		}
+	}
+
+	ur.shuffle = true
+	orders := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		orders[strings.Join(ur.itemOrder(), ",")] = true
+	}
+	if len(orders) < 2 {
+		t.Errorf("Expected shuffled item order to vary across cycles, got %d distinct order(s)", len(orders))
+	}
+}
+
+// TestFilterByTotalStock tests that items are only kept when their total stock meets the threshold
+func TestFilterByTotalStock(t *testing.T) {
+	items := []search.LiquorItem{
+		{Name: "BLANTON'S", Code: "0171B", Store: "Store A", Quantity: 1},
+		{Name: "WELLER 12", Code: "0223B", Store: "Store A", Quantity: 2},
+		{Name: "WELLER 12", Code: "0223B", Store: "Store B", Quantity: 1},
+		{Name: "EAGLE RARE", Code: "0188B", Store: "Store C", Quantity: 6},
+		{Name: "STAGG", Code: "0227B", Store: "Store A", Quantity: 1},
+		{Name: "STAGG", Code: "0227B", Store: "Store D", Quantity: 0},
+	}
+
+	tests := []struct {
+		name      string
+		minStock  int
+		wantCodes []string
+	}{
+		{"disabled", 0, []string{"0171B", "0223B", "0223B", "0188B", "0227B", "0227B"}},
+		{"below threshold suppressed", 3, []string{"0223B", "0223B", "0188B", "0227B", "0227B"}},
+		{"at threshold alerts", 6, []string{"0188B", "0227B", "0227B"}},
+		{"unknown quantity kept", 7, []string{"0227B", "0227B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterByTotalStock(items, tt.minStock)
+			var codes []string
+			for _, item := range filtered {
+				codes = append(codes, item.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.wantCodes, ",") {
+				t.Errorf("Expected codes %v, got %v", tt.wantCodes, codes)
+			}
+		})
+	}
+}
+
+// TestFilterByStoreStock tests that stores are only kept when their stock meets the threshold
+func TestFilterByStoreStock(t *testing.T) {
+	items := []search.LiquorItem{
+		{Name: "WELLER 12", Code: "0223B", Store: "Store A", Quantity: 1},
+		{Name: "WELLER 12", Code: "0223B", Store: "Store B", Quantity: 4},
+		{Name: "WELLER 12", Code: "0223B", Store: "Store C", Quantity: 0},
+	}
+
+	tests := []struct {
+		name       string
+		minStock   int
+		wantStores []string
+	}{
+		{"disabled", 0, []string{"Store A", "Store B", "Store C"}},
+		{"below threshold suppressed", 2, []string{"Store B", "Store C"}},
+		{"at threshold alerts", 4, []string{"Store B", "Store C"}},
+		{"unknown quantity kept", 5, []string{"Store C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stores []string
+			for _, item := range filterByStoreStock(items, tt.minStock) {
+				stores = append(stores, item.Store)
+			}
+			if strings.Join(stores, ",") != strings.Join(tt.wantStores, ",") {
+				t.Errorf("Expected stores %v, got %v", tt.wantStores, stores)
+			}
+		})
+	}
+}
+
+// TestRunner_FilterByPrice tests that items listed outside their price limits are dropped
+func TestRunner_FilterByPrice(t *testing.T) {
+	items := []search.LiquorItem{
+		{Name: "WELLER 12", Store: "Store A", Price: "$44.99", Query: "Weller 12"},
+		{Name: "WELLER 12", Store: "Store B", Price: "$129.99", Query: "Weller 12"},
+		{Name: "WELLER 12", Store: "Store C", Price: "$19.99", Query: "Weller 12"},
+		{Name: "WELLER 12", Store: "Store D", Price: "$49.99", Query: "Weller 12"},
+		{Name: "WELLER 12", Store: "Store E", Price: "", Query: "Weller 12"},
+		{Name: "PAPPY VAN WINKLE 23", Store: "Store F", Price: "$1,299.99", Query: "Pappy 23"},
+		{Name: "BLANTON'S", Store: "Store G", Price: "$299.99", Query: "Blanton's"},
+	}
+
+	tests := []struct {
+		name       string
+		limits     map[string]config.PriceLimit
+		items      []config.ItemConfig
+		wantStores string
+	}{
+		{name: "no limits", wantStores: "Store A,Store B,Store C,Store D,Store E,Store F,Store G"},
+		{
+			name:       "max only",
+			limits:     map[string]config.PriceLimit{"weller 12": {Max: 49.99}},
+			wantStores: "Store A,Store C,Store D,Store E,Store F,Store G",
+		},
+		{
+			name:       "min and max",
+			limits:     map[string]config.PriceLimit{"Weller 12": {Min: 30, Max: 49.99}},
+			wantStores: "Store A,Store D,Store E,Store F,Store G",
+		},
+		{
+			name:       "min only with commas in price",
+			limits:     map[string]config.PriceLimit{"Pappy 23": {Min: 1500}},
+			wantStores: "Store A,Store B,Store C,Store D,Store E,Store G",
+		},
+		{
+			name:       "item max_price",
+			items:      []config.ItemConfig{{Name: "Weller 12", MaxPrice: 49.99}},
+			wantStores: "Store A,Store C,Store D,Store E,Store F,Store G",
+		},
+		{
+			name:       "item max_price with price_limits min",
+			limits:     map[string]config.PriceLimit{"Weller 12": {Min: 30}},
+			items:      []config.ItemConfig{{Name: "Weller 12", MaxPrice: 49.99}},
+			wantStores: "Store A,Store D,Store E,Store F,Store G",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := &userRunner{userConfig: config.UserConfig{Name: "user1", Items: tt.items, PriceLimits: tt.limits}, log: logger}
+			var stores []string
+			for _, item := range ur.filterByPrice(items) {
+				stores = append(stores, item.Store)
+			}
+			if got := strings.Join(stores, ","); got != tt.wantStores {
+				t.Errorf("Expected stores %s, got %s", tt.wantStores, got)
+			}
+		})
+	}
+}
+
+// TestRunner_ItemDistance tests that an item's own distance overrides the user's
+func TestRunner_ItemDistance(t *testing.T) {
+	ur := &userRunner{userConfig: config.UserConfig{
+		Name:     "user1",
+		Distance: 10,
+		Items:    []config.ItemConfig{{Name: "Blanton's", Distance: 50}, {Name: "Weller 12"}},
+	}}
+
+	for item, want := range map[string]int{"Blanton's": 50, "blanton's": 50, "Weller 12": 10, "Unknown": 10} {
+		if got := ur.itemDistance(item); got != want {
+			t.Errorf("Expected distance %d for %s, got %d", want, item, got)
+		}
+	}
+}
+
+// TestRunner_MinTotalStockOverride tests that a per-user threshold overrides the global one
+func TestRunner_MinTotalStockOverride(t *testing.T) {
+	ur := &userRunner{userConfig: config.UserConfig{Name: "user1"}, minStock: 2}
+	if got := ur.minTotalStock(); got != 2 {
+		t.Errorf("Expected global minimum 2, got %d", got)
+	}
+
+	ur.userConfig.MinTotalStock = 5
+	if got := ur.minTotalStock(); got != 5 {
+		t.Errorf("Expected per-user minimum 5, got %d", got)
+	}
+}
+
+func TestRunner_PerUserInterval(t *testing.T) {
+	cfg := config.Config{
+		Interval:  12 * time.Hour,
+		UserAgent: "test-agent",
+		Users: []config.UserConfig{
+			{Name: "hourly", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10, Interval: time.Hour},
+			{Name: "default", Items: config.NewItems("Weller"), Zipcode: "97210", Distance: 15},
+		},
+	}
+
+	r, err := NewRunner(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	sr := r.(*SearchRunner)
+
+	if got := sr.userRunners["hourly"].interval; got != time.Hour {
+		t.Errorf("Expected per-user interval 1h, got %s", got)
+	}
+	if got := sr.userRunners["default"].interval; got != 12*time.Hour {
+		t.Errorf("Expected global interval 12h, got %s", got)
+	}
+}
+
+func TestRunner_PerUserCron(t *testing.T) {
+	cfg := config.Config{
+		Interval:  12 * time.Hour,
+		UserAgent: "test-agent",
+		Users: []config.UserConfig{
+			{Name: "twice-daily", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10, Cron: "0 8,18 * * *"},
+			{Name: "default", Items: config.NewItems("Weller"), Zipcode: "97210", Distance: 15},
+		},
+	}
+
+	r, err := NewRunner(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	sr := r.(*SearchRunner)
+
+	if cron := sr.userRunners["twice-daily"].cron; cron == nil || cron.String() != "0 8,18 * * *" {
+		t.Errorf("Expected the user's cron schedule, got %v", cron)
+	}
+	if cron := sr.userRunners["default"].cron; cron != nil {
+		t.Errorf("Expected no cron schedule without cron set, got %v", cron)
+	}
+}
+
+// TestRunner_CronSkipsInitialSearch tests that a runner on a cron schedule waits
+// for the next scheduled time rather than searching as soon as it starts
+func TestRunner_CronSkipsInitialSearch(t *testing.T) {
+	ur, _ := newFixtureRunner(t, "search_results.html")
+	cron, err := schedule.Parse("0 0 1 1 *")
+	if err != nil {
+		t.Fatalf("Failed to parse cron: %v", err)
+	}
+	ur.cron = cron
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ur.start(context.Background())
+	}()
+	time.Sleep(200 * time.Millisecond)
+	ur.stop()
+	if err := <-done; err != nil {
+		t.Fatalf("start returned error: %v", err)
+	}
+
+	if h := ur.health(); h.LastSearchTime != nil || h.LastError != "" {
+		t.Errorf("Expected no search before the scheduled time, got %+v", h)
+	}
+}
+
+func TestRandomJitter(t *testing.T) {
+	if got := randomJitter(0); got != 0 {
+		t.Errorf("Expected no jitter when disabled, got %s", got)
+	}
+	for range 100 {
+		if got := randomJitter(time.Second); got < 0 || got >= time.Second {
+			t.Fatalf("Expected jitter in [0, 1s), got %s", got)
+		}
+	}
+}
+
+// TestRunner_StartupJitter tests that with startup jitter, users' first
+// searches are delayed and staggered rather than all running at once
+func TestRunner_StartupJitter(t *testing.T) {
+	early, _ := newFixtureRunner(t, "search_results.html")
+	late, _ := newFixtureRunner(t, "search_results.html")
+	late.userConfig.Name = "user2"
+	for _, ur := range []*userRunner{early, late} {
+		ur.startupJitter = time.Second
+	}
+	// Deterministic jitter: user1 waits a quarter of the limit, user2 all of it
+	early.jitter = func(limit time.Duration) time.Duration { return limit / 4 }
+	late.jitter = func(limit time.Duration) time.Duration { return limit }
+
+	sr := &SearchRunner{
+		userRunners: map[string]*userRunner{"user1": early, "user2": late},
+		stopChan:    make(chan struct{}),
+	}
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- sr.Start(context.Background())
+	}()
+	defer func() {
+		sr.Stop()
+		if err := <-done; err != nil {
+			t.Errorf("Start returned error: %v", err)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if early.health().LastSearchTime != nil || late.health().LastSearchTime != nil {
+		t.Fatal("Expected no search to run at startup with jitter enabled")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for late.health().LastSearchTime == nil && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	earlyAt, lateAt := early.health().LastSearchTime, late.health().LastSearchTime
+	if earlyAt == nil || lateAt == nil {
+		t.Fatal("Expected both users to search after their jitter")
+	}
+	if lateAt.Sub(started) < time.Second {
+		t.Errorf("Expected user2 to wait out its jitter, searched after %s", lateAt.Sub(started))
+	}
+	if !earlyAt.Before(*lateAt) {
+		t.Errorf("Expected user1's search before user2's, got %s and %s", earlyAt, lateAt)
+	}
+}
+
+// newCountingGotifyRunner creates a user runner whose gotify notifier posts to a
+// test server, returning the runner and a counter of received notifications
+func newCountingGotifyRunner(t *testing.T) (*userRunner, *int32) {
+	t.Helper()
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	userConfig := config.UserConfig{
+		Name:     "user1",
+		Items:    config.NewItems("Test Item"),
+		Zipcode:  "97201",
+		Distance: 10,
+		Notifications: []config.NotificationConfig{
+			{
+				Type:       "gotify",
+				Endpoint:   server.URL,
+				Credential: map[string]string{"token": "test-token"},
+			},
+		},
+	}
+
+	ur, err := newUserRunner(userConfig, nil, time.Hour, "test-agent", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create user runner: %v", err)
+	}
+	return ur, &received
+}
+
+// TestRunner_NoNotificationsAfterStop tests that a cycle interrupted by Stop sends nothing
+func TestRunner_NoNotificationsAfterStop(t *testing.T) {
+	items := []search.LiquorItem{{Name: "TEST ITEM", Code: "0001B", Store: "1001 - Portland"}}
+
+	t.Run("stop", func(t *testing.T) {
+		ur, received := newCountingGotifyRunner(t)
+		ur.stop()
+
+		ur.notifyFoundItems(context.Background(), items)
+		ur.notifyHeartbeat(context.Background(), ur.heartbeatStats("", false))
+
+		if got := atomic.LoadInt32(received); got != 0 {
+			t.Errorf("Expected no notifications after Stop, got %d", got)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ur, received := newCountingGotifyRunner(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ur.notifyFoundItems(ctx, items)
+		ur.notifyHeartbeat(ctx, ur.heartbeatStats("", false))
+
+		if got := atomic.LoadInt32(received); got != 0 {
+			t.Errorf("Expected no notifications after cancellation, got %d", got)
+		}
+	})
+
+	t.Run("flush on stop", func(t *testing.T) {
+		ur, received := newCountingGotifyRunner(t)
+		ur.flushOnStop = true
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ur.stop()
+
+		ur.notifyFoundItems(ctx, items)
+		ur.notifyHeartbeat(ctx, ur.heartbeatStats("", false))
+
+		if got := atomic.LoadInt32(received); got != 2 {
+			t.Errorf("Expected 2 flushed notifications, got %d", got)
+		}
+	})
+
+	t.Run("running", func(t *testing.T) {
+		ur, received := newCountingGotifyRunner(t)
+
+		ur.notifyFoundItems(context.Background(), items)
+
+		if got := atomic.LoadInt32(received); got != 1 {
+			t.Errorf("Expected 1 notification while running, got %d", got)
+		}
+	})
+}
+
+// cancellingSearcher finds every item, cancelling the cycle's context after the first search
+type cancellingSearcher struct {
+	cancel   context.CancelFunc
+	searched []string
+}
+
+func (c *cancellingSearcher) SearchItem(ctx context.Context, item string, zipcode string, distance int) ([]search.LiquorItem, error) {
+	c.searched = append(c.searched, item)
+	c.cancel()
+	return []search.LiquorItem{{Name: strings.ToUpper(item), Code: "0001B", Store: "1001 - Portland", Price: "$19.99"}}, nil
+}
+
+// TestRunSearch_FlushOnStop tests that a cycle interrupted by a shutdown still
+// notifies about the items found so far with flush_on_stop, and only then
+func TestRunSearch_FlushOnStop(t *testing.T) {
+	for _, flush := range []bool{true, false} {
+		t.Run(fmt.Sprintf("flush=%t", flush), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			searcher := &cancellingSearcher{cancel: cancel}
+			recorder := &recordingNotifier{}
+			userConfig := config.UserConfig{Name: "user1", Items: config.NewItems("Weller", "Blanton's"), Zipcode: "97201", Distance: 10}
+			ur, err := newUserRunner(userConfig, searcher, time.Hour, "test-agent", nil, nil,
+				[]notification.ManagerOption{notification.WithNotifiers(recorder)})
+			if err != nil {
+				t.Fatalf("Failed to create user runner: %v", err)
+			}
+			ur.flushOnStop = flush
+			ur.searchDelay = func() time.Duration { return time.Hour }
+
+			if err := ur.runSearch(ctx, false); err == nil {
+				t.Error("Expected an interrupted search to return an error")
+			}
+			if len(searcher.searched) != 1 {
+				t.Errorf("Expected the shutdown to skip the remaining searches, got %v", searcher.searched)
+			}
+			sent := strings.Join(recorder.sent, "\n")
+			if flush && !strings.Contains(sent, "WELLER") {
+				t.Errorf("Expected the item found before the shutdown to be notified, got: %q", sent)
+			}
+			if !flush && len(recorder.sent) != 0 {
+				t.Errorf("Expected no notifications without flush_on_stop, got: %q", sent)
+			}
+		})
+	}
+}
+
+// TestRunner_StopTwice tests that stopping a runner more than once does not panic
+func TestRunner_StopTwice(t *testing.T) {
+	sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"))
+
+	sr.Stop()
+	sr.Stop()
+	if err := sr.StopWithContext(context.Background()); err != nil {
+		t.Errorf("Expected StopWithContext after Stop to succeed, got: %v", err)
+	}
+	userRunnerFor(sr, "alice").stop()
+	userRunnerFor(sr, "alice").stop()
+}
+
+// TestRunner_StopWithContext tests that StopWithContext waits for user runners
+// to finish and gives up when its context is done
+func TestRunner_StopWithContext(t *testing.T) {
+	t.Run("running", func(t *testing.T) {
+		sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"))
+		done := make(chan error, 1)
+		go func() {
+			done <- sr.Start(context.Background())
+		}()
+		waitFor(t, "alice to start", func() bool { return userRunnerFor(sr, "alice").health().Alive })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := sr.StopWithContext(ctx); err != nil {
+			t.Fatalf("StopWithContext failed: %v", err)
+		}
+		if userRunnerFor(sr, "alice").health().Alive {
+			t.Error("Expected alice to have stopped when StopWithContext returned")
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Expected Start to return cleanly, got: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		sr := newReloadTestRunner(t, reloadTestUser("alice", "Blanton's"))
+		// Simulate a user runner that does not finish in time
+		sr.wg.Add(1)
+		defer sr.wg.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := sr.StopWithContext(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected a deadline exceeded error, got: %v", err)
+		}
+	})
+}
+
+// blockingSearcher blocks each search until its context is done, then takes a
+// moment longer to return, like a search request winding down
+type blockingSearcher struct {
+	started  chan struct{}
+	returned atomic.Bool
+}
+
+func (b *blockingSearcher) SearchItem(ctx context.Context, item string, zipcode string, distance int) ([]search.LiquorItem, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	b.returned.Store(true)
+	return nil, ctx.Err()
+}
+
+// TestRunner_StopWithContextWaitsForSearch tests that StopWithContext only
+// returns once a search in progress has finished
+func TestRunner_StopWithContextWaitsForSearch(t *testing.T) {
+	blocking := &blockingSearcher{started: make(chan struct{}, 1)}
+	r, err := NewRunner(config.Config{
+		Interval: time.Hour,
+		Users:    []config.UserConfig{{Name: "alice", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10}},
+	}, WithSearcherFactory(func(config.UserConfig) Searcher { return blocking }))
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	sr := r.(*SearchRunner)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sr.Start(context.Background())
+	}()
+	select {
+	case <-blocking.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the search to start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sr.StopWithContext(ctx); err != nil {
+		t.Fatalf("StopWithContext failed: %v", err)
+	}
+	if !blocking.returned.Load() {
+		t.Error("Expected the search in progress to have finished when StopWithContext returned")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected Start to return cleanly, got: %v", err)
+	}
+}
+
+// TestUserLocation tests that a user's timezone overrides the global one
+func TestUserLocation(t *testing.T) {
+	cfg := config.Config{Timezone: "America/Los_Angeles"}
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		user     config.UserConfig
+		expected string
+	}{
+		{name: "user override", cfg: cfg, user: config.UserConfig{Name: "east", Timezone: "America/New_York"}, expected: "America/New_York"},
+		{name: "global", cfg: cfg, user: config.UserConfig{Name: "west"}, expected: "America/Los_Angeles"},
+		{name: "local", cfg: config.Config{}, user: config.UserConfig{Name: "local"}, expected: time.Local.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := userLocation(tt.cfg, tt.user)
+			if err != nil {
+				t.Skipf("Time zone data unavailable: %v", err)
+			}
+			if loc.String() != tt.expected {
+				t.Errorf("Expected location %s, got %s", tt.expected, loc)
+			}
+		})
+	}
+
+	if _, err := userLocation(config.Config{}, config.UserConfig{Name: "bad", Timezone: "Mars/Olympus_Mons"}); err == nil {
+		t.Error("Expected error for an unknown timezone")
+	}
+}
+
+// TestMessageOptions_Location tests that previews built from MessageOptions
+// show found times in the user's timezone, as sent notifications do
+func TestMessageOptions_Location(t *testing.T) {
+	cfg := config.Config{Timezone: "America/New_York"}
+	user := config.UserConfig{Name: "alice"}
+	opts, err := MessageOptions(cfg, user)
+	if err != nil {
+		t.Fatalf("MessageOptions failed: %v", err)
+	}
+
+	items := []search.LiquorItem{{Name: "BLANTON'S", Store: "1001 - Portland", Price: "$64.95", Date: time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)}}
+	messages, err := notification.PreviewFoundItems(config.NotificationConfig{Type: "gotify"}, items, opts...)
+	if err != nil {
+		t.Fatalf("PreviewFoundItems failed: %v", err)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0].Body, "10:04") {
+		t.Errorf("Expected the found time in New York time, got: %+v", messages)
+	}
+
+	user.Timezone = "Mars/Olympus_Mons"
+	if _, err := MessageOptions(cfg, user); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+}
+
+// TestMessageOptions_ItemDetails tests that previews built from MessageOptions
+// include item details when show_item_details is set, as sent notifications do
+func TestMessageOptions_ItemDetails(t *testing.T) {
+	items := []search.LiquorItem{{Name: "BLANTON'S", Store: "1001 - Portland", Price: "$64.95", Size: "750 ML", Proof: "93.0"}}
+
+	for _, show := range []bool{true, false} {
+		opts, err := MessageOptions(config.Config{ShowItemDetails: show}, config.UserConfig{Name: "alice"})
+		if err != nil {
+			t.Fatalf("MessageOptions failed: %v", err)
+		}
+		messages, err := notification.PreviewFoundItems(config.NotificationConfig{Type: "gotify"}, items, opts...)
+		if err != nil {
+			t.Fatalf("PreviewFoundItems failed: %v", err)
+		}
+		if got := strings.Contains(messages[0].Body, "(750 ML, 93.0 proof)"); got != show {
+			t.Errorf("Expected item details shown to be %t, got message: %q", show, messages[0].Body)
+		}
+	}
+}
+
+// TestRunner_ResultsChanged tests result set comparison between cycles
+func TestRunner_ResultsChanged(t *testing.T) {
+	ur := &userRunner{}
+	first := []search.LiquorItem{
+		{Name: "BLANTON'S", Code: "0171B", Store: "1001 - Portland", Price: "$64.95", Quantity: 2, Date: time.Now()},
+		{Name: "BLANTON'S", Code: "0171B", Store: "1003 - Gresham", Price: "$64.95", Quantity: 1, Date: time.Now()},
+	}
+	// Same results in a different order, found at a different time
+	same := []search.LiquorItem{first[1], first[0]}
+	same[0].Date = same[0].Date.Add(time.Hour)
+	restocked := []search.LiquorItem{first[0], first[1]}
+	restocked[1].Quantity = 6
+
+	if !ur.resultsChanged(first) {
+		t.Error("Expected the first results to count as changed")
+	}
+	if ur.resultsChanged(same) {
+		t.Error("Expected identical results to count as unchanged")
+	}
+	if !ur.resultsChanged(restocked) {
+		t.Error("Expected a quantity change to count as changed")
+	}
+	if !ur.resultsChanged(nil) {
+		t.Error("Expected no results to count as changed")
+	}
+	if !ur.resultsChanged(restocked) {
+		t.Error("Expected results after an empty cycle to count as changed")
+	}
+}
+
+// TestRunner_SearchTerms tests that a search_terms override is sent to OLCC
+// while results stay attributed to the item as written in the watch list
+func TestRunner_SearchTerms(t *testing.T) {
+	fixture := newFixtureServer(t, "search_results.html")
+	var mu sync.Mutex
+	var terms []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.ParseForm() == nil && r.PostForm.Has("productSearchParam") {
+			mu.Lock()
+			terms = append(terms, r.PostForm.Get("productSearchParam"))
+			mu.Unlock()
+		}
+		fixture.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	recorder := &recordingNotifier{}
+	userConfig := config.UserConfig{
+		Name:        "user1",
+		Items:       config.NewItems("Jack"),
+		Zipcode:     "97201",
+		Distance:    10,
+		SearchTerms: map[string]string{"jack": "JACK DANIELS #7"},
+	}
+	ur, err := newUserRunner(userConfig, nil, time.Hour, "test-agent", nil,
+		[]search.SearcherOption{search.WithBaseURL(server.URL)},
+		[]notification.ManagerOption{notification.WithNotifiers(recorder)})
+	if err != nil {
+		t.Fatalf("Failed to create user runner: %v", err)
+	}
+	ur.newOnly = true
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	if len(terms) != 1 || terms[0] != "JACK DANIELS #7" {
+		t.Errorf("Expected the override term to be searched, got %v", terms)
+	}
+	if _, ok := ur.dryStreaks["Jack"]; !ok {
+		t.Errorf("Expected the search to be recorded under the watch list name, got %v", ur.dryStreaks)
+	}
+	for key, seen := range ur.notified {
+		if seen.query != "Jack" {
+			t.Errorf("Expected found item %s to belong to the watch list name, got query %q", key, seen.query)
+		}
+	}
+	if len(recorder.sent) == 0 || !strings.Contains(recorder.sent[0], "GFL - Found JACK DANIELS #7 BL LABEL") {
+		t.Errorf("Expected found notifications to use the product name, got %v", recorder.sent)
+	}
+
+	if got := ur.searchTerm("Eagle Rare"); got != "Eagle Rare" {
+		t.Errorf("Expected items without an override to be searched as written, got %q", got)
+	}
+}
+
+// TestRunner_LogsUserField tests that a user runner's log lines carry the user's name as a field
+func TestRunner_LogsUserField(t *testing.T) {
+	var buf bytes.Buffer
+	originalOut, originalFormatter := log.StandardLogger().Out, log.StandardLogger().Formatter
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.JSONFormatter{})
+	t.Cleanup(func() {
+		log.SetOutput(originalOut)
+		log.SetFormatter(originalFormatter)
+	})
+
+	ur, _ := newFixtureRunner(t, "search_results.html")
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	runnerLines := 0
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected a JSON log line, got %q: %v", line, err)
+		}
+		if entry["component"] != "runner" {
+			continue
+		}
+		runnerLines++
+		if entry["user"] != "user1" {
+			t.Errorf("Expected the user field on %q", line)
+		}
+	}
+	if runnerLines == 0 {
+		t.Error("Expected the search to be logged by the runner")
+	}
+}
+
+// TestRunner_HeartbeatCadence tests that heartbeats are only sent as often as
+// the user's heartbeat interval allows
+func TestRunner_HeartbeatCadence(t *testing.T) {
+	heartbeats := func(recorder *recordingNotifier) int {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		count := 0
+		for _, sent := range recorder.sent {
+			if strings.HasPrefix(sent, "subject: GFL - Heartbeat") {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("never by default", func(t *testing.T) {
+		ur, recorder := newFixtureRunner(t, "search_results.html")
+		for i := 0; i < 2; i++ {
+			if err := ur.runOnce(context.Background()); err != nil {
+				t.Fatalf("runOnce failed: %v", err)
+			}
+		}
+		if got := heartbeats(recorder); got != 0 {
+			t.Errorf("Expected no heartbeats without a heartbeat interval, got %d", got)
+		}
+	})
+
+	t.Run("once per interval", func(t *testing.T) {
+		ur, recorder := newFixtureRunner(t, "search_results.html")
+		ur.heartbeatEvery = time.Hour
+		for i := 0; i < 3; i++ {
+			if err := ur.runOnce(context.Background()); err != nil {
+				t.Fatalf("runOnce failed: %v", err)
+			}
+		}
+		if got := heartbeats(recorder); got != 1 {
+			t.Errorf("Expected 1 heartbeat within the interval, got %d", got)
+		}
+
+		// Once the interval has passed the next cycle sends another
+		ur.lastHeartbeat = ur.lastHeartbeat.Add(-time.Hour)
+		if err := ur.runOnce(context.Background()); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+		if got := heartbeats(recorder); got != 2 {
+			t.Errorf("Expected a heartbeat after the interval, got %d", got)
+		}
+	})
+
+	t.Run("user override", func(t *testing.T) {
+		ur, _ := newFixtureRunner(t, "search_results.html")
+		ur.heartbeatEvery = time.Hour
+		ur.userConfig.HeartbeatInterval = 24 * time.Hour
+		ur.lastHeartbeat = time.Now().Add(-2 * time.Hour)
+		if ur.heartbeatDue(time.Now()) {
+			t.Error("Expected the user's daily heartbeat not to be due after 2 hours")
+		}
+		if !ur.heartbeatDue(time.Now().Add(22 * time.Hour)) {
+			t.Error("Expected the user's daily heartbeat to be due after a day")
+		}
+	})
+}
+
+// fakeSearcher is a Searcher returning canned items, recording the searches made
+type fakeSearcher struct {
+	mu        sync.Mutex
+	items     map[string][]search.LiquorItem
+	searched  []string
+	distances []int
+}
+
+func (f *fakeSearcher) SearchItem(ctx context.Context, item string, zipcode string, distance int) ([]search.LiquorItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.searched = append(f.searched, item)
+	f.distances = append(f.distances, distance)
+	return f.items[item], nil
+}
+
+// TestRandomDelayBetween tests that item search delays fall within the configured range
+func TestRandomDelayBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{"default", 0, config.DefaultMaxItemDelay},
+		{"range", 10 * time.Second, time.Minute},
+		{"fixed", 5 * time.Second, 5 * time.Second},
+		{"disabled", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay := randomDelayBetween(tt.minDelay, tt.maxDelay)
+			for range 100 {
+				if got := delay(); got < tt.minDelay || got > tt.maxDelay {
+					t.Fatalf("Expected a delay between %s and %s, got %s", tt.minDelay, tt.maxDelay, got)
+				}
+			}
+		})
+	}
+}
+
+// peakSearcher is a Searcher that holds each search briefly, recording the
+// most searches in progress at once
+type peakSearcher struct {
+	active atomic.Int32
+	peak   atomic.Int32
+}
+
+func (p *peakSearcher) SearchItem(ctx context.Context, item string, zipcode string, distance int) ([]search.LiquorItem, error) {
+	n := p.active.Add(1)
+	defer p.active.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return nil, nil
+}
+
+// TestRunner_MaxConcurrentSearches tests that item searches across all users
+// never exceed max_concurrent_searches
+func TestRunner_MaxConcurrentSearches(t *testing.T) {
+	fake := &peakSearcher{}
+	cfg := config.Config{
+		Interval:              time.Hour,
+		MaxConcurrentSearches: 2,
+	}
+	for _, name := range []string{"user1", "user2", "user3", "user4"} {
+		cfg.Users = append(cfg.Users, config.UserConfig{
+			Name:     name,
+			Items:    config.NewItems("Blanton's", "Pappy Van Winkle", "Weller 12"),
+			Zipcode:  "97201",
+			Distance: 10,
+		})
+	}
+
+	runner, err := NewRunner(cfg, WithSearcherFactory(func(config.UserConfig) Searcher { return fake }))
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	for _, ur := range runner.(*SearchRunner).userRunners {
+		ur.searchDelay = func() time.Duration { return 0 }
+	}
+	if err := runner.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	if peak := fake.peak.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 searches at once, got %d", peak)
+	} else if peak == 0 {
+		t.Error("Expected searches to run")
+	}
+}
+
+// TestRunner_StatewideWithoutZipcode tests that a statewide user without a
+// zipcode searches every store
+func TestRunner_StatewideWithoutZipcode(t *testing.T) {
+	fake := &fakeSearcher{}
+	cfg := config.Config{
+		Interval: time.Hour,
+		Users: []config.UserConfig{
+			{Name: "user1", Items: config.NewItems("Blanton's", "Eagle Rare"), Statewide: true},
+		},
+	}
+
+	runner, err := NewRunner(cfg, WithSearcherFactory(func(config.UserConfig) Searcher { return fake }))
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	if err := runner.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.distances) < 2 {
+		t.Fatalf("Expected both items to be searched, got %v", fake.searched)
+	}
+	for i, distance := range fake.distances {
+		if distance != search.Statewide {
+			t.Errorf("Expected %s to be searched statewide, got distance %d", fake.searched[i], distance)
+		}
+	}
+}
+
+// TestRunner_FakeSearcherNotifies tests that items found by an injected
+// searcher are notified, without any network calls to OLCC
+func TestRunner_FakeSearcherNotifies(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	fake := &fakeSearcher{items: map[string][]search.LiquorItem{
+		"Blanton's": {
+			{Name: "BLANTON'S", Code: "0171B", Store: "1001 - Portland", Price: "$59.99", Quantity: 2},
+			{Name: "BLANTON'S", Code: "0171B", Store: "1002 - Beaverton", Price: "$59.99", Quantity: 1},
+		},
+	}}
+	notifications := []config.NotificationConfig{
+		{Type: "gotify", Endpoint: server.URL, Credential: map[string]string{"token": "test-token"}},
+	}
+	cfg := config.Config{
+		Interval: time.Hour,
+		Users: []config.UserConfig{
+			{Name: "user1", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10, Notifications: notifications},
+			{Name: "user2", Items: config.NewItems("Pappy Van Winkle"), Zipcode: "97201", Distance: 10, Notifications: notifications},
+		},
+	}
+
+	runner, err := NewRunner(cfg, WithSearcherFactory(func(config.UserConfig) Searcher { return fake }))
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	if err := runner.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	searched := len(fake.searched)
+	fake.mu.Unlock()
+	if searched != 2 {
+		t.Errorf("Expected each user's item to be searched with the fake, got %d searches", searched)
+	}
+	if got := atomic.LoadInt32(&received); got != 2 {
+		t.Errorf("Expected a notification for each store carrying the found item, got %d", got)
+	}
+}
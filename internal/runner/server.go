@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// serverShutdownTimeout bounds how long in-flight requests may take on shutdown
+const serverShutdownTimeout = 5 * time.Second
+
+// serveHTTP serves handler at path on addr in the background, returning a
+// function that shuts the server down. name describes the server in logs.
+func serveHTTP(name, addr, path string, handler http.Handler) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for %s on %s: %w", name, addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("%s server failed: %v", name, err)
+		}
+	}()
+	logger.Infof("Serving %s at http://%s%s", name, listener.Addr(), path)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Warnf("Failed to shut down %s server: %v", name, err)
+		}
+	}, nil
+}
@@ -0,0 +1,197 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// defaultStateFile is the runner state file name in the state dir when
+// state_file isn't set
+const defaultStateFile = "runner_state.json"
+
+// UserState is the part of a user runner's state that survives restarts
+type UserState struct {
+	// LastRun is when the user's last successful search cycle finished
+	LastRun time.Time `json:"last_run"`
+	// LastFind is when any of the user's items was last found in stock
+	LastFind time.Time `json:"last_find,omitzero"`
+	// Notified holds the items notified about that were still in stock, keyed
+	// like notifiedKey, so notify_new_only doesn't repeat them after a restart
+	Notified map[string]NotifiedState `json:"notified,omitempty"`
+	// LastResults is the digest of the last cycle's results, for skip_unchanged_cycles
+	LastResults string `json:"last_results,omitempty"`
+	// LastHeartbeat is when the user was last sent a heartbeat, for heartbeat_interval
+	LastHeartbeat time.Time `json:"last_heartbeat,omitzero"`
+	// DryStreaks holds how long each watched item has gone without stock, so
+	// dry_spell_alert keeps counting across restarts
+	DryStreaks map[string]DryStreakState `json:"dry_streaks,omitempty"`
+}
+
+// DryStreakState records an item's time without stock, as tracked for dry_spell_alert
+type DryStreakState struct {
+	// Since is when the item was last found, or when searching for it began
+	Since time.Time `json:"since"`
+	// Found reports whether the item has been found at all
+	Found bool `json:"found,omitempty"`
+	// Alerted is the number of dry spell thresholds already notified
+	Alerted int `json:"alerted,omitempty"`
+}
+
+// NotifiedState records when an item was last notified about, and the
+// watched item that found it
+type NotifiedState struct {
+	Query string    `json:"query"`
+	At    time.Time `json:"at"`
+}
+
+// StateStore persists user runner state across restarts
+type StateStore interface {
+	// Load returns the saved state of a user, and false if there is none
+	Load(user string) (UserState, bool)
+	// Save stores the state of a user
+	Save(user string, state UserState) error
+}
+
+// FileStateStore is a StateStore keeping every user's state in one JSON file
+type FileStateStore struct {
+	path  string
+	mu    sync.Mutex
+	users map[string]UserState
+}
+
+// NewFileStateStore opens the state file at path. A missing file starts with
+// no saved state, as on the first run, and so does a file that can't be read
+// or parsed: losing state only means items may be notified again.
+func NewFileStateStore(path string) *FileStateStore {
+	s := &FileStateStore{path: path, users: make(map[string]UserState)}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is from config, not user input
+	if errors.Is(err, os.ErrNotExist) {
+		return s
+	}
+	if err != nil {
+		logger.Warnf("Failed to read runner state %s, starting fresh: %v", path, err)
+		return s
+	}
+	if err := json.Unmarshal(data, &s.users); err != nil || s.users == nil {
+		logger.Warnf("Runner state %s is corrupt, starting fresh: %v", path, err)
+		s.users = make(map[string]UserState)
+	}
+	return s
+}
+
+// Load returns the saved state of a user, and false if there is none
+func (s *FileStateStore) Load(user string) (UserState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.users[user]
+	return state, ok
+}
+
+// Save stores the state of a user and writes every user's state to the file
+func (s *FileStateStore) Save(user string, state UserState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[user] = state
+	data, err := json.MarshalIndent(s.users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode runner state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated state file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write runner state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write runner state: %w", err)
+	}
+	return nil
+}
+
+// statePath returns the configured runner state file, defaulting to one in
+// the state dir, or "" if state isn't persisted
+func statePath(cfg config.Config) string {
+	if cfg.StateFile != "" {
+		return cfg.StateFile
+	}
+	if cfg.StateDir != "" {
+		return filepath.Join(cfg.StateDir, defaultStateFile)
+	}
+	return ""
+}
+
+// restoreState resumes from the user's saved state, if any
+func (ur *userRunner) restoreState() {
+	if ur.state == nil {
+		return
+	}
+	state, ok := ur.state.Load(ur.userConfig.Name)
+	if !ok {
+		return
+	}
+
+	ur.lastFind = state.LastFind
+	ur.lastResults = state.LastResults
+	ur.lastHeartbeat = state.LastHeartbeat
+	if len(state.Notified) > 0 {
+		ur.notified = make(map[string]notifiedItem, len(state.Notified))
+		for key, n := range state.Notified {
+			ur.notified[key] = notifiedItem{query: n.Query, at: n.At}
+		}
+	}
+	// Items no longer watched are dropped
+	for _, item := range ur.userConfig.ItemNames() {
+		if streak, ok := state.DryStreaks[item]; ok {
+			ur.dryStreaks[item] = &dryStreak{since: streak.Since, found: streak.Found, alerted: streak.Alerted}
+		}
+	}
+
+	ur.statusMu.Lock()
+	ur.lastSearchTime = state.LastRun
+	ur.statusMu.Unlock()
+
+	ur.log.Infof("Restored state from the last search at %s", state.LastRun.Format(time.RFC1123))
+}
+
+// saveState saves the user's state after a search cycle
+func (ur *userRunner) saveState() {
+	if ur.state == nil {
+		return
+	}
+
+	ur.statusMu.Lock()
+	state := UserState{LastRun: ur.lastSearchTime}
+	ur.statusMu.Unlock()
+	state.LastFind = ur.lastFind
+	state.LastResults = ur.lastResults
+	state.LastHeartbeat = ur.lastHeartbeat
+	if len(ur.notified) > 0 {
+		state.Notified = make(map[string]NotifiedState, len(ur.notified))
+		for key, n := range ur.notified {
+			state.Notified[key] = NotifiedState{Query: n.query, At: n.at}
+		}
+	}
+	if len(ur.dryStreaks) > 0 {
+		state.DryStreaks = make(map[string]DryStreakState, len(ur.dryStreaks))
+		for item, streak := range ur.dryStreaks {
+			state.DryStreaks[item] = DryStreakState{Since: streak.since, Found: streak.found, Alerted: streak.alerted}
+		}
+	}
+
+	if err := ur.state.Save(ur.userConfig.Name, state); err != nil {
+		ur.log.Errorf("Failed to save state: %v", err)
+	}
+}
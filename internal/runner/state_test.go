@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+func TestFileStateStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "runner_state.json")
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	state := UserState{
+		LastRun:     at,
+		LastFind:    at.Add(-time.Hour),
+		Notified:    map[string]NotifiedState{"Blanton's|0171B|Store A": {Query: "Blanton's", At: at}},
+		LastResults: "abc123",
+	}
+
+	store := NewFileStateStore(path)
+	if _, ok := store.Load("alice"); ok {
+		t.Error("Expected no state for a new store")
+	}
+	if err := store.Save("alice", state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save("bob", UserState{LastRun: at}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A new store reads the saved state back from the file
+	reopened := NewFileStateStore(path)
+	got, ok := reopened.Load("alice")
+	if !ok || !reflect.DeepEqual(got, state) {
+		t.Errorf("Expected state %+v, got %+v (found: %t)", state, got, ok)
+	}
+	if _, ok := reopened.Load("bob"); !ok {
+		t.Error("Expected every user's state to be saved")
+	}
+}
+
+func TestFileStateStore_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runner_state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	store := NewFileStateStore(path)
+	if _, ok := store.Load("alice"); ok {
+		t.Error("Expected a corrupt state file to start fresh")
+	}
+	if err := store.Save("alice", UserState{}); err != nil {
+		t.Fatalf("Expected a corrupt state file to be overwritten, got: %v", err)
+	}
+	if _, ok := NewFileStateStore(path).Load("alice"); !ok {
+		t.Error("Expected the state to be saved over the corrupt file")
+	}
+}
+
+func TestStatePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		expected string
+	}{
+		{name: "disabled", cfg: config.Config{}, expected: ""},
+		{name: "state dir", cfg: config.Config{StateDir: "state"}, expected: filepath.Join("state", defaultStateFile)},
+		{name: "state file", cfg: config.Config{StateDir: "state", StateFile: "/var/lib/gfl/state.json"}, expected: "/var/lib/gfl/state.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statePath(tt.cfg); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestRunner_StateSuppressesSeenItems tests that items notified about before a
+// restart are not notified again by the new runner while still in stock
+func TestRunner_StateSuppressesSeenItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runner_state.json")
+
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+	ur.newOnly = true
+	ur.state = NewFileStateStore(path)
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	if found := countFound(recorder); found == 0 {
+		t.Fatal("Expected the first run to notify about found items")
+	}
+
+	// Simulate a restart: a new runner with state loaded from the file
+	restarted, recorder := newFixtureRunner(t, "search_results.html")
+	restarted.newOnly = true
+	restarted.state = NewFileStateStore(path)
+	restarted.restoreState()
+	if restarted.health().LastSearchTime == nil || restarted.lastFind.IsZero() {
+		t.Error("Expected the last search and find times to be restored")
+	}
+	if err := restarted.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	if found := countFound(recorder); found != 0 {
+		t.Errorf("Expected items seen before the restart to be suppressed, got %d found notifications", found)
+	}
+}
+
+// countFound returns how many found item notifications recorder received
+func countFound(recorder *recordingNotifier) int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	found := 0
+	for _, sent := range recorder.sent {
+		if strings.HasPrefix(sent, "subject: GFL - Found") {
+			found++
+		}
+	}
+	return found
+}
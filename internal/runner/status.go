@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// UserStatus is a user's entry in the status report served at /status
+type UserStatus struct {
+	Name           string     `json:"name"`
+	Alive          bool       `json:"alive"`
+	LastSearchTime *time.Time `json:"last_search_time,omitempty"`
+	// LastResultCount is how many results the last search cycle found in stock
+	LastResultCount int        `json:"last_result_count"`
+	NextSearchTime  *time.Time `json:"next_search_time,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+// StatusReport is the body served at /status
+type StatusReport struct {
+	Users []UserStatus `json:"users"`
+}
+
+// setNextSearch records when the user's next search is scheduled (zero = none)
+func (ur *userRunner) setNextSearch(next time.Time) {
+	ur.statusMu.Lock()
+	defer ur.statusMu.Unlock()
+	ur.nextSearchTime = next
+}
+
+// recordResultCount records how many results a search cycle found
+func (ur *userRunner) recordResultCount(count int) {
+	ur.statusMu.Lock()
+	defer ur.statusMu.Unlock()
+	ur.lastResultCount = count
+}
+
+// status returns the user runner's entry in the status report
+func (ur *userRunner) status() UserStatus {
+	h := ur.health()
+
+	ur.statusMu.Lock()
+	defer ur.statusMu.Unlock()
+	s := UserStatus{
+		Name:            h.Name,
+		Alive:           h.Alive,
+		LastSearchTime:  h.LastSearchTime,
+		LastResultCount: ur.lastResultCount,
+		LastError:       h.LastError,
+	}
+	if !ur.nextSearchTime.IsZero() {
+		t := ur.nextSearchTime
+		s.NextSearchTime = &t
+	}
+	return s
+}
+
+// status reports the search status of each user runner, sorted by name
+func (sr *SearchRunner) status() StatusReport {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	report := StatusReport{Users: make([]UserStatus, 0, len(sr.userRunners))}
+	for _, ur := range sr.userRunners {
+		report.Users = append(report.Users, ur.status())
+	}
+	sort.Slice(report.Users, func(i, j int) bool {
+		return report.Users[i].Name < report.Users[j].Name
+	})
+	return report
+}
+
+// serveStatusReport writes the status report as JSON
+func (sr *SearchRunner) serveStatusReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sr.status())
+}
+
+// serveStatus serves the runner's status report at /status on addr in the
+// background, returning a function that shuts the server down
+func serveStatus(addr string, sr *SearchRunner) (func(), error) {
+	return serveHTTP("status", addr, "/status", http.HandlerFunc(sr.serveStatusReport))
+}
+
+// FetchStatus requests the status report of the GFL instance serving it on addr
+func FetchStatus(ctx context.Context, addr string) (StatusReport, error) {
+	var report StatusReport
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+"/status", nil)
+	if err != nil {
+		return report, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req) // #nosec G704 -- status address is from config, not user input
+	if err != nil {
+		return report, fmt.Errorf("failed to reach GFL at %s (is it running with status_addr set?): %w", addr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return report, fmt.Errorf("status request returned status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return report, fmt.Errorf("failed to decode status: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	ur, _ := newFixtureRunner(t, "search_results.html")
+	sr := &SearchRunner{userRunners: map[string]*userRunner{"user1": ur}, stopChan: make(chan struct{})}
+
+	ur.setAlive(true)
+	next := time.Now().Add(time.Hour)
+	ur.setNextSearch(next)
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(sr.serveStatusReport))
+	defer server.Close()
+
+	report, err := FetchStatus(context.Background(), strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("FetchStatus failed: %v", err)
+	}
+	if len(report.Users) != 1 {
+		t.Fatalf("Expected 1 user in the report, got %d", len(report.Users))
+	}
+	user := report.Users[0]
+	if user.Name != "user1" || !user.Alive || user.LastSearchTime == nil {
+		t.Errorf("Unexpected user status after a successful search: %+v", user)
+	}
+	if user.LastResultCount == 0 {
+		t.Error("Expected the last result count to be reported")
+	}
+	if user.NextSearchTime == nil || !user.NextSearchTime.Equal(next) {
+		t.Errorf("Expected next search time %v, got %v", next, user.NextSearchTime)
+	}
+}
+
+func TestStatus_MethodNotAllowed(t *testing.T) {
+	sr := &SearchRunner{userRunners: map[string]*userRunner{}, stopChan: make(chan struct{})}
+
+	rec := httptest.NewRecorder()
+	sr.serveStatusReport(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestFetchStatus_NotRunning(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	addr := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	if _, err := FetchStatus(context.Background(), addr); err == nil {
+		t.Error("Expected an error when nothing serves the status endpoint")
+	}
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+)
+
+// itemStreamer posts each found item to a webhook as soon as it is found,
+// independent of the end-of-cycle notifications
+type itemStreamer struct {
+	url    string
+	client *http.Client
+}
+
+// streamedItem is the JSON body posted to the stream webhook
+type streamedItem struct {
+	User string            `json:"user"`
+	Item search.LiquorItem `json:"item"`
+}
+
+// newItemStreamer creates a streamer posting to url, or returns nil if url is empty
+func newItemStreamer(url string) *itemStreamer {
+	if url == "" {
+		return nil
+	}
+	return &itemStreamer{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// post sends a single found item to the webhook
+func (s *itemStreamer) post(ctx context.Context, user string, item search.LiquorItem) error {
+	body, err := json.Marshal(streamedItem{User: user, Item: item})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req) // #nosec G704 -- webhook URL is from config, not user input
+	if err != nil {
+		return fmt.Errorf("failed to post item: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("stream webhook returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// streamFinds posts each found item to the stream webhook, if configured.
+// Failures are logged and never interrupt the search.
+func (ur *userRunner) streamFinds(ctx context.Context, items []search.LiquorItem) {
+	if ur.streamer == nil {
+		return
+	}
+
+	for _, item := range items {
+		if err := ur.streamer.post(ctx, ur.userConfig.Name, item); err != nil {
+			ur.log.Warnf("Failed to stream %s at %s: %v", item.Name, item.Store, err)
+		}
+	}
+}
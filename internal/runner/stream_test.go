@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestStreamFinds tests that each found item is posted to the stream webhook
+// as soon as it is found, before the end-of-cycle notifications
+func TestStreamFinds(t *testing.T) {
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+
+	var mu sync.Mutex
+	var streamed []streamedItem
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload streamedItem
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		recorder.mu.Lock()
+		for _, sent := range recorder.sent {
+			if strings.Contains(sent, "GFL - Found") {
+				t.Errorf("Expected %s to be streamed before found notifications were sent", payload.Item.Store)
+			}
+		}
+		recorder.mu.Unlock()
+
+		mu.Lock()
+		streamed = append(streamed, payload)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+	ur.streamer = newItemStreamer(webhook.URL)
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The fixture has 2 stores in stock
+	if len(streamed) != 2 {
+		t.Fatalf("Expected 2 streamed items, got %d", len(streamed))
+	}
+	for _, payload := range streamed {
+		if payload.User != "user1" || payload.Item.Name == "" || payload.Item.Store == "" {
+			t.Errorf("Expected user and item details in streamed payload, got %+v", payload)
+		}
+	}
+}
+
+// TestStreamFinds_FailureDoesNotAbortSearch tests that webhook errors are
+// logged without interrupting the search or its notifications
+func TestStreamFinds_FailureDoesNotAbortSearch(t *testing.T) {
+	ur, recorder := newFixtureRunner(t, "search_results.html")
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer webhook.Close()
+	ur.streamer = newItemStreamer(webhook.URL)
+
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("Expected search to succeed despite webhook failures, got: %v", err)
+	}
+
+	found := 0
+	for _, sent := range recorder.sent {
+		if strings.Contains(sent, "GFL - Found") {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("Expected 2 found notifications, got %d", found)
+	}
+}
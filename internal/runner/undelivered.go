@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// Ways of handling found items that no notifier delivered, set with on_notify_failure
+const (
+	failureLog   = "log"
+	failureFile  = "file"
+	failureRetry = "retry-next-cycle"
+)
+
+// defaultDeadLetterFile is the dead-letter file name in the state dir when
+// dead_letter_file isn't set
+const defaultDeadLetterFile = "undelivered.jsonl"
+
+// deadLetterMu serializes appends to the dead-letter file, which users share
+var deadLetterMu sync.Mutex
+
+// deadLetter is one line of the dead-letter file
+type deadLetter struct {
+	User  string              `json:"user"`
+	Time  time.Time           `json:"time"`
+	Error string              `json:"error"`
+	Items []search.LiquorItem `json:"items"`
+}
+
+// deadLetterPath returns the configured dead-letter file, defaulting to one in the state dir
+func deadLetterPath(cfg config.Config) string {
+	if cfg.DeadLetterFile != "" {
+		return cfg.DeadLetterFile
+	}
+	return filepath.Join(cfg.StateDir, defaultDeadLetterFile)
+}
+
+// handleUndelivered keeps found items that no notifier delivered from being
+// lost silently, according to on_notify_failure
+func (ur *userRunner) handleUndelivered(items []search.LiquorItem, sendErr error, now time.Time) {
+	switch ur.onNotifyFailure {
+	case failureFile:
+		entry := deadLetter{User: ur.userConfig.Name, Time: now, Error: sendErr.Error(), Items: items}
+		if err := appendDeadLetter(ur.deadLetterFile, entry); err != nil {
+			ur.log.Errorf("Lost %d undelivered found items: %v", len(items), err)
+			return
+		}
+		ur.log.Warnf("Wrote %d undelivered found items to %s", len(items), ur.deadLetterFile)
+	case failureRetry:
+		ur.requeue(items)
+		ur.log.Warnf("Will notify of %d undelivered found items again next cycle if still in stock", len(items))
+	default:
+		ur.log.Errorf("No notification delivered %d found items", len(items))
+	}
+}
+
+// requeue forgets that items were seen or notified, so the next cycle treats
+// them as new finds
+func (ur *userRunner) requeue(items []search.LiquorItem) {
+	if ur.prices != nil {
+		for _, item := range items {
+			ur.prices.Forget(item)
+		}
+		if err := ur.prices.Save(); err != nil {
+			ur.log.Errorf("Failed to save price history: %v", err)
+		}
+	}
+	for _, item := range items {
+		delete(ur.notified, notifiedKey(item))
+	}
+	ur.lastResults = ""
+}
+
+// appendDeadLetter appends entry to path as a JSON line, creating the file and its directory if needed
+func appendDeadLetter(path string, entry deadLetter) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode undelivered items: %w", err)
+	}
+
+	deadLetterMu.Lock()
+	defer deadLetterMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // #nosec G304 -- path is from config
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/toozej/go-find-liquor/internal/notification"
+)
+
+// failingNotifier fails every found item notification while failing is set,
+// counting the found notifications it delivered
+type failingNotifier struct {
+	failing   atomic.Bool
+	delivered atomic.Int32
+}
+
+func (f *failingNotifier) Notify(ctx context.Context, subject, message string) error {
+	if !strings.Contains(subject, "GFL - Found") {
+		return nil
+	}
+	if f.failing.Load() {
+		return errors.New("service unavailable")
+	}
+	f.delivered.Add(1)
+	return nil
+}
+
+// newUndeliveredRunner creates a fixture runner with price history whose only
+// notifier fails until told otherwise
+func newUndeliveredRunner(t *testing.T, onNotifyFailure string) (*userRunner, *failingNotifier) {
+	t.Helper()
+	ur, _ := newFixtureRunner(t, "search_results.html")
+
+	notifier := &failingNotifier{}
+	notifier.failing.Store(true)
+	manager, err := notification.NewNotificationManager(nil, notification.WithNotifiers(notifier))
+	if err != nil {
+		t.Fatalf("Failed to create notification manager: %v", err)
+	}
+	ur.notifier = manager
+
+	prices, err := openPriceHistory(t.TempDir(), ur.userConfig.Name, nil)
+	if err != nil {
+		t.Fatalf("Failed to open price history: %v", err)
+	}
+	ur.prices = prices
+	ur.onNotifyFailure = onNotifyFailure
+	return ur, notifier
+}
+
+// runRecoveringCycles runs a cycle whose notifications fail, then one where
+// they succeed, returning the found notifications delivered in the second
+func runRecoveringCycles(t *testing.T, ur *userRunner, notifier *failingNotifier) int32 {
+	t.Helper()
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	notifier.failing.Store(false)
+	if err := ur.runOnce(context.Background()); err != nil {
+		t.Fatalf("runOnce failed: %v", err)
+	}
+	return notifier.delivered.Load()
+}
+
+func TestUndelivered_Log(t *testing.T) {
+	ur, notifier := newUndeliveredRunner(t, failureLog)
+
+	// The price history already recorded the finds, so they aren't new next cycle
+	if got := runRecoveringCycles(t, ur, notifier); got != 0 {
+		t.Errorf("Expected undelivered finds not to be retried, got %d notifications", got)
+	}
+}
+
+func TestUndelivered_RetryNextCycle(t *testing.T) {
+	ur, notifier := newUndeliveredRunner(t, failureRetry)
+
+	// The fixture has 2 stores in stock
+	if got := runRecoveringCycles(t, ur, notifier); got != 2 {
+		t.Errorf("Expected the 2 undelivered finds to be notified next cycle, got %d", got)
+	}
+}
+
+func TestUndelivered_File(t *testing.T) {
+	ur, notifier := newUndeliveredRunner(t, failureFile)
+	ur.deadLetterFile = filepath.Join(t.TempDir(), "dead", "undelivered.jsonl")
+
+	// Without price history every cycle notifies, so each failing cycle appends a line
+	ur.prices = nil
+	for i := 0; i < 2; i++ {
+		if err := ur.runOnce(context.Background()); err != nil {
+			t.Fatalf("runOnce failed: %v", err)
+		}
+	}
+	if notifier.delivered.Load() != 0 {
+		t.Fatal("Expected every found notification to fail")
+	}
+
+	f, err := os.Open(ur.deadLetterFile)
+	if err != nil {
+		t.Fatalf("Expected dead-letter file to be written: %v", err)
+	}
+	defer f.Close()
+
+	var entries []deadLetter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid dead-letter line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 dead-letter entries, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.User != "user1" || len(entry.Items) == 0 || !strings.Contains(entry.Error, "service unavailable") {
+		t.Errorf("Expected user, items and error in dead-letter entry, got %+v", entry)
+	}
+}
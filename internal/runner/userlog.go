@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+const (
+	// defaultUserLogDir is used for per-user log files when no directory is configured
+	defaultUserLogDir = "logs"
+	// userLogMaxSize is the size in bytes at which a per-user log file is rotated
+	userLogMaxSize = 10 * 1024 * 1024
+)
+
+// rotatingFile is an io.Writer that appends to a file and rotates it to
+// "<path>.1" once it grows beyond maxSize, keeping a single backup
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+// newRotatingFile opens (or creates) the file at path for appending
+func newRotatingFile(path string, maxSize int64) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	r := &rotatingFile{path: path, maxSize: maxSize}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the underlying file and records its current size
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", r.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file %s: %w", r.path, err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p to the file, rotating first if p would exceed maxSize
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate moves the current file to "<path>.1" and starts a new one
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file %s: %w", r.path, err)
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file %s: %w", r.path, err)
+	}
+	return r.open()
+}
+
+// Close closes the underlying file. Later writes fail with os.ErrClosed.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// userLogFileName returns a filesystem-safe log file name for a user
+func userLogFileName(userName string) string {
+	return safeFileName(userName) + ".log"
+}
+
+// safeFileName replaces characters that aren't safe in a file name
+func safeFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return strings.TrimLeft(safe, ".")
+}
+
+// checkFileNames returns an error if two users' names give the same file
+// name, as they would then write to the same log file and price history
+func checkFileNames(users []config.UserConfig) error {
+	seen := make(map[string]string, len(users))
+	for _, user := range users {
+		name := safeFileName(user.Name)
+		if other, ok := seen[name]; ok && other != user.Name {
+			return fmt.Errorf("users '%s' and '%s' would share the file name '%s', rename one of them", other, user.Name, name)
+		}
+		seen[name] = user.Name
+	}
+	return nil
+}
+
+// newUserLogger creates a logger writing to <dir>/<user>.log for auditing a
+// user's finds. The returned closer closes the log file.
+func newUserLogger(dir, userName string) (*log.Logger, io.Closer, error) {
+	if dir == "" {
+		dir = defaultUserLogDir
+	}
+
+	file, err := newRotatingFile(filepath.Join(dir, userLogFileName(userName)), userLogMaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger := log.New()
+	logger.SetOutput(file)
+	logger.SetFormatter(&log.TextFormatter{DisableColors: true, FullTimestamp: true})
+	return logger, file, nil
+}
+
+// logFinds records found items to the user's log file, if per-user logging is enabled
+func (ur *userRunner) logFinds(items []search.LiquorItem) {
+	if ur.findLog == nil {
+		return
+	}
+
+	for _, item := range items {
+		ur.findLog.WithFields(log.Fields{
+			"user":  ur.userConfig.Name,
+			"item":  item.Name,
+			"code":  item.Code,
+			"store": item.Store,
+			"price": item.Price,
+		}).Info("Found item")
+	}
+}
@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toozej/go-find-liquor/internal/search"
+	"github.com/toozej/go-find-liquor/pkg/config"
+)
+
+// TestRunner_PerUserLogs tests that each user's finds are written to their own log file
+func TestRunner_PerUserLogs(t *testing.T) {
+	logDir := t.TempDir()
+	cfg := config.Config{
+		Interval:      time.Hour,
+		UserAgent:     "test-agent",
+		PerUserLogs:   true,
+		PerUserLogDir: logDir,
+		Users: []config.UserConfig{
+			{Name: "alice", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10},
+			{Name: "bob", Items: config.NewItems("Weller"), Zipcode: "97210", Distance: 15},
+		},
+	}
+
+	r, err := NewRunner(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	sr := r.(*SearchRunner)
+
+	sr.userRunners["alice"].logFinds([]search.LiquorItem{
+		{Name: "BLANTON'S", Code: "0171B", Store: "1001 - Portland", Price: "$64.95"},
+	})
+	sr.userRunners["bob"].logFinds([]search.LiquorItem{
+		{Name: "WELLER SPECIAL RESERVE", Code: "0223B", Store: "1003 - Gresham", Price: "$29.95"},
+	})
+
+	alice, err := os.ReadFile(filepath.Join(logDir, "alice.log"))
+	if err != nil {
+		t.Fatalf("Failed to read alice's log: %v", err)
+	}
+	bob, err := os.ReadFile(filepath.Join(logDir, "bob.log"))
+	if err != nil {
+		t.Fatalf("Failed to read bob's log: %v", err)
+	}
+
+	if !strings.Contains(string(alice), "BLANTON'S") || strings.Contains(string(alice), "WELLER") {
+		t.Errorf("Expected alice's log to contain only her finds, got: %s", alice)
+	}
+	if !strings.Contains(string(bob), "WELLER") || strings.Contains(string(bob), "BLANTON'S") {
+		t.Errorf("Expected bob's log to contain only his finds, got: %s", bob)
+	}
+}
+
+func TestRotatingFile_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.log")
+	f, err := newRotatingFile(path, 16)
+	if err != nil {
+		t.Fatalf("Failed to create rotating file: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("first line....\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := f.Write([]byte("second line...\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("Expected rotated backup file: %v", err)
+	}
+	if string(backup) != "first line....\n" {
+		t.Errorf("Expected backup to hold the first line, got %q", backup)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read current log: %v", err)
+	}
+	if string(current) != "second line...\n" {
+		t.Errorf("Expected current log to hold the second line, got %q", current)
+	}
+}
+
+func TestUserLogFileName(t *testing.T) {
+	tests := map[string]string{
+		"alice":         "alice.log",
+		"../etc/passwd": "_etc_passwd.log",
+		"bob smith":     "bob_smith.log",
+	}
+
+	for in, want := range tests {
+		if got := userLogFileName(in); got != want {
+			t.Errorf("userLogFileName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestRunner_UserLogClosed tests that a user's log file is closed when the
+// user is removed, and handed to the new runner when the user is replaced
+func TestRunner_UserLogClosed(t *testing.T) {
+	cfg := config.Config{
+		Interval:      time.Hour,
+		UserAgent:     "test-agent",
+		PerUserLogs:   true,
+		PerUserLogDir: t.TempDir(),
+		Users: []config.UserConfig{
+			{Name: "alice", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10},
+			{Name: "bob", Items: config.NewItems("Weller"), Zipcode: "97210", Distance: 15},
+		},
+	}
+
+	r, err := NewRunner(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create Runner: %v", err)
+	}
+	sr := r.(*SearchRunner)
+	alice := sr.userRunners["alice"].logFile.(*rotatingFile)
+	bob := sr.userRunners["bob"].logFile.(*rotatingFile)
+
+	if err := sr.RemoveUser("alice"); err != nil {
+		t.Fatalf("RemoveUser failed: %v", err)
+	}
+	if _, err := alice.Write([]byte("after removal\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected a removed user's log file to be closed, got: %v", err)
+	}
+
+	bobConfig := cfg.Users[1]
+	bobConfig.Distance = 25
+	if err := sr.UpdateUser(bobConfig); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	// The replacement takes over the log file rather than opening it again
+	if sr.userRunners["bob"].logFile != bob {
+		t.Error("Expected the replacement runner to take over the log file")
+	}
+	if _, err := bob.Write([]byte("after update\n")); err != nil {
+		t.Errorf("Expected the taken over log file to be open, got: %v", err)
+	}
+
+	sr.closeUsers()
+	if _, err := bob.Write([]byte("after close\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected closing the runner to close the log file, got: %v", err)
+	}
+}
+
+func TestNewRunner_CollidingUserFileNames(t *testing.T) {
+	cfg := config.Config{
+		Interval:      time.Hour,
+		UserAgent:     "test-agent",
+		PerUserLogs:   true,
+		PerUserLogDir: t.TempDir(),
+		Users: []config.UserConfig{
+			{Name: "a/b", Items: config.NewItems("Blanton's"), Zipcode: "97201", Distance: 10},
+			{Name: "a_b", Items: config.NewItems("Weller"), Zipcode: "97210", Distance: 15},
+		},
+	}
+
+	_, err := NewRunner(cfg)
+	if err == nil || !strings.Contains(err.Error(), "would share the file name 'a_b'") {
+		t.Fatalf("Expected users sharing a log file to be rejected, got: %v", err)
+	}
+
+	// Without per-user files the names don't collide
+	cfg.PerUserLogs = false
+	if _, err := NewRunner(cfg); err != nil {
+		t.Errorf("Expected users to be accepted without per-user files, got: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package runner
+
+import "context"
+
+// recordCycle counts consecutive search cycles without any finds, sending a
+// watch list advisory once the count reaches the configured threshold
+func (ur *userRunner) recordCycle(ctx context.Context, found bool) {
+	if found {
+		ur.zeroCycles = 0
+		return
+	}
+
+	ur.zeroCycles++
+	if ur.zeroAlert <= 0 || ur.zeroCycles != ur.zeroAlert {
+		return
+	}
+
+	notifyCtx, cancel, ok := ur.notifyContext(ctx)
+	defer cancel()
+	if !ok {
+		return
+	}
+
+	if err := ur.notifier.NotifyZeroFinds(notifyCtx, ur.zeroCycles, ur.userConfig.ItemNames()); err != nil {
+		ur.log.Warnf("Failed to send watch list advisory: %v", err)
+	}
+}
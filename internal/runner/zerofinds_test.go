@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestRunner_ZeroFindAdvisory tests that the watch list advisory fires at the threshold and resets on a find
+func TestRunner_ZeroFindAdvisory(t *testing.T) {
+	ur, recorder := newDrySpellRunner(t, 0)
+	ur.zeroAlert = 3
+	ctx := context.Background()
+
+	ur.recordCycle(ctx, false)
+	ur.recordCycle(ctx, false)
+	if len(recorder.sent) != 0 {
+		t.Fatalf("Expected no advisory before the threshold, got %d", len(recorder.sent))
+	}
+
+	ur.recordCycle(ctx, false)
+	if len(recorder.sent) != 1 {
+		t.Fatalf("Expected 1 advisory at the threshold, got %d", len(recorder.sent))
+	}
+	if !strings.Contains(recorder.sent[0], "3 searches in a row") || !strings.Contains(recorder.sent[0], "Blanton's") {
+		t.Errorf("Expected advisory with cycle count and watch list, got: %s", recorder.sent[0])
+	}
+
+	ur.recordCycle(ctx, false)
+	if len(recorder.sent) != 1 {
+		t.Errorf("Expected the advisory to fire only once per streak, got %d", len(recorder.sent))
+	}
+
+	ur.recordCycle(ctx, true)
+	if ur.zeroCycles != 0 {
+		t.Errorf("Expected a find to reset the zero cycle count, got %d", ur.zeroCycles)
+	}
+	for i := 0; i < 3; i++ {
+		ur.recordCycle(ctx, false)
+	}
+	if len(recorder.sent) != 2 {
+		t.Errorf("Expected the advisory to fire again after a reset streak, got %d", len(recorder.sent))
+	}
+}
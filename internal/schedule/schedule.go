@@ -0,0 +1,39 @@
+// Package schedule parses standard five-field cron expressions and computes
+// when they next fire.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	spec     string
+	schedule cron.Schedule
+}
+
+// Parse parses a standard five-field cron expression such as "0 8,18 * * *"
+// (minute, hour, day of month, month, day of week) or a descriptor such as
+// "@daily". As in cron, when both day fields are restricted a day matching
+// either one fires.
+func Parse(spec string) (*Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", spec, err)
+	}
+	return &Schedule{spec: spec, schedule: schedule}, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Next returns the first time after t that the schedule fires, in t's location,
+// or the zero time if it never fires within five years
+func (s *Schedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t)
+}
@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"0 8 * *",
+		"0 8 * * * *",
+		"60 8 * * *",
+		"0 24 * * *",
+		"0 8 0 * *",
+		"0 8 * 13 *",
+		"0 8 * * 7",
+		"0 8-6 * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"0 8,x * * *",
+	}
+
+	for _, spec := range tests {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Expected an error parsing %q", spec)
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatalf("Failed to parse time %q: %v", s, err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		spec     string
+		from     string
+		expected string
+	}{
+		{spec: "0 8,18 * * *", from: "2024-05-01 07:30", expected: "2024-05-01 08:00"},
+		{spec: "0 8,18 * * *", from: "2024-05-01 08:00", expected: "2024-05-01 18:00"},
+		{spec: "0 8,18 * * *", from: "2024-05-01 19:00", expected: "2024-05-02 08:00"},
+		{spec: "*/15 * * * *", from: "2024-05-01 10:07", expected: "2024-05-01 10:15"},
+		{spec: "5/20 * * * *", from: "2024-05-01 10:30", expected: "2024-05-01 10:45"},
+		{spec: "30 9 * * 1-5", from: "2024-05-03 10:00", expected: "2024-05-06 09:30"}, // Friday to Monday
+		{spec: "0 12 * * 0", from: "2024-05-01 00:00", expected: "2024-05-05 12:00"},   // 0 is Sunday
+		{spec: "@daily", from: "2024-05-01 10:00", expected: "2024-05-02 00:00"},
+		{spec: "0 0 1 * *", from: "2024-12-15 00:00", expected: "2025-01-01 00:00"},
+		{spec: "0 0 29 2 *", from: "2024-03-01 00:00", expected: "2028-02-29 00:00"},
+		// With both day fields restricted, either one matches
+		{spec: "0 0 15 * 1", from: "2024-05-01 00:00", expected: "2024-05-06 00:00"},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.spec, err)
+		}
+		if got := s.Next(at(tt.from)); !got.Equal(at(tt.expected)) {
+			t.Errorf("%q after %s: expected %s, got %s", tt.spec, tt.from, tt.expected, got.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Expected no next time for February 31st, got %s", got)
+	}
+}
+
+func TestSchedule_NextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+	s, err := Parse("0 8 * * *")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	got := s.Next(time.Date(2024, 5, 1, 9, 0, 0, 0, loc))
+	expected := time.Date(2024, 5, 2, 8, 0, 0, 0, loc)
+	if !got.Equal(expected) {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCircuitBreakerCooldown is how long searches pause the first time the
+// circuit breaker opens
+const DefaultCircuitBreakerCooldown = 5 * time.Minute
+
+// maxCooldownDoublings caps how many times the cool-down doubles while OLCC
+// keeps failing, so searches resume at most every 16 cool-downs
+const maxCooldownDoublings = 4
+
+// ErrCircuitOpen is returned instead of searching while the circuit breaker is
+// open after repeated failed searches
+var ErrCircuitOpen = errors.New("OLCC searches paused after repeated failures")
+
+// circuitBreaker stops a Searcher from hammering OLCC while it keeps failing.
+// After threshold consecutive failed searches it opens, and searches fail with
+// ErrCircuitOpen until the cool-down passes. One trial search is then let
+// through while the others keep failing: a success closes the breaker, while a
+// failure opens it again for twice as long.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	opens     int
+	openUntil time.Time
+	// trial is set while the search let through after the cool-down is running
+	trial bool
+}
+
+// WithCircuitBreaker pauses searches for cooldown after threshold consecutive
+// failures, doubling the pause each time searches keep failing. A threshold of
+// zero or less disables the breaker, and a cooldown of zero or less uses
+// DefaultCircuitBreakerCooldown.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) SearcherOption {
+	return func(s *Searcher) {
+		if threshold <= 0 {
+			s.breaker = nil
+			return
+		}
+		if cooldown <= 0 {
+			cooldown = DefaultCircuitBreakerCooldown
+		}
+		s.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+// allow returns ErrCircuitOpen, wrapped with when searches resume, while the
+// breaker is open or another search is its trial
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.openUntil) {
+		return fmt.Errorf("%w, retrying after %s", ErrCircuitOpen, b.openUntil.Format(time.Kitchen))
+	}
+	if b.opens > 0 {
+		if b.trial {
+			return fmt.Errorf("%w, a trial search is in progress", ErrCircuitOpen)
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// abort ends a search that was cancelled without recording its outcome, so a
+// cancelled trial lets the next search try again
+func (b *circuitBreaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// record notes the outcome of a search, opening or closing the breaker
+func (b *circuitBreaker) record(now time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if err == nil {
+		if b.opens > 0 {
+			logger.Infof("OLCC search succeeded, resuming searches")
+		}
+		b.failures = 0
+		b.opens = 0
+		b.openUntil = time.Time{}
+		return
+	}
+
+	b.failures++
+	// Once the breaker has opened, a failed trial search opens it again
+	if b.opens == 0 && b.failures < b.threshold {
+		return
+	}
+	cooldown := b.cooldown << min(b.opens, maxCooldownDoublings)
+	b.opens++
+	b.openUntil = now.Add(cooldown)
+	logger.Warnf("%d consecutive OLCC searches failed (last: %v), pausing searches for %s", b.failures, err, cooldown)
+}
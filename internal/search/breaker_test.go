@@ -0,0 +1,145 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	product, err := os.ReadFile(filepath.Join("testdata", "product.html"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	// OLCC fails searches until healthy is set
+	var healthy atomic.Bool
+	var searches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+searchPath {
+			return
+		}
+		searches.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(product)
+	}))
+	t.Cleanup(server.Close)
+
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	s := NewSearcher("test-agent", WithBaseURL(server.URL), WithClock(func() time.Time { return now }),
+		WithCircuitBreaker(3, time.Minute))
+
+	searchItem := func() error {
+		t.Helper()
+		_, err := s.SearchItem(context.Background(), "Blanton's", "97201", 10)
+		return err
+	}
+
+	for i := range 3 {
+		if err := searchItem(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected search %d to fail with the server error, got: %v", i+1, err)
+		}
+	}
+
+	// Open: searches are skipped without reaching OLCC
+	if err := searchItem(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after 3 failures, got: %v", err)
+	}
+	if got := searches.Load(); got != 3 {
+		t.Errorf("Expected no search while the breaker is open, got %d searches", got)
+	}
+
+	// A failed trial search after the cool-down opens it again for twice as long
+	now = now.Add(time.Minute)
+	if err := searchItem(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the trial search to reach the server, got: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := searchItem(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the breaker to stay open for the doubled cool-down, got: %v", err)
+	}
+	if got := searches.Load(); got != 4 {
+		t.Errorf("Expected only the trial search while open, got %d searches", got)
+	}
+
+	// A successful trial closes it, resetting the failure count
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	if err := searchItem(); err != nil {
+		t.Fatalf("Expected the trial search to succeed, got: %v", err)
+	}
+	healthy.Store(false)
+	for i := range 2 {
+		if err := searchItem(); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected search %d after closing to reach the server, got: %v", i+1, err)
+		}
+	}
+	if got := searches.Load(); got != 7 {
+		t.Errorf("Expected 7 searches, got %d", got)
+	}
+}
+
+// TestCircuitBreaker_SingleTrial tests that only one search is let through
+// after the cool-down, however many arrive while it runs
+func TestCircuitBreaker_SingleTrial(t *testing.T) {
+	failed := errors.New("server error")
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	b := &circuitBreaker{threshold: 1, cooldown: time.Minute}
+	b.record(now, failed)
+
+	now = now.Add(time.Minute)
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if b.allow(now) == nil {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	if got := allowed.Load(); got != 1 {
+		t.Fatalf("Expected 1 trial search after the cool-down, got %d", got)
+	}
+
+	// A cancelled trial lets the next search try again
+	b.abort()
+	if err := b.allow(now); err != nil {
+		t.Fatalf("Expected a new trial after the last was cancelled, got: %v", err)
+	}
+	if err := b.allow(now); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen while the trial runs, got: %v", err)
+	}
+
+	// Once the trial succeeds, every search is let through
+	b.record(now, nil)
+	for range 3 {
+		if err := b.allow(now); err != nil {
+			t.Fatalf("Expected searches after a successful trial, got: %v", err)
+		}
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	s := NewSearcher("test-agent", WithBaseURL(server.URL), WithCircuitBreaker(0, time.Minute))
+
+	for range 5 {
+		if _, err := s.SearchItem(context.Background(), "Blanton's", "97201", 10); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("Expected a disabled breaker never to open")
+		}
+	}
+}
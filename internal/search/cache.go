@@ -0,0 +1,76 @@
+package search
+
+import (
+	"sync"
+	"time"
+)
+
+// ResultCache holds recent search results so identical searches by several
+// users within the TTL are only scraped once. A single cache is shared by
+// every Searcher it is passed to, and is safe for concurrent use.
+type ResultCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+// cacheKey identifies a search by what is sent to OLCC
+type cacheKey struct {
+	item     string
+	zipcode  string
+	distance int
+}
+
+// cacheEntry is a cached search result and when it expires
+type cacheEntry struct {
+	items   []LiquorItem
+	expires time.Time
+}
+
+// NewResultCache creates a cache keeping results for ttl.
+// It returns nil (no caching) when ttl is zero or negative.
+func NewResultCache(ttl time.Duration) *ResultCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &ResultCache{ttl: ttl, now: time.Now, entries: make(map[cacheKey]cacheEntry)}
+}
+
+// WithResultCache makes the Searcher answer searches from cache while they are
+// fresh, and cache the results of the searches it performs
+func WithResultCache(cache *ResultCache) SearcherOption {
+	return func(s *Searcher) {
+		s.cache = cache
+	}
+}
+
+// get returns a copy of the cached results of a search, and false on a miss
+func (c *ResultCache) get(key cacheKey) ([]LiquorItem, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return append([]LiquorItem(nil), entry.items...), true
+}
+
+// put caches a copy of the results of a search, dropping expired entries
+func (c *ResultCache) put(key cacheKey, items []LiquorItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{items: append([]LiquorItem(nil), items...), expires: now.Add(c.ttl)}
+}
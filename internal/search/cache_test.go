@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResultCache(t *testing.T) {
+	server, terms := newFixtureSearchServer(t, "")
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	cache := NewResultCache(10 * time.Minute)
+	cache.now = func() time.Time { return now }
+
+	// Two searchers sharing one cache, as user runners do
+	first := NewSearcher("test-agent", WithBaseURL(server.URL), WithResultCache(cache))
+	second := NewSearcher("test-agent", WithBaseURL(server.URL), WithResultCache(cache))
+
+	search := func(s *Searcher, item, zipcode string) []LiquorItem {
+		t.Helper()
+		results, err := s.SearchItem(context.Background(), item, zipcode, 10)
+		if err != nil {
+			t.Fatalf("SearchItem failed: %v", err)
+		}
+		return results
+	}
+
+	scraped := search(first, "99900014675", "97201")
+	if len(scraped) == 0 {
+		t.Fatal("Expected results from the fixture")
+	}
+
+	// Hit: the same search by another user isn't scraped again
+	cached := search(second, "99900014675", "97201")
+	if len(*terms) != 1 {
+		t.Errorf("Expected a cached search not to be scraped, got searches %v", *terms)
+	}
+	if len(cached) != len(scraped) {
+		t.Errorf("Expected %d cached results, got %d", len(scraped), len(cached))
+	}
+
+	// The cache hands out copies, so one user's changes don't leak to another
+	cached[0].Note = "changed"
+	if again := search(first, "99900014675", "97201"); again[0].Note != "" {
+		t.Error("Expected cached results to be copied")
+	}
+
+	// Miss: a different zipcode is a different search
+	search(second, "99900014675", "97210")
+	if len(*terms) != 2 {
+		t.Errorf("Expected a search near another zipcode to be scraped, got searches %v", *terms)
+	}
+
+	// Expiry: the search is scraped again once the TTL has passed
+	now = now.Add(10 * time.Minute)
+	search(first, "99900014675", "97201")
+	if len(*terms) != 3 {
+		t.Errorf("Expected an expired search to be scraped again, got searches %v", *terms)
+	}
+}
+
+func TestNewResultCache_Disabled(t *testing.T) {
+	if NewResultCache(0) != nil {
+		t.Error("Expected a zero TTL to disable caching")
+	}
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// redacted replaces secret values in logged requests
+const redacted = "REDACTED"
+
+// sensitiveHeaders are request headers whose values are never logged
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+	"X-Auth-Token":        true,
+}
+
+// sensitiveParams are URL query parameters whose values are never logged
+var sensitiveParams = []string{"token", "key", "secret", "password", "auth", "signature"}
+
+// WithHTTPLogging logs the method, URL, headers, response status and timing of
+// every HTTP request issued by the Searcher, with secrets redacted
+func WithHTTPLogging(enabled bool) SearcherOption {
+	return func(s *Searcher) {
+		if !enabled {
+			return
+		}
+		next := s.client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		s.client.Transport = &loggingTransport{next: next, logger: logger}
+	}
+}
+
+// loggingTransport is an http.RoundTripper that logs each request and its outcome
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *log.Entry
+}
+
+// RoundTrip performs the request and logs it
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	entry := t.logger.WithFields(log.Fields{
+		"method":   req.Method,
+		"url":      redactURL(req.URL),
+		"headers":  redactHeaders(req.Header),
+		"duration": time.Since(start).Round(time.Millisecond),
+	})
+	if err != nil {
+		entry.WithError(err).Info("HTTP request failed")
+		return nil, err
+	}
+
+	entry.WithField("status", resp.StatusCode).Info("HTTP request")
+	return resp, nil
+}
+
+// redactURL returns u as a string with user info and sensitive query parameter values redacted
+func redactURL(u *url.URL) string {
+	clean := *u
+	if clean.User != nil {
+		clean.User = url.User(redacted)
+	}
+
+	query := clean.Query()
+	changed := false
+	for name := range query {
+		if isSensitiveParam(name) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
+
+// isSensitiveParam reports whether a query parameter name looks like it holds a secret
+func isSensitiveParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveParams {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders formats headers as "Name: value" pairs sorted by name, with sensitive values redacted
+func redactHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header.Values(name), ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		pairs = append(pairs, name+": "+value)
+	}
+	return strings.Join(pairs, "; ")
+}
@@ -0,0 +1,56 @@
+package search
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	testLogger := log.New()
+	testLogger.SetOutput(&buf)
+
+	s := NewSearcher("test-agent", WithHTTPLogging(true))
+	s.client.Transport.(*loggingTransport).logger = log.NewEntry(testLogger)
+
+	req, err := http.NewRequest("GET", server.URL+"/message?token=s3cret&item=blantons", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Cookie", "JSESSIONID=s3cret")
+	req.Header.Set("User-Agent", "test-agent")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	out := buf.String()
+	for _, want := range []string{"method=GET", "status=418", "duration=", "item=blantons", "User-Agent: test-agent", "Authorization: REDACTED", "token=REDACTED"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected log to contain %q, got: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("Expected secrets to be redacted, got: %s", out)
+	}
+}
+
+func TestWithHTTPLogging_Disabled(t *testing.T) {
+	s := NewSearcher("test-agent", WithHTTPLogging(false))
+	if s.client.Transport != nil {
+		t.Error("Expected default transport when HTTP logging is disabled")
+	}
+}
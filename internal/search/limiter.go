@@ -0,0 +1,92 @@
+package search
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ConnectionLimiter caps the number of simultaneous HTTP connections to OLCC.
+// A single limiter is shared by every Searcher it is passed to, so the cap
+// applies across all users.
+type ConnectionLimiter struct {
+	slots chan struct{}
+}
+
+// NewConnectionLimiter creates a limiter allowing at most max concurrent connections.
+// It returns nil (no limit) when max is zero or negative.
+func NewConnectionLimiter(max int) *ConnectionLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &ConnectionLimiter{slots: make(chan struct{}, max)}
+}
+
+// acquire waits for a free connection slot or for the context to be done
+func (l *ConnectionLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a connection slot
+func (l *ConnectionLimiter) release() {
+	<-l.slots
+}
+
+// WithConnectionLimiter makes every HTTP request issued by the Searcher hold a
+// slot from limiter until its response body is closed
+func WithConnectionLimiter(limiter *ConnectionLimiter) SearcherOption {
+	return func(s *Searcher) {
+		if limiter == nil {
+			return
+		}
+		next := s.client.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		s.client.Transport = &limitedTransport{limiter: limiter, next: next}
+	}
+}
+
+// limitedTransport is an http.RoundTripper that holds a ConnectionLimiter slot
+// for the lifetime of each request
+type limitedTransport struct {
+	limiter *ConnectionLimiter
+	next    http.RoundTripper
+}
+
+// RoundTrip acquires a slot, performs the request, and releases the slot once
+// the response body is closed (or immediately if the request fails)
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.limiter.release()
+		return nil, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.limiter.release}
+	return resp, nil
+}
+
+// releasingBody releases a connection slot exactly once when closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close closes the underlying body and releases the connection slot
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
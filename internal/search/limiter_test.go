@@ -0,0 +1,110 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnectionLimiter_CapsInFlightRequests(t *testing.T) {
+	const maxConnections = 2
+	var inFlight, peak int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	limiter := NewConnectionLimiter(maxConnections)
+	// Two searchers sharing one limiter, as user runners do
+	searchers := []*Searcher{
+		NewSearcher("test-agent", WithConnectionLimiter(limiter)),
+		NewSearcher("test-agent", WithConnectionLimiter(limiter)),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(s *Searcher) {
+			defer wg.Done()
+			resp, err := s.client.Get(server.URL)
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}(searchers[i%len(searchers)])
+	}
+	wg.Wait()
+
+	if peak > maxConnections {
+		t.Errorf("Expected at most %d concurrent requests, observed %d", maxConnections, peak)
+	}
+	if peak == 0 {
+		t.Error("Expected requests to reach the server")
+	}
+}
+
+func TestConnectionLimiter_RespectsContextWhileWaiting(t *testing.T) {
+	limiter := NewConnectionLimiter(1)
+	if err := limiter.acquire(context.Background()); err != nil {
+		t.Fatalf("Failed to acquire free slot: %v", err)
+	}
+	defer limiter.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded while waiting for a slot, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected acquire to return promptly on cancellation, took %s", elapsed)
+	}
+}
+
+// TestConnectionLimiter_SearchWithOneSlot tests that a search, including its
+// age verification requests, completes when only one connection is allowed
+func TestConnectionLimiter_SearchWithOneSlot(t *testing.T) {
+	server, _ := newFixtureSearchServer(t, "")
+	s := NewSearcher("test-agent", WithBaseURL(server.URL), WithConnectionLimiter(NewConnectionLimiter(1)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := s.SearchItem(ctx, "Blanton's", "97201", 10)
+	if err != nil {
+		t.Fatalf("Expected the search to finish with one connection slot, got: %v", err)
+	}
+	if len(results) == 0 {
+		t.Error("Expected results from the search")
+	}
+}
+
+func TestNewConnectionLimiter_Unlimited(t *testing.T) {
+	if NewConnectionLimiter(0) != nil {
+		t.Error("Expected nil limiter for zero max connections")
+	}
+
+	s := NewSearcher("test-agent", WithConnectionLimiter(nil))
+	if s.client.Transport != nil {
+		t.Error("Expected default transport when no limiter is configured")
+	}
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Modes for handling a search that matches several products
+const (
+	// AmbiguousAll searches every matched product
+	AmbiguousAll = "all"
+	// AmbiguousExact only searches matched products whose name equals the search term
+	AmbiguousExact = "exact"
+)
+
+// DefaultMaxListProducts is how many products listed on a multi-match results
+// page are searched unless set with WithMaxListProducts
+const DefaultMaxListProducts = 10
+
+// ProductMatch is one product listed on a multi-match search results page
+type ProductMatch struct {
+	Code string
+	Name string
+}
+
+// WithAmbiguousResults sets how a search matching several products is handled:
+// AmbiguousAll (default) or AmbiguousExact
+func WithAmbiguousResults(mode string) SearcherOption {
+	return func(s *Searcher) {
+		if mode != "" {
+			s.ambiguous = mode
+		}
+	}
+}
+
+// WithMaxListProducts sets how many products listed on a multi-match results
+// page are searched, so a generic term like "bourbon" can't trigger dozens of
+// searches. Zero or less keeps DefaultMaxListProducts.
+func WithMaxListProducts(limit int) SearcherOption {
+	return func(s *Searcher) {
+		if limit > 0 {
+			s.maxListProducts = limit
+		}
+	}
+}
+
+// isProductListPage reports whether the document is a list of matching products
+// rather than a single product's details page
+func isProductListPage(doc *goquery.Document) bool {
+	return doc.Find("#product-desc").Length() == 0 && len(extractProductMatches(doc)) > 0
+}
+
+// extractProductMatches extracts the products listed on a multi-match results page,
+// locating the item code and description columns by their headers
+func extractProductMatches(doc *goquery.Document) []ProductMatch {
+	codeCol, nameCol := -1, -1
+	doc.Find("table.list tr").First().Find("th").Each(func(i int, th *goquery.Selection) {
+		switch strings.ToLower(strings.Join(strings.Fields(th.Text()), " ")) {
+		case "item code":
+			codeCol = i
+		case "description":
+			nameCol = i
+		}
+	})
+	if codeCol == -1 || nameCol == -1 {
+		return nil
+	}
+
+	var matches []ProductMatch
+	doc.Find("table.list tr.row, table.list tr.alt-row").Each(func(i int, row *goquery.Selection) {
+		tds := row.Find("td")
+		code := strings.TrimSpace(tds.Eq(codeCol).Text())
+		name := strings.Join(strings.Fields(tds.Eq(nameCol).Text()), " ")
+		if code != "" {
+			matches = append(matches, ProductMatch{Code: code, Name: name})
+		}
+	})
+	return matches
+}
+
+// filterMatches returns the matches to search for item according to mode
+func filterMatches(matches []ProductMatch, item string, mode string) []ProductMatch {
+	if mode != AmbiguousExact {
+		return matches
+	}
+
+	var exact []ProductMatch
+	for _, match := range matches {
+		if strings.EqualFold(match.Name, strings.TrimSpace(item)) {
+			exact = append(exact, match)
+		}
+	}
+	return exact
+}
+
+// searchMatches searches each product listed on a multi-match results page by
+// its item code, returning the combined results
+func (s *Searcher) searchMatches(ctx context.Context, item string, list *goquery.Document, zipcode string, distance int) ([]LiquorItem, error) {
+	matches := filterMatches(extractProductMatches(list), item, s.ambiguous)
+	if len(matches) > s.maxListProducts {
+		logger.Warnf("Search for %s matched %d products, only searching the first %d", item, len(matches), s.maxListProducts)
+		matches = matches[:s.maxListProducts]
+	}
+	logger.Debugf("Search for %s matched several products, searching %d of them", item, len(matches))
+
+	var results []LiquorItem
+	for _, match := range matches {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		doc, err := s.fetchResults(ctx, match.Code, zipcode, distance)
+		if err != nil {
+			return results, err
+		}
+		if isProductListPage(doc) {
+			// An item code should identify one product; don't follow lists any deeper
+			logger.Warnf("Search for item code %s (%s) returned another product list, skipping", match.Code, match.Name)
+			continue
+		}
+
+		found, err := s.parseResults(doc)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, found...)
+	}
+	return results, nil
+}
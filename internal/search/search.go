@@ -3,24 +3,71 @@ package search
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	log "github.com/sirupsen/logrus"
+
+	"github.com/toozej/go-find-liquor/internal/logging"
+)
+
+// logger is the search component logger, whose level can be set separately
+var logger = logging.For(logging.Search)
+
+const (
+	// DefaultBaseURL is the OLCC liquor search site
+	DefaultBaseURL = "https://www.oregonliquorsearch.com/"
+	searchPath     = "servlet/FrontController"
+	ageBtnFormPath = "servlet/WelcomeController"
 )
 
+// UserAgent rotation modes, used when no fixed user agent is configured
 const (
-	baseURL       = "https://www.oregonliquorsearch.com/"
-	searchURL     = "https://www.oregonliquorsearch.com/servlet/FrontController"
-	ageBtnFormURL = "https://www.oregonliquorsearch.com/servlet/WelcomeController"
+	// RotatePerSearch picks a new user agent for every SearchItem call
+	RotatePerSearch = "per-search"
+	// RotatePerSession keeps one user agent until StartSession is called
+	RotatePerSession = "per-session"
+	// RotateOff keeps the user agent picked when the Searcher was created
+	RotateOff = "off"
 )
 
+// Item code forms reported in LiquorItem.Code. OLCC lists codes such as "99900014675(0146B)".
+const (
+	// CodeParenthesized reports the short code in parentheses, e.g. "0146B"
+	CodeParenthesized = "parenthesized"
+	// CodeFull reports the full numeric code, e.g. "99900014675"
+	CodeFull = "full"
+)
+
+// ErrSiteMaintenance is returned when OLCC serves its maintenance page instead of
+// search results, so callers can back off rather than treat it as "nothing found"
+var ErrSiteMaintenance = errors.New("OLCC site is under maintenance")
+
+// SupportedRadii are the search radii, in miles, offered by the OLCC search
+// form. Other distances may silently return no results, so SearchItem uses the
+// nearest of these.
+var SupportedRadii = []int{5, 10, 15, 25, 50, 100}
+
+// Statewide is the distance that searches every OLCC store in Oregon rather
+// than those within a radius of the zipcode. It is negative so that a distance
+// left at zero is searched within the nearest radius, not statewide.
+const Statewide = -1
+
+// DefaultMaintenanceMarkers are phrases found on the OLCC maintenance page.
+// Matching is case-insensitive against the page text.
+var DefaultMaintenanceMarkers = []string{
+	"down for scheduled maintenance",
+	"currently undergoing maintenance",
+	"site is temporarily unavailable",
+}
+
 // DefaultCommonItems are items that are typically always in stock at OLCC stores,
 // used as fallback for health check searches when none are configured.
 var DefaultCommonItems = []string{
@@ -54,20 +101,46 @@ var userAgents = []string{
 	"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/119.0",
 }
 
+// headerBundle holds request headers a browser sends alongside its user agent
+type headerBundle struct {
+	Accept         string
+	AcceptLanguage string
+}
+
+// Header bundles rotated together with the user agent when header rotation is enabled
+var headerBundles = []headerBundle{
+	{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8", AcceptLanguage: "en-US,en;q=0.9"},
+	{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", AcceptLanguage: "en-US,en;q=0.5"},
+	{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8", AcceptLanguage: "en-US,en;q=0.9,es;q=0.8"},
+	{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", AcceptLanguage: "en-GB,en;q=0.9,en-US;q=0.8"},
+	{Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8", AcceptLanguage: "en-US"},
+}
+
 // LiquorItem represents a found liquor item
 // with only the information we care about
 type LiquorItem struct {
-	Name  string
-	Code  string
-	Store string
-	Date  time.Time
-	Price string
+	Name     string
+	Code     string
+	Store    string
+	Date     time.Time
+	Price    string
+	Quantity int
+	// Size, Proof, Category and CasePrice are product details as listed by OLCC, e.g. "750 ML" and "80.0"
+	Size      string
+	Proof     string
+	Category  string
+	CasePrice string
+	// Query is the search term that found this item
+	Query string
+	// Note is the watched item's note from the config, shown in notifications
+	Note string
 }
 
 // ProductInfo represents all the possible information about a liquor item
 // including the information we don't really care about
 type ProductInfo struct {
 	ItemCode    string
+	FullCode    string
 	Name        string
 	BottlePrice string
 	CasePrice   string
@@ -78,13 +151,179 @@ type ProductInfo struct {
 
 // Searcher provides functionality to search for liquor items
 type Searcher struct {
-	client     *http.Client
-	userAgent  string
-	cycleAgent bool
+	client             *http.Client
+	userAgent          string
+	cycleAgent         bool
+	maintenanceMarkers []string
+	rotation           string
+	ambiguous          string
+	maxListProducts    int
+	// cache answers repeated searches within its TTL (nil = disabled)
+	cache    *ResultCache
+	codeForm string
+	baseURL  string
+	now      func() time.Time
+	// rotateHeaders picks new headers from headerBundles whenever the user agent changes
+	rotateHeaders bool
+	headers       headerBundle
+	// breaker pauses searches after repeated failures (nil = disabled)
+	breaker *circuitBreaker
+	// err is set by an option that couldn't be applied, and returned instead of searching
+	err error
+}
+
+// SearcherOption configures optional Searcher behavior
+type SearcherOption func(*Searcher)
+
+// WithMaintenanceMarkers adds phrases that identify an OLCC maintenance page,
+// in addition to DefaultMaintenanceMarkers
+func WithMaintenanceMarkers(markers []string) SearcherOption {
+	return func(s *Searcher) {
+		for _, marker := range markers {
+			if marker = strings.TrimSpace(marker); marker != "" {
+				s.maintenanceMarkers = append(s.maintenanceMarkers, strings.ToLower(marker))
+			}
+		}
+	}
+}
+
+// WithBaseURL points the Searcher at a different site root than DefaultBaseURL,
+// such as a local fixture server in tests
+func WithBaseURL(base string) SearcherOption {
+	return func(s *Searcher) {
+		if base == "" {
+			return
+		}
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+		s.baseURL = base
+	}
+}
+
+// WithTransport sets the transport requests to the site are sent with, e.g.
+// one with a TLS config that pins the site's certificate. Cookies, connection
+// limiting and HTTP logging keep working on top of it.
+func WithTransport(transport *http.Transport) SearcherOption {
+	return func(s *Searcher) {
+		if transport != nil {
+			s.setBaseTransport(transport)
+		}
+	}
+}
+
+// WithProxy routes requests to the site through a proxy, given as an http://,
+// https://, socks5:// or socks5h:// URL; net/http speaks SOCKS5 itself. It
+// applies on top of any transport set with WithTransport. An invalid proxy URL
+// makes the Searcher refuse to search rather than connect directly, and is
+// never logged since it may hold credentials.
+func WithProxy(proxyURL string) SearcherOption {
+	return func(s *Searcher) {
+		if proxyURL == "" {
+			return
+		}
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			// url.Parse errors quote the URL, which may hold proxy credentials
+			s.err = errors.New("invalid proxy URL: not a valid URL")
+			return
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			s.err = fmt.Errorf("invalid proxy URL: unsupported scheme %q", u.Scheme)
+			return
+		}
+		if u.Host == "" {
+			s.err = errors.New("invalid proxy URL: missing proxy host")
+			return
+		}
+
+		var transport *http.Transport
+		if base, ok := s.baseTransport().(*http.Transport); ok {
+			transport = base.Clone()
+		} else {
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		}
+		transport.Proxy = http.ProxyURL(u)
+		s.setBaseTransport(transport)
+	}
+}
+
+// baseTransport returns the transport at the bottom of the client's chain of
+// round trippers, beneath connection limiting and HTTP logging (nil = default)
+func (s *Searcher) baseTransport() http.RoundTripper {
+	next := s.client.Transport
+	for {
+		switch rt := next.(type) {
+		case *loggingTransport:
+			next = rt.next
+		case *limitedTransport:
+			next = rt.next
+		default:
+			return next
+		}
+	}
+}
+
+// setBaseTransport replaces the transport at the bottom of the client's chain
+// of round trippers, keeping connection limiting and HTTP logging on top
+func (s *Searcher) setBaseTransport(transport http.RoundTripper) {
+	next := &s.client.Transport
+	for {
+		switch rt := (*next).(type) {
+		case *loggingTransport:
+			next = &rt.next
+		case *limitedTransport:
+			next = &rt.next
+		default:
+			*next = transport
+			return
+		}
+	}
+}
+
+// WithUserAgentRotation sets how often a randomly chosen user agent changes:
+// RotatePerSearch (default), RotatePerSession, or RotateOff.
+// It has no effect when a fixed user agent is configured.
+func WithUserAgentRotation(mode string) SearcherOption {
+	return func(s *Searcher) {
+		if mode != "" {
+			s.rotation = mode
+		}
+	}
+}
+
+// WithHeaderRotation sends Accept and Accept-Language headers from a set of
+// realistic bundles, switching bundle whenever a random user agent changes.
+// It has no effect when a fixed user agent is configured.
+func WithHeaderRotation(enabled bool) SearcherOption {
+	return func(s *Searcher) {
+		s.rotateHeaders = enabled
+	}
+}
+
+// WithItemCodeForm sets which item code is reported in LiquorItem.Code:
+// CodeParenthesized (default) or CodeFull
+func WithItemCodeForm(form string) SearcherOption {
+	return func(s *Searcher) {
+		if form != "" {
+			s.codeForm = form
+		}
+	}
+}
+
+// WithClock sets the function used to timestamp found items, defaulting to time.Now
+func WithClock(now func() time.Time) SearcherOption {
+	return func(s *Searcher) {
+		if now != nil {
+			s.now = now
+		}
+	}
 }
 
 // NewSearcher creates a new searcher with cookie support
-func NewSearcher(userAgent string) *Searcher {
+func NewSearcher(userAgent string, opts ...SearcherOption) *Searcher {
 	jar, _ := cookiejar.New(nil)
 	client := &http.Client{
 		Jar:     jar,
@@ -99,11 +338,35 @@ func NewSearcher(userAgent string) *Searcher {
 		userAgent = userAgents[randUserAgent.Int64()]
 	}
 
-	return &Searcher{
-		client:     client,
-		userAgent:  userAgent,
-		cycleAgent: cycleAgent,
+	s := &Searcher{
+		client:          client,
+		userAgent:       userAgent,
+		cycleAgent:      cycleAgent,
+		rotation:        RotatePerSearch,
+		ambiguous:       AmbiguousAll,
+		maxListProducts: DefaultMaxListProducts,
+		codeForm:        CodeParenthesized,
+		baseURL:         DefaultBaseURL,
+		now:             time.Now,
+	}
+	for _, marker := range DefaultMaintenanceMarkers {
+		s.maintenanceMarkers = append(s.maintenanceMarkers, strings.ToLower(marker))
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if s.cycleAgent && s.rotateHeaders {
+		s.updateHeaders()
+	}
+
+	return s
+}
+
+// Err returns the error of an option the Searcher was created with that
+// couldn't be applied, such as an invalid proxy URL. A Searcher with an error
+// refuses to search, returning it.
+func (s *Searcher) Err() error {
+	return s.err
 }
 
 // updateUserAgent sets a new random user agent if cycling is enabled
@@ -113,49 +376,93 @@ func (s *Searcher) updateUserAgent() {
 		bigLenUserAgents.SetInt64(int64(len(userAgents))) // Convert int to int64 first
 		randUserAgent, _ := rand.Int(rand.Reader, bigLenUserAgents)
 		s.userAgent = userAgents[randUserAgent.Int64()]
-		log.Debugf("Using user agent: %s", s.userAgent)
+		logger.Debugf("Using user agent: %s", s.userAgent)
+		if s.rotateHeaders {
+			s.updateHeaders()
+		}
+	}
+}
+
+// updateHeaders picks a random header bundle to send with the user agent
+func (s *Searcher) updateHeaders() {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(headerBundles))))
+	if err != nil {
+		s.headers = headerBundles[0]
+		return
+	}
+	s.headers = headerBundles[n.Int64()]
+}
+
+// setHeaders sets the user agent, and any rotated headers, on a request to the site
+func (s *Searcher) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", s.userAgent)
+	if s.headers.Accept != "" {
+		req.Header.Set("Accept", s.headers.Accept)
+	}
+	if s.headers.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", s.headers.AcceptLanguage)
+	}
+}
+
+// StartSession marks the start of a search cycle, picking a new user agent
+// when rotating per session
+func (s *Searcher) StartSession() {
+	if s.rotation == RotatePerSession {
+		s.updateUserAgent()
 	}
 }
 
-// AgeVerification performs the age verification
-func (s *Searcher) AgeVerification() error {
+// AgeVerification performs the age verification. Its requests are cancelled
+// as soon as ctx is done.
+func (s *Searcher) AgeVerification(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+
 	// First get the page to get session cookies
-	req, err := http.NewRequest("GET", baseURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", s.userAgent)
+	s.setHeaders(req)
 
 	resp, err := s.client.Do(req) // #nosec G704 -- URL is from config, not user input
 	if err != nil {
 		return fmt.Errorf("failed to get page: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Parse the form for the age verification
-	_, err = goquery.NewDocumentFromReader(resp.Body)
+	// Parse the form for the age verification. The page is closed before the
+	// form is submitted, since with a connection limiter its open body holds
+	// a slot the submission may need.
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	resp.Body.Close()
 	if err != nil {
 		return fmt.Errorf("failed to parse page: %w", err)
 	}
 
+	if s.isMaintenancePage(doc) {
+		return ErrSiteMaintenance
+	}
+
 	// Prepare the form submission for age verification
 	formData := url.Values{}
 	formData.Set("ageCheck", "true")
 	formData.Set("action", "search")
 
 	// Submit the form
-	log.Debugf("AgeVerification() POSTing %v\n", formData)
-	req, err = http.NewRequest("POST", ageBtnFormURL, strings.NewReader(formData.Encode()))
+	logger.Debugf("AgeVerification() POSTing %v\n", formData)
+	ageBtnFormURL := s.baseURL + ageBtnFormPath
+	req, err = http.NewRequestWithContext(ctx, "POST", ageBtnFormURL, strings.NewReader(formData.Encode()))
 	if err != nil {
 		return fmt.Errorf("failed to create form submission request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", s.userAgent)
+	s.setHeaders(req)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Referer", ageBtnFormURL)
 
-	resp, err = s.client.Do(req) // #nosec G704 -- URL is hardcoded
+	resp, err = s.client.Do(req) // #nosec G704 -- URL is from config, not user input
 	if err != nil {
 		return fmt.Errorf("failed to submit age verification: %w", err)
 	}
@@ -168,12 +475,131 @@ func (s *Searcher) AgeVerification() error {
 	return nil
 }
 
-// SearchItem searches for a specific liquor item by name or code
+// SearchItem searches for a specific liquor item by name or code. A numeric
+// item code, optionally followed by its short code as in "99900014675(0146B)",
+// is searched as a code so OLCC goes straight to the product page. A distance
+// of Statewide searches every store instead of those near zipcode.
 func (s *Searcher) SearchItem(ctx context.Context, item string, zipcode string, distance int) ([]LiquorItem, error) {
-	s.updateUserAgent()
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	// Age verification and search requests share one user agent either way
+	if s.rotation == RotatePerSearch {
+		s.updateUserAgent()
+	}
+
+	if radius := nearestRadius(distance); distance != Statewide && radius != distance {
+		logger.Warnf("Distance of %d miles is not supported by OLCC, searching within %d miles instead", distance, radius)
+		distance = radius
+	}
+
+	key := cacheKey{item: strings.TrimSpace(item), zipcode: zipcode, distance: distance}
+	if s.cache != nil {
+		if results, ok := s.cache.get(key); ok {
+			logger.Debugf("Using cached results for %s", item)
+			for i := range results {
+				results[i].Query = item
+			}
+			return results, nil
+		}
+	}
+
+	if s.breaker != nil {
+		if err := s.breaker.allow(s.now()); err != nil {
+			return nil, err
+		}
+	}
 
+	results, err := s.search(ctx, item, zipcode, distance)
+	// A cancelled search says nothing about whether OLCC is healthy
+	if s.breaker != nil {
+		if ctx.Err() == nil {
+			s.breaker.record(s.now(), err)
+		} else {
+			s.breaker.abort()
+		}
+	}
+
+	for i := range results {
+		results[i].Query = item
+	}
+	if err == nil && s.cache != nil {
+		s.cache.put(key, results)
+	}
+	return results, err
+}
+
+// search fetches and parses the results of a search that wasn't answered from cache
+func (s *Searcher) search(ctx context.Context, item string, zipcode string, distance int) ([]LiquorItem, error) {
+	query := item
+	code, byCode := itemCode(item)
+	if byCode {
+		query = code
+	}
+
+	doc, err := s.fetchResults(ctx, query, zipcode, distance)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []LiquorItem
+	switch {
+	case isProductListPage(doc) && byCode:
+		// An item code should identify one product; don't guess between several
+		logger.Warnf("Search for item code %s returned a product list rather than a product, skipping", code)
+	case isProductListPage(doc):
+		// A free-text search matching several products returns a list to choose from
+		results, err = s.searchMatches(ctx, item, doc, zipcode, distance)
+	default:
+		results, err = s.parseResults(doc)
+	}
+	return results, err
+}
+
+// itemCode returns the numeric OLCC item code in item, such as "99900014675"
+// or "99900014675(0146B)", and false if item is a product name instead
+func itemCode(item string) (string, bool) {
+	code := strings.TrimSpace(item)
+	if open := strings.IndexByte(code, '('); open > 0 && strings.HasSuffix(code, ")") {
+		code = code[:open]
+	}
+	// Short numbers are more likely product names, such as "1792"
+	if len(code) < 5 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// nearestRadius returns the supported radius closest to distance, preferring
+// the larger radius on a tie so no nearby stores are missed
+func nearestRadius(distance int) int {
+	nearest := SupportedRadii[0]
+	for _, radius := range SupportedRadii[1:] {
+		if abs(radius-distance) <= abs(nearest-distance) {
+			nearest = radius
+		}
+	}
+	return nearest
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// fetchResults performs age verification and submits the search form, returning the response document
+func (s *Searcher) fetchResults(ctx context.Context, item string, zipcode string, distance int) (*goquery.Document, error) {
 	// Perform age verification before search
-	if err := s.AgeVerification(); err != nil {
+	if err := s.AgeVerification(ctx); err != nil {
 		return nil, fmt.Errorf("age verification failed: %w", err)
 	}
 
@@ -181,24 +607,30 @@ func (s *Searcher) SearchItem(ctx context.Context, item string, zipcode string,
 	formData := url.Values{}
 	formData.Set("view", "global")
 	formData.Set("action", "search")
-	formData.Set("radiusSearchParam", fmt.Sprintf("%d", distance))
 	formData.Set("productSearchParam", item)
-	formData.Set("locationSearchParam", zipcode)
+	// Statewide searches leave out the location and radius, on the assumption
+	// that OLCC then lists every store carrying the item. This has not been
+	// checked against the live site.
+	if distance != Statewide {
+		formData.Set("radiusSearchParam", fmt.Sprintf("%d", distance))
+		formData.Set("locationSearchParam", zipcode)
+	}
 	formData.Set("btnSearch", "Search")
 
 	// Submit search form
-	log.Debugf("SearchItem() POSTing formData %v\n", formData)
-	req, err := http.NewRequest("POST", searchURL, strings.NewReader(formData.Encode()))
+	logger.Debugf("SearchItem() POSTing formData %v\n", formData)
+	searchURL := s.baseURL + searchPath
+	req, err := http.NewRequestWithContext(ctx, "POST", searchURL, strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create search request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", s.userAgent)
+	s.setHeaders(req)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Referer", searchURL)
 
 	// Perform search request
-	resp, err := s.client.Do(req) // #nosec G704 -- URL is hardcoded
+	resp, err := s.client.Do(req) // #nosec G704 -- URL is from config, not user input
 	if err != nil {
 		return nil, fmt.Errorf("search request failed: %w", err)
 	}
@@ -214,37 +646,71 @@ func (s *Searcher) SearchItem(ctx context.Context, item string, zipcode string,
 		return nil, fmt.Errorf("failed to generate goquery document from search query response: %w", err)
 	}
 
+	return doc, nil
+}
+
+// parseResults turns a search response document into found liquor items,
+// returning ErrSiteMaintenance if the document is the OLCC maintenance page
+func (s *Searcher) parseResults(doc *goquery.Document) ([]LiquorItem, error) {
+	if s.isMaintenancePage(doc) {
+		return nil, ErrSiteMaintenance
+	}
+
 	// Extract product information
 	product := extractProductInfo(doc)
+	if s.codeForm == CodeFull && product.FullCode != "" {
+		product.ItemCode = product.FullCode
+	}
 
 	// Extract results from the table and generate list of found LiquorItem
-	results := extractResults(doc, product)
+	results := extractResults(doc, product, s.now())
 
 	return results, nil
 }
 
+// isMaintenancePage reports whether the document contains any known maintenance marker
+func (s *Searcher) isMaintenancePage(doc *goquery.Document) bool {
+	text := strings.ToLower(strings.Join(strings.Fields(doc.Find("body").Text()), " "))
+	for _, marker := range s.maintenanceMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractResults extracts found products from the table and creates a list of found liquor item results
-func extractResults(doc *goquery.Document, product ProductInfo) []LiquorItem {
-	var results []LiquorItem
+// stamped with foundAt
+func extractResults(doc *goquery.Document, product ProductInfo, foundAt time.Time) []LiquorItem {
+	rows := doc.Find("tr.row, tr.alt-row")
+	if rows.Length() == 0 {
+		return nil
+	}
+	// Statewide searches can list hundreds of stores, so size the results up front
+	results := make([]LiquorItem, 0, rows.Length())
+	cols := findResultColumns(rows.First().Closest("table"))
+
+	rows.Each(func(i int, s *goquery.Selection) {
+		tds := s.Find("td")
 
-	doc.Find("tr.row, tr.alt-row").Each(func(i int, s *goquery.Selection) {
 		// Check if the store has stock
-		qtyText := strings.TrimSpace(s.Find("td.qty").Text())
+		qtyCell := s.Find("td.qty")
+		if cols.qty >= 0 {
+			qtyCell = tds.Eq(cols.qty)
+		}
+		qtyText := strings.TrimSpace(qtyCell.Text())
 		if qtyText == "0" {
 			return // Skip stores with no stock
 		}
 
-		tds := s.Find("td")
-		// The actual table columns are:
-		// [0]Store No, [1]Location(City), [2]Address, [3]Zip, [4]Telephone, [5]Store Hours, [6]Qty, [7]Distance
-		// Note: Store No (td[0]) contains <noscript><a>...</noscript><span class="link">NNNN</span><noscript>...</noscript>
+		// Note: Store No contains <noscript><a>...</noscript><span class="link">NNNN</span><noscript>...</noscript>
 		// The store number is in <span class="link">, so we prefer that; fall back to full td text.
-		storeNoTd := tds.Eq(0)
+		storeNoTd := tds.Eq(cols.storeNo)
 		storeNo := strings.TrimSpace(storeNoTd.Find("span.link").Text())
 		if storeNo == "" {
 			storeNo = strings.TrimSpace(storeNoTd.Text())
 		}
-		location := strings.TrimSpace(tds.Eq(1).Text())
+		location := strings.TrimSpace(tds.Eq(cols.location).Text())
 
 		// Combine store number and city for a meaningful store identifier
 		storeName := location
@@ -254,11 +720,16 @@ func extractResults(doc *goquery.Document, product ProductInfo) []LiquorItem {
 
 		if storeName != "" {
 			results = append(results, LiquorItem{
-				Name:  product.Name,
-				Code:  product.ItemCode,
-				Store: storeName,
-				Date:  time.Now(),
-				Price: product.BottlePrice,
+				Name:      product.Name,
+				Code:      product.ItemCode,
+				Store:     storeName,
+				Date:      foundAt,
+				Price:     product.BottlePrice,
+				Quantity:  parseQuantity(qtyText),
+				Size:      product.Size,
+				Proof:     product.Proof,
+				Category:  product.Category,
+				CasePrice: product.CasePrice,
 			})
 		}
 	})
@@ -266,6 +737,54 @@ func extractResults(doc *goquery.Document, product ProductInfo) []LiquorItem {
 	return results
 }
 
+// resultColumns holds the cell indices of the store results table columns
+// that extractResults reads. A qty of -1 means the quantity cell is found by
+// its "qty" class instead.
+type resultColumns struct {
+	storeNo  int
+	location int
+	qty      int
+}
+
+// findResultColumns maps the header cells of the store results table to column
+// indices, so a reordered table is still read correctly. Columns without a
+// recognized header keep their usual position:
+// [0]Store No, [1]Location(City), [2]Address, [3]Zip, [4]Telephone, [5]Store Hours, [6]Qty, [7]Distance
+func findResultColumns(table *goquery.Selection) resultColumns {
+	cols := resultColumns{storeNo: 0, location: 1, qty: -1}
+
+	table.Find("tr").FilterFunction(func(i int, tr *goquery.Selection) bool {
+		return tr.Find("th").Length() > 0
+	}).First().Find("th").Each(func(i int, th *goquery.Selection) {
+		switch strings.ToLower(strings.TrimSpace(th.Text())) {
+		case "store no", "store no.", "store number", "store":
+			cols.storeNo = i
+		case "location", "city":
+			cols.location = i
+		case "qty", "quantity":
+			cols.qty = i
+		}
+	})
+
+	return cols
+}
+
+// parseQuantity parses a store's stock quantity cell such as "12", "1,200",
+// "10+" or a range like "5-10", taking the number it starts with. It returns
+// 0 (unknown) when the cell doesn't start with a number.
+func parseQuantity(qtyText string) int {
+	text := strings.ReplaceAll(strings.TrimSpace(qtyText), ",", "")
+	end := strings.IndexFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	if end == -1 {
+		end = len(text)
+	}
+	qty, err := strconv.Atoi(text[:end])
+	if err != nil {
+		return 0
+	}
+	return qty
+}
+
 // extractProductInfo extracts product details from the product-details table
 func extractProductInfo(doc *goquery.Document) ProductInfo {
 	product := ProductInfo{}
@@ -286,7 +805,9 @@ func extractProductInfo(doc *goquery.Document) ProductInfo {
 				fullCode := itemParts[1]
 				// Extract the code in parentheses if it exists
 				codeInParens := ""
+				product.FullCode = fullCode
 				if i := strings.Index(fullCode, "("); i != -1 {
+					product.FullCode = fullCode[:i]
 					if j := strings.Index(fullCode, ")"); j != -1 && j > i {
 						codeInParens = fullCode[i+1 : j]
 					}
@@ -56,7 +56,7 @@ func TestE2ESearchItem(t *testing.T) {
 func TestE2EAgeVerification(t *testing.T) {
 	searcher := NewSearcher("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	if err := searcher.AgeVerification(); err != nil {
+	if err := searcher.AgeVerification(context.Background()); err != nil {
 		t.Fatalf("AgeVerification failed: %v", err)
 	}
 }
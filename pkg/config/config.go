@@ -7,7 +7,8 @@
 //
 // The configuration loading follows a priority order:
 //  1. Environment variables (highest priority)
-//  2. YAML configuration file (config.yaml or custom file)
+//  2. Configuration file in YAML, JSON or TOML (config.yaml, config.json,
+//     config.toml or custom file)
 //  3. .env file in current working directory
 //  4. Default values (lowest priority)
 //
@@ -48,69 +49,522 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
+
+	"github.com/toozej/go-find-liquor/internal/logging"
+	"github.com/toozej/go-find-liquor/internal/schedule"
 )
 
+// logger is the config component logger, whose level can be set separately
+var logger = logging.For(logging.Config)
+
 // CommonItem represents a commonly available liquor item used for health check searches
 type CommonItem struct {
-	Code string `yaml:"code" json:"code"`
-	Name string `yaml:"name" json:"name"`
+	Code string `yaml:"code" toml:"code" json:"code"`
+	Name string `yaml:"name" toml:"name" json:"name"`
 }
 
 // NotificationConfig stores configuration for notification methods
 type NotificationConfig struct {
-	Type       string            `yaml:"type" json:"type"`
-	Endpoint   string            `yaml:"endpoint" json:"endpoint"`
-	Credential map[string]string `yaml:"credential" json:"credential"`
-	Condense   bool              `yaml:"condense" json:"condense"`
+	Type       string            `yaml:"type" toml:"type" json:"type"`
+	Endpoint   string            `yaml:"endpoint" toml:"endpoint" json:"endpoint"`
+	Credential map[string]string `yaml:"credential" toml:"credential" json:"credential"`
+	Condense   bool              `yaml:"condense" toml:"condense" json:"condense"`
+
+	// Optional text/templates for found item notifications, rendered with the
+	// found item (.Name, .Code, .Store, .Date, .Price, .Quantity, .Size, .Proof,
+	// .Category, .CasePrice, .Query, .Note). Unset templates use the default format.
+	SubjectTemplate string `yaml:"subject_template,omitempty" toml:"subject_template,omitempty" json:"subject_template,omitempty"`
+	BodyTemplate    string `yaml:"body_template,omitempty" toml:"body_template,omitempty" json:"body_template,omitempty"`
+}
+
+// ItemAlert sets the notification priority and sound used when a watched item is found.
+// They are passed to notification services that support them (currently Pushover).
+type ItemAlert struct {
+	// Priority from -2 (lowest) to 2 (emergency), 0 is normal
+	Priority int    `yaml:"priority" toml:"priority" json:"priority"`
+	Sound    string `yaml:"sound" toml:"sound" json:"sound"`
+}
+
+// ItemConfig is a watched item. In config files it is either the item's name
+// or code as a plain string, or a mapping with the options below.
+type ItemConfig struct {
+	Name string `yaml:"name" toml:"name" json:"name"`
+	// Search radius in miles for this item (overrides the user's distance)
+	Distance int `yaml:"distance,omitempty" toml:"distance,omitempty" json:"distance,omitempty"`
+	// Highest bottle price in dollars worth a notification (0 = no limit)
+	MaxPrice float64 `yaml:"max_price,omitempty" toml:"max_price,omitempty" json:"max_price,omitempty"`
+	// Free text shown in notifications about this item
+	Note string `yaml:"note,omitempty" toml:"note,omitempty" json:"note,omitempty"`
+}
+
+// UnmarshalYAML reads an item from either a plain string or a mapping
+func (i *ItemConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*i = ItemConfig{}
+		return node.Decode(&i.Name)
+	}
+	type plain ItemConfig
+	return node.Decode((*plain)(i))
+}
+
+// UnmarshalTOML reads an item from either a plain string or a table
+func (i *ItemConfig) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case string:
+		*i = ItemConfig{Name: v}
+		return nil
+	case map[string]any:
+		// Re-encode the table so the plain struct's toml tags apply
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+			return err
+		}
+		type plain ItemConfig
+		*i = ItemConfig{}
+		_, err := toml.Decode(buf.String(), (*plain)(i))
+		return err
+	default:
+		return fmt.Errorf("item must be a string or a table, got %T", data)
+	}
+}
+
+// MarshalYAML writes an item without options as a plain string
+func (i ItemConfig) MarshalYAML() (any, error) {
+	if i == (ItemConfig{Name: i.Name}) {
+		return i.Name, nil
+	}
+	type plain ItemConfig
+	return plain(i), nil
+}
+
+// NewItems returns watched items for names, without options
+func NewItems(names ...string) []ItemConfig {
+	items := make([]ItemConfig, len(names))
+	for i, name := range names {
+		items[i] = ItemConfig{Name: name}
+	}
+	return items
+}
+
+// PriceLimit bounds the bottle price, in dollars, at which a watched item is
+// worth a notification. A zero bound is not enforced.
+type PriceLimit struct {
+	Min float64 `yaml:"min,omitempty" toml:"min,omitempty" json:"min,omitempty"`
+	Max float64 `yaml:"max,omitempty" toml:"max,omitempty" json:"max,omitempty"`
 }
 
 // UserConfig represents configuration for a single user
 type UserConfig struct {
-	Name          string               `yaml:"name" json:"name"`
-	Items         []string             `yaml:"items" json:"items"`
-	Zipcode       string               `yaml:"zipcode" json:"zipcode"`
-	Distance      int                  `yaml:"distance" json:"distance"`
-	Notifications []NotificationConfig `yaml:"notifications" json:"notifications"`
+	Name          string               `yaml:"name" toml:"name" json:"name"`
+	Items         []ItemConfig         `yaml:"items" toml:"items" json:"items"`
+	Zipcode       string               `yaml:"zipcode" toml:"zipcode" json:"zipcode"`
+	Distance      int                  `yaml:"distance" toml:"distance" json:"distance"`
+	Notifications []NotificationConfig `yaml:"notifications" toml:"notifications" json:"notifications"`
+
+	// Search every OLCC store in Oregon instead of those within distance of the zipcode
+	Statewide bool `yaml:"statewide,omitempty" toml:"statewide,omitempty" json:"statewide,omitempty"`
+
+	// File of more items to watch, one per line, relative to the config file's
+	// directory. Blank lines and lines starting with # are skipped.
+	ItemsFile string `yaml:"items_file,omitempty" toml:"items_file,omitempty" json:"items_file,omitempty"`
+
+	// How often to search for this user (overrides global interval)
+	Interval time.Duration `yaml:"interval,omitempty" toml:"interval,omitempty" json:"interval,omitempty"`
+
+	// Cron expression to search on instead of an interval, e.g. "0 8,18 * * *"
+	// for 8am and 6pm daily, in the local time zone
+	Cron string `yaml:"cron,omitempty" toml:"cron,omitempty" json:"cron,omitempty"`
+
+	// How often this user gets a heartbeat notification (overrides global heartbeat_interval)
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval,omitempty" toml:"heartbeat_interval,omitempty" json:"heartbeat_interval,omitempty"`
+
+	// Random wait between this user's item searches (overrides global min_item_delay and max_item_delay)
+	MinItemDelay time.Duration `yaml:"min_item_delay,omitempty" toml:"min_item_delay,omitempty" json:"min_item_delay,omitempty"`
+	MaxItemDelay time.Duration `yaml:"max_item_delay,omitempty" toml:"max_item_delay,omitempty" json:"max_item_delay,omitempty"`
+
+	// Minimum bottles summed across all stores before notifying (overrides global min_total_stock)
+	MinTotalStock int `yaml:"min_total_stock,omitempty" toml:"min_total_stock,omitempty" json:"min_total_stock,omitempty"`
+
+	// Minimum bottles at a store before notifying about it (overrides global min_store_stock)
+	MinStoreStock int `yaml:"min_store_stock,omitempty" toml:"min_store_stock,omitempty" json:"min_store_stock,omitempty"`
+
+	// IANA time zone for this user's notification timestamps (overrides global timezone)
+	Timezone string `yaml:"timezone,omitempty" toml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// Proxy this user's searches go through (overrides global proxy_url)
+	ProxyURL string `yaml:"proxy_url,omitempty" toml:"proxy_url,omitempty" json:"proxy_url,omitempty"`
+
+	// Notification priority and sound per item, keyed by the item as written in items
+	ItemAlerts map[string]ItemAlert `yaml:"item_alerts,omitempty" toml:"item_alerts,omitempty" json:"item_alerts,omitempty"`
+
+	// Query sent to OLCC per item, keyed by the item as written in items, for
+	// items whose search term should differ from the name they are known by
+	SearchTerms map[string]string `yaml:"search_terms,omitempty" toml:"search_terms,omitempty" json:"search_terms,omitempty"`
+
+	// Price range per item, keyed by the item as written in items; stores
+	// listing the item outside it are not notified about
+	PriceLimits map[string]PriceLimit `yaml:"price_limits,omitempty" toml:"price_limits,omitempty" json:"price_limits,omitempty"`
+}
+
+// ItemNames returns the names of the user's watched items
+func (u UserConfig) ItemNames() []string {
+	names := make([]string, len(u.Items))
+	for i, item := range u.Items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+// Item returns the options of the watched item named name
+func (u UserConfig) Item(name string) (ItemConfig, bool) {
+	for _, item := range u.Items {
+		if strings.EqualFold(item.Name, name) {
+			return item, true
+		}
+	}
+	return ItemConfig{}, false
+}
+
+// EffectiveInterval returns the user's search interval, falling back to global when not set
+func (u UserConfig) EffectiveInterval(global time.Duration) time.Duration {
+	if u.Interval > 0 {
+		return u.Interval
+	}
+	return global
+}
+
+// EffectiveItemDelay returns the range of the random wait between the user's
+// item searches, falling back to the global range for bounds not set
+func (u UserConfig) EffectiveItemDelay(globalMin, globalMax time.Duration) (time.Duration, time.Duration) {
+	minDelay, maxDelay := globalMin, globalMax
+	if u.MinItemDelay > 0 {
+		minDelay = u.MinItemDelay
+	}
+	if u.MaxItemDelay > 0 {
+		maxDelay = u.MaxItemDelay
+	}
+	return minDelay, maxDelay
+}
+
+// EffectiveHeartbeatInterval returns how often the user gets a heartbeat,
+// falling back to global when not set (0 = never)
+func (u UserConfig) EffectiveHeartbeatInterval(global time.Duration) time.Duration {
+	if u.HeartbeatInterval > 0 {
+		return u.HeartbeatInterval
+	}
+	return global
+}
+
+// EffectiveProxyURL returns the proxy for the user's searches, falling back to global when not set
+func (u UserConfig) EffectiveProxyURL(global string) string {
+	if u.ProxyURL != "" {
+		return u.ProxyURL
+	}
+	return global
+}
+
+// LocationDescription describes where the user's searches look: statewide,
+// or within their distance of their zipcode
+func (u UserConfig) LocationDescription() string {
+	if u.Statewide {
+		return "statewide"
+	}
+	return fmt.Sprintf("%s (within %d miles)", u.Zipcode, u.Distance)
+}
+
+// ScheduleDescription describes when the user's searches run: their cron
+// expression if set, otherwise their effective interval
+func (u UserConfig) ScheduleDescription(global time.Duration) string {
+	if u.Cron != "" {
+		return fmt.Sprintf("cron %q", u.Cron)
+	}
+	return "every " + u.EffectiveInterval(global).String()
 }
 
 // Config stores all configuration for the application
 type Config struct {
 	// Global settings
-	Interval  time.Duration `yaml:"interval" json:"interval" env:"GFL_INTERVAL" envDefault:"12h"`
-	UserAgent string        `yaml:"user_agent" json:"user_agent" env:"GFL_USER_AGENT"`
-	Verbose   bool          `yaml:"verbose" json:"verbose" env:"GFL_VERBOSE" envDefault:"false"`
+	Interval  time.Duration `yaml:"interval" toml:"interval" json:"interval" env:"GFL_INTERVAL" envDefault:"12h"`
+	UserAgent string        `yaml:"user_agent" toml:"user_agent" json:"user_agent" env:"GFL_USER_AGENT"`
+	Verbose   bool          `yaml:"verbose" toml:"verbose" json:"verbose" env:"GFL_VERBOSE" envDefault:"false"`
+
+	// IANA time zone for notification timestamps, e.g. America/Los_Angeles (default: local time)
+	Timezone string `yaml:"timezone" toml:"timezone" json:"timezone" env:"GFL_TIMEZONE"`
+
+	// Rotate Accept and Accept-Language headers together with a random user agent (default: false)
+	RotateHeaders bool `yaml:"rotate_headers" toml:"rotate_headers" json:"rotate_headers" env:"GFL_ROTATE_HEADERS" envDefault:"false"`
+
+	// Proxy searches go through: an http://, https://, socks5:// or socks5h:// URL (default: none)
+	ProxyURL string `yaml:"proxy_url" toml:"proxy_url" json:"proxy_url" env:"GFL_PROXY_URL"`
+
+	// How often a random user agent changes when user_agent is unset: per-search (default), per-session, off
+	UserAgentRotation string `yaml:"user_agent_rotation" toml:"user_agent_rotation" json:"user_agent_rotation" env:"GFL_USER_AGENT_ROTATION"`
+
+	// Maximum simultaneous HTTP connections to OLCC across all users (0 = unlimited)
+	MaxConnections int `yaml:"max_connections" toml:"max_connections" json:"max_connections" env:"GFL_MAX_CONNECTIONS"`
+
+	// Maximum item searches running at once across all users (0 = unlimited)
+	MaxConcurrentSearches int `yaml:"max_concurrent_searches" toml:"max_concurrent_searches" json:"max_concurrent_searches" env:"GFL_MAX_CONCURRENT_SEARCHES"`
+
+	// Consecutive failed searches after which a user's searches pause (0 = disabled)
+	CircuitBreakerThreshold int `yaml:"circuit_breaker_threshold" toml:"circuit_breaker_threshold" json:"circuit_breaker_threshold" env:"GFL_CIRCUIT_BREAKER_THRESHOLD"`
+
+	// How long searches first pause once the circuit breaker opens, doubling while failures continue (0 = 5m)
+	CircuitBreakerCooldown time.Duration `yaml:"circuit_breaker_cooldown" toml:"circuit_breaker_cooldown" json:"circuit_breaker_cooldown" env:"GFL_CIRCUIT_BREAKER_COOLDOWN"`
+
+	// How long search results are reused for identical searches by any user (0 = disabled)
+	SearchCacheTTL time.Duration `yaml:"search_cache_ttl" toml:"search_cache_ttl" json:"search_cache_ttl" env:"GFL_SEARCH_CACHE_TTL"`
+
+	// Minimum bottles summed across all stores before notifying about an item (0 = disabled)
+	MinTotalStock int `yaml:"min_total_stock" toml:"min_total_stock" json:"min_total_stock" env:"GFL_MIN_TOTAL_STOCK"`
+
+	// Minimum bottles at a store before notifying about the item there (0 = disabled)
+	MinStoreStock int `yaml:"min_store_stock" toml:"min_store_stock" json:"min_store_stock" env:"GFL_MIN_STORE_STOCK"`
+
+	// Maximum number of items a single user may watch (0 = unlimited)
+	MaxItemsPerUser int `yaml:"max_items_per_user" toml:"max_items_per_user" json:"max_items_per_user" env:"GFL_MAX_ITEMS_PER_USER"`
+
+	// Log notifications instead of sending them, e.g. to try out a new config
+	DryRun bool `yaml:"dry_run" toml:"dry_run" json:"dry_run" env:"GFL_DRY_RUN"`
+
+	// Watch the config file and apply changes to users without restarting
+	HotReload bool `yaml:"hot_reload" toml:"hot_reload" json:"hot_reload" env:"GFL_HOT_RELOAD"`
+
+	// Random delay of up to this long before each user's first search, and before
+	// each later search, so users don't all hit the site at once (0 = disabled, default: 1m)
+	StartupJitter time.Duration `yaml:"startup_jitter" toml:"startup_jitter" json:"startup_jitter" env:"GFL_STARTUP_JITTER"`
+	TickJitter    time.Duration `yaml:"tick_jitter" toml:"tick_jitter" json:"tick_jitter" env:"GFL_TICK_JITTER"`
+
+	// Random wait between each user's item searches, so the site isn't hit in a
+	// burst (default: 0s to 30s)
+	MinItemDelay time.Duration `yaml:"min_item_delay" toml:"min_item_delay" json:"min_item_delay" env:"GFL_MIN_ITEM_DELAY"`
+	MaxItemDelay time.Duration `yaml:"max_item_delay" toml:"max_item_delay" json:"max_item_delay" env:"GFL_MAX_ITEM_DELAY"`
+
+	// Randomize each user's item search order every cycle
+	ShuffleItems bool `yaml:"shuffle_items" toml:"shuffle_items" json:"shuffle_items" env:"GFL_SHUFFLE_ITEMS" envDefault:"false"`
+
+	// Send a status notification each time an item goes this long without being found (0 = disabled)
+	DrySpellAlert time.Duration `yaml:"dry_spell_alert" toml:"dry_spell_alert" json:"dry_spell_alert" env:"GFL_DRY_SPELL_ALERT"`
+
+	// Suggest double-checking the watch list after this many consecutive cycles with no finds (0 = disabled)
+	ZeroFindAlert int `yaml:"zero_find_alert" toml:"zero_find_alert" json:"zero_find_alert" env:"GFL_ZERO_FIND_ALERT"`
+
+	// After this many consecutive search cycles where every search failed, notify once searches work again (0 = disabled)
+	RecoveryAlert int `yaml:"recovery_alert" toml:"recovery_alert" json:"recovery_alert" env:"GFL_RECOVERY_ALERT"`
+
+	// After this many consecutive search cycles where every search failed, send a failure alert (0 = disabled)
+	FailureAlert int `yaml:"failure_alert" toml:"failure_alert" json:"failure_alert" env:"GFL_FAILURE_ALERT"`
+
+	// Least time between failure alerts while searches keep failing (default: 24h)
+	FailureAlertCooldown time.Duration `yaml:"failure_alert_cooldown" toml:"failure_alert_cooldown" json:"failure_alert_cooldown" env:"GFL_FAILURE_ALERT_COOLDOWN"`
+
+	// Only notify about an item at a store once while it stays in stock, re-notifying after renotify_after (0 = never)
+	NotifyNewOnly bool          `yaml:"notify_new_only" toml:"notify_new_only" json:"notify_new_only" env:"GFL_NOTIFY_NEW_ONLY" envDefault:"false"`
+	RenotifyAfter time.Duration `yaml:"renotify_after" toml:"renotify_after" json:"renotify_after" env:"GFL_RENOTIFY_AFTER"`
+
+	// Skip found notifications when a cycle's results are identical to the previous cycle's
+	SkipUnchangedCycles bool `yaml:"skip_unchanged_cycles" toml:"skip_unchanged_cycles" json:"skip_unchanged_cycles" env:"GFL_SKIP_UNCHANGED_CYCLES" envDefault:"false"`
+
+	// Still send notifications for a search cycle that was interrupted by shutdown
+	FlushOnStop bool `yaml:"flush_on_stop" toml:"flush_on_stop" json:"flush_on_stop" env:"GFL_FLUSH_ON_STOP" envDefault:"false"`
+
+	// How long to wait for searches to finish when stopping (0 = DefaultShutdownTimeout)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" json:"shutdown_timeout" env:"GFL_SHUTDOWN_TIMEOUT"`
+
+	// Extra attempts for a failed notification send, with jittered exponential backoff (0 = no retries)
+	NotifyRetries int `yaml:"notify_retries" toml:"notify_retries" json:"notify_retries" env:"GFL_NOTIFY_RETRIES"`
+
+	// Wait before the first notification retry, doubling each attempt (0 = default: 2s)
+	NotifyRetryDelay time.Duration `yaml:"notify_retry_delay" toml:"notify_retry_delay" json:"notify_retry_delay" env:"GFL_NOTIFY_RETRY_DELAY"`
+
+	// What to do with found items no notification channel could deliver:
+	// log (default), file (append them to dead_letter_file) or retry-next-cycle
+	OnNotifyFailure string `yaml:"on_notify_failure" toml:"on_notify_failure" json:"on_notify_failure" env:"GFL_ON_NOTIFY_FAILURE"`
+	// File undelivered found items are appended to as JSON lines (default: <state_dir>/undelivered.jsonl)
+	DeadLetterFile string `yaml:"dead_letter_file" toml:"dead_letter_file" json:"dead_letter_file" env:"GFL_DEAD_LETTER_FILE"`
+
+	// When a condensed notification can't be delivered, send each item individually instead
+	CondensedFallback bool `yaml:"condensed_fallback" toml:"condensed_fallback" json:"condensed_fallback" env:"GFL_CONDENSED_FALLBACK" envDefault:"false"`
+
+	// Condensed notifications list an item found at more than this many stores
+	// once, e.g. "BLANTON'S available at 20 stores (nearest: Store A, $59.99)" (0 = disabled)
+	CondenseGroupStores int `yaml:"condense_group_stores" toml:"condense_group_stores" json:"condense_group_stores" env:"GFL_CONDENSE_GROUP_STORES"`
+	// List every store below an item grouped by condense_group_stores
+	CondenseListStores bool `yaml:"condense_list_stores" toml:"condense_list_stores" json:"condense_list_stores" env:"GFL_CONDENSE_LIST_STORES"`
+
+	// Directory for persisted state such as price history. When set, found items are only
+	// notified when they newly appear in stock at a store or their price drops.
+	StateDir string `yaml:"state_dir" toml:"state_dir" json:"state_dir" env:"GFL_STATE_DIR"`
+
+	// File the runner's per-user state (last run, items already notified about) is
+	// kept in across restarts (default: <state_dir>/runner_state.json, disabled without state_dir)
+	StateFile string `yaml:"state_file" toml:"state_file" json:"state_file" env:"GFL_STATE_FILE"`
+
+	// Fields identifying an already-notified item in the state dir: code, store, price, size (default: code, store)
+	DedupKeyFields []string `yaml:"dedup_key_fields" toml:"dedup_key_fields" json:"dedup_key_fields" env:"GFL_DEDUP_KEY_FIELDS" envSeparator:","`
+
+	// Log output format: text or json (default: text)
+	LogFormat string `yaml:"log_format" toml:"log_format" json:"log_format" env:"GFL_LOG_FORMAT"`
+
+	// Log level per component (search, runner, notification, config), overriding the global level
+	LogLevels map[string]string `yaml:"log_levels" toml:"log_levels" json:"log_levels" env:"GFL_LOG_LEVELS"`
+
+	// Webhook URL that each found item is POSTed to as JSON as soon as it is found (default: disabled)
+	StreamWebhook string `yaml:"stream_webhook" toml:"stream_webhook" json:"stream_webhook" env:"GFL_STREAM_WEBHOOK"`
+
+	// Address to serve Prometheus metrics on at /metrics, e.g. ":9090" (default: disabled)
+	MetricsAddr string `yaml:"metrics_addr" toml:"metrics_addr" json:"metrics_addr" env:"GFL_METRICS_ADDR"`
+
+	// Address to serve a health check on at /healthz, e.g. ":8080" (default: disabled)
+	HealthAddr string `yaml:"health_addr" toml:"health_addr" json:"health_addr" env:"GFL_HEALTH_ADDR"`
+
+	// Localhost address to serve each user's search status on at /status for the
+	// status command, e.g. "127.0.0.1:9092" (default: disabled)
+	StatusAddr string `yaml:"status_addr" toml:"status_addr" json:"status_addr" env:"GFL_STATUS_ADDR"`
+
+	// Log every OLCC request's method, URL, headers, status and timing, with secrets redacted
+	LogHTTP bool `yaml:"log_http" toml:"log_http" json:"log_http" env:"GFL_LOG_HTTP" envDefault:"false"`
+
+	// Per-user audit logs of found items, written to <per_user_log_dir>/<user>.log
+	PerUserLogs   bool   `yaml:"per_user_logs" toml:"per_user_logs" json:"per_user_logs" env:"GFL_PER_USER_LOGS" envDefault:"false"`
+	PerUserLogDir string `yaml:"per_user_log_dir" toml:"per_user_log_dir" json:"per_user_log_dir" env:"GFL_PER_USER_LOG_DIR"`
+
+	// Notification types users may configure, e.g. [gotify, pushover] (default: all)
+	AllowedNotificationTypes []string `yaml:"allowed_notification_types" toml:"allowed_notification_types" json:"allowed_notification_types" env:"GFL_ALLOWED_NOTIFICATION_TYPES" envSeparator:","`
 
 	// Commonly available items used for health check searches
-	CommonItems []CommonItem `yaml:"common_items" json:"common_items"`
+	CommonItems []CommonItem `yaml:"common_items" toml:"common_items" json:"common_items"`
+
+	// How to handle a search that matches several products: all (default) or exact (only matching names)
+	AmbiguousResults string `yaml:"ambiguous_results" toml:"ambiguous_results" json:"ambiguous_results" env:"GFL_AMBIGUOUS_RESULTS"`
+
+	// Most products searched from one multi-match results page (default: 10)
+	MaxListProducts int `yaml:"max_list_products" toml:"max_list_products" json:"max_list_products" env:"GFL_MAX_LIST_PRODUCTS"`
+
+	// Which OLCC item code is reported for found items: parenthesized (default, e.g. 0146B) or full (e.g. 99900014675)
+	ItemCodeForm string `yaml:"item_code_form" toml:"item_code_form" json:"item_code_form" env:"GFL_ITEM_CODE_FORM"`
+
+	// Include each found item's size and proof in found notifications, e.g. "(750 ML, 80.0 proof)"
+	ShowItemDetails bool `yaml:"show_item_details" toml:"show_item_details" json:"show_item_details" env:"GFL_SHOW_ITEM_DETAILS" envDefault:"false"`
+
+	// Shown instead of "for <price>" when OLCC lists an item without a price, e.g. "(price N/A)" (default: omitted)
+	MissingPrice string `yaml:"missing_price" toml:"missing_price" json:"missing_price" env:"GFL_MISSING_PRICE"`
+
+	// How product names are cased in notifications: as-is (default, OLCC's all-caps names), title-case or lower
+	ProductNameCase string `yaml:"product_name_case" toml:"product_name_case" json:"product_name_case" env:"GFL_PRODUCT_NAME_CASE"`
+
+	// How often a heartbeat notification is sent, at the end of a search cycle (0 = never)
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" toml:"heartbeat_interval" json:"heartbeat_interval" env:"GFL_HEARTBEAT_INTERVAL"`
+
+	// Optional text/template for the heartbeat message, rendered with stats
+	// (.User, .Users, .ItemsWatched, .LastFind, .Uptime, .HealthCheckItem, .HealthCheckFound)
+	HeartbeatTemplate string `yaml:"heartbeat_template" toml:"heartbeat_template" json:"heartbeat_template"`
+
+	// Additional phrases identifying the OLCC maintenance page (added to built-in defaults)
+	MaintenanceMarkers []string `yaml:"maintenance_markers" toml:"maintenance_markers" json:"maintenance_markers"`
 
 	// User-specific configurations
-	Users []UserConfig `yaml:"users" json:"users"`
+	Users []UserConfig `yaml:"users" toml:"users" json:"users"`
 
 	// Legacy fields for backward compatibility (will be populated if old format detected)
-	Items         []string             `yaml:"items,omitempty" json:"items,omitempty" env:"GFL_ITEMS" envSeparator:","`
-	Zipcode       string               `yaml:"zipcode,omitempty" json:"zipcode,omitempty" env:"GFL_ZIPCODE"`
-	Distance      int                  `yaml:"distance,omitempty" json:"distance,omitempty" env:"GFL_DISTANCE" envDefault:"10"`
-	Notifications []NotificationConfig `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+	Items         []string             `yaml:"items,omitempty" toml:"items,omitempty" json:"items,omitempty" env:"GFL_ITEMS" envSeparator:","`
+	Zipcode       string               `yaml:"zipcode,omitempty" toml:"zipcode,omitempty" json:"zipcode,omitempty" env:"GFL_ZIPCODE"`
+	Distance      int                  `yaml:"distance,omitempty" toml:"distance,omitempty" json:"distance,omitempty" env:"GFL_DISTANCE" envDefault:"10"`
+	Notifications []NotificationConfig `yaml:"notifications,omitempty" toml:"notifications,omitempty" json:"notifications,omitempty"`
+}
+
+// Default jitter before searches. Set before the config file is read, so a file
+// setting them to 0 disables jitter.
+const (
+	DefaultStartupJitter = time.Minute
+	DefaultTickJitter    = time.Minute
+)
+
+// DefaultMaxItemDelay is the longest default wait between item searches. Set
+// before the config file is read, so a file setting max_item_delay to 0
+// searches items without waiting.
+const DefaultMaxItemDelay = 30 * time.Second
+
+// DefaultShutdownTimeout is how long stopping waits for searches to finish
+// when shutdown_timeout is not set
+const DefaultShutdownTimeout = 30 * time.Second
+
+// EffectiveShutdownTimeout returns how long stopping waits for searches to
+// finish, falling back to DefaultShutdownTimeout when not set
+func (c Config) EffectiveShutdownTimeout() time.Duration {
+	if c.ShutdownTimeout > 0 {
+		return c.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
 }
 
 // configFile holds the path to the config file set via CLI
 var configFile string
 
+// maxItemsPerUser overrides Config.MaxItemsPerUser when set via CLI
+var maxItemsPerUser *int
+
+// dryRun overrides Config.DryRun when set via CLI
+var dryRun *bool
+
+// defaultConfigFiles lists the config files searched for in the current directory
+// when no config file is set via CLI, in order of preference
+var defaultConfigFiles = []string{"config.yaml", "config.json", "config.toml"}
+
+// envPrefix is the prefix shared by all environment variables read by this package
+const envPrefix = "GFL_"
+
+// ErrNoConfig is returned by GetConfig when there is no config file and no GFL_*
+// environment variables, as opposed to a configuration that is present but invalid
+var ErrNoConfig = errors.New("no configuration found")
+
+// ErrNotLegacyConfig is returned by MigrateConfigFile for a config file that is
+// already in the multi-user format
+var ErrNotLegacyConfig = errors.New("not a legacy single-user configuration")
+
 // SetConfigFile sets the config file path for loading
 func SetConfigFile(path string) {
 	configFile = path
 }
 
-// GetConfig is the primary entrypoint to the config package, loading configuration structs from .env and yaml files
+// SetMaxItemsPerUser overrides max_items_per_user from the config file and environment
+func SetMaxItemsPerUser(limit int) {
+	maxItemsPerUser = &limit
+}
+
+// SetDryRun overrides dry_run from the config file and environment
+func SetDryRun(enabled bool) {
+	dryRun = &enabled
+}
+
+// FilePath returns the config file GetConfig loads: the one set via CLI, else
+// the first default config file present, or "" if there is none
+func FilePath() string {
+	if configFile != "" {
+		return configFile
+	}
+	return findDefaultConfigFile()
+}
+
+// GetConfig is the primary entrypoint to the config package, loading configuration structs from .env and config files
 func GetConfig() (Config, error) {
 	var config Config
 
@@ -119,19 +573,33 @@ func GetConfig() (Config, error) {
 		return config, fmt.Errorf("failed to load .env file: %w", err)
 	}
 
+	// Fail fast with an actionable error when there is nothing to load at all
+	if configFile == "" && findDefaultConfigFile() == "" && !hasEnvConfig() {
+		return config, fmt.Errorf("%w: no config file found at %s and no %s* environment variables set",
+			ErrNoConfig, strings.Join(defaultConfigSearchPaths(), ", "), envPrefix)
+	}
+
 	// Parse environment variables first (they have highest priority)
 	if err := env.Parse(&config); err != nil {
 		return config, fmt.Errorf("failed to parse environment variables: %w", err)
 	}
 
-	// Load YAML config file if specified or if default exists
-	yamlConfig, err := loadYAMLConfig()
+	// Load config file if specified or if default exists
+	fileConfig, err := loadConfigFile()
 	if err != nil {
-		return config, fmt.Errorf("failed to load YAML config: %w", err)
+		return config, fmt.Errorf("failed to load config file: %w", err)
 	}
 
-	// Merge YAML config with env config (env takes priority)
-	config = mergeConfigs(yamlConfig, config)
+	// Merge file config with env config (env takes priority)
+	config = mergeConfigs(fileConfig, config)
+
+	// The CLI flag takes priority over both
+	if maxItemsPerUser != nil {
+		config.MaxItemsPerUser = *maxItemsPerUser
+	}
+	if dryRun != nil {
+		config.DryRun = *dryRun
+	}
 
 	// Check for legacy configuration format and migrate if needed
 	if isLegacyConfig(config) {
@@ -140,6 +608,7 @@ func GetConfig() (Config, error) {
 			return config, fmt.Errorf("failed to migrate legacy config: %w", err)
 		}
 		config = migratedConfig
+		logger.Infof("Migrated legacy configuration to multi-user format with user '%s'", config.Users[0].Name)
 	}
 
 	// Validate configuration
@@ -185,25 +654,70 @@ func loadEnvFile() error {
 	return nil
 }
 
-// loadYAMLConfig loads configuration from YAML file
-func loadYAMLConfig() (Config, error) {
-	var config Config
+// loadConfigFile loads configuration from the YAML, JSON or TOML config file
+func loadConfigFile() (Config, error) {
+	config := Config{
+		StartupJitter: DefaultStartupJitter,
+		TickJitter:    DefaultTickJitter,
+		MaxItemDelay:  DefaultMaxItemDelay,
+	}
 
 	// Determine which config file to load
-	var configPath string
-	if configFile != "" {
-		configPath = configFile
-	} else if _, err := os.Stat("config.yaml"); err == nil {
-		configPath = "config.yaml"
-	} else {
+	configPath := FilePath()
+	if configPath == "" {
 		// No config file to load, return empty config
 		return config, nil
 	}
 
+	logger.Debugf("Loading configuration from %s", configPath)
+	data, err := readConfigFile(configPath)
+	if err != nil {
+		return config, err
+	}
+
+	if err := decodeConfig(configPath, data, &config); err != nil {
+		return config, err
+	}
+
+	if err := loadItemsFiles(&config, configPath); err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// decodeConfig unmarshals a config file into config with the decoder matching
+// its extension: .json, .toml, or YAML for anything else
+func decodeConfig(path string, data []byte, config *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		// JSON is valid YAML, and decoding it as YAML reads durations written as
+		// strings like "6h", which encoding/json can't decode into time.Duration
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to unmarshal JSON config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("failed to unmarshal JSON config: %w", err)
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), config); err != nil {
+			return fmt.Errorf("failed to unmarshal TOML config: %w", err)
+		}
+	default:
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("failed to unmarshal YAML config: %w", err)
+		}
+	}
+	return nil
+}
+
+// readConfigFile reads the config file at configPath through a root scoped to its directory
+func readConfigFile(configPath string) ([]byte, error) {
 	// Resolve config path to an absolute path for consistent handling
 	absConfigPath, err := filepath.Abs(configPath)
 	if err != nil {
-		return config, fmt.Errorf("failed to resolve config file path: %w", err)
+		return nil, fmt.Errorf("failed to resolve config file path: %w", err)
 	}
 
 	// The parent directory of the config file becomes the root for os.OpenRoot.
@@ -215,21 +729,51 @@ func loadYAMLConfig() (Config, error) {
 	// Create a root filesystem scoped to the config file's parent directory
 	root, err := os.OpenRoot(configDir)
 	if err != nil {
-		return config, fmt.Errorf("failed to create secure root filesystem: %w", err)
+		return nil, fmt.Errorf("failed to create secure root filesystem: %w", err)
 	}
 	defer root.Close()
 
-	// Read and parse YAML file using scoped root with relative path
+	// Read YAML file using scoped root with relative path
 	data, err := root.ReadFile(configName)
 	if err != nil {
-		return config, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
 	}
 
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return config, fmt.Errorf("failed to unmarshal YAML config: %w", err)
+	return data, nil
+}
+
+// findDefaultConfigFile returns the first default config file present in the
+// current directory, or an empty string if there is none
+func findDefaultConfigFile() string {
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// defaultConfigSearchPaths returns the absolute paths searched for a default config file
+func defaultConfigSearchPaths() []string {
+	paths := make([]string, 0, len(defaultConfigFiles))
+	for _, name := range defaultConfigFiles {
+		if abs, err := filepath.Abs(name); err == nil {
+			name = abs
+		}
+		paths = append(paths, name)
 	}
+	return paths
+}
 
-	return config, nil
+// hasEnvConfig reports whether any GFL_* environment variable is set,
+// including those loaded from a .env file
+func hasEnvConfig() bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envPrefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // mergeConfigs merges YAML config with env config, giving priority to env values
@@ -243,9 +787,177 @@ func mergeConfigs(yamlConfig, envConfig Config) Config {
 	if envConfig.UserAgent != "" {
 		result.UserAgent = envConfig.UserAgent
 	}
+	if envConfig.StartupJitter != 0 {
+		result.StartupJitter = envConfig.StartupJitter
+	}
+	if envConfig.TickJitter != 0 {
+		result.TickJitter = envConfig.TickJitter
+	}
+	if envConfig.MinItemDelay != 0 {
+		result.MinItemDelay = envConfig.MinItemDelay
+	}
+	if envConfig.MaxItemDelay != 0 {
+		result.MaxItemDelay = envConfig.MaxItemDelay
+	}
 	if envConfig.Verbose {
 		result.Verbose = envConfig.Verbose
 	}
+	if envConfig.Timezone != "" {
+		result.Timezone = envConfig.Timezone
+	}
+	if envConfig.RotateHeaders {
+		result.RotateHeaders = envConfig.RotateHeaders
+	}
+	if envConfig.ProxyURL != "" {
+		result.ProxyURL = envConfig.ProxyURL
+	}
+	if envConfig.UserAgentRotation != "" {
+		result.UserAgentRotation = envConfig.UserAgentRotation
+	}
+	if envConfig.MaxConnections != 0 {
+		result.MaxConnections = envConfig.MaxConnections
+	}
+	if envConfig.MaxConcurrentSearches != 0 {
+		result.MaxConcurrentSearches = envConfig.MaxConcurrentSearches
+	}
+	if envConfig.CircuitBreakerThreshold != 0 {
+		result.CircuitBreakerThreshold = envConfig.CircuitBreakerThreshold
+	}
+	if envConfig.CircuitBreakerCooldown != 0 {
+		result.CircuitBreakerCooldown = envConfig.CircuitBreakerCooldown
+	}
+	if envConfig.SearchCacheTTL != 0 {
+		result.SearchCacheTTL = envConfig.SearchCacheTTL
+	}
+	if envConfig.HeartbeatInterval != 0 {
+		result.HeartbeatInterval = envConfig.HeartbeatInterval
+	}
+	if envConfig.MinTotalStock != 0 {
+		result.MinTotalStock = envConfig.MinTotalStock
+	}
+	if envConfig.MinStoreStock != 0 {
+		result.MinStoreStock = envConfig.MinStoreStock
+	}
+	if envConfig.MaxItemsPerUser != 0 {
+		result.MaxItemsPerUser = envConfig.MaxItemsPerUser
+	}
+	if envConfig.DryRun {
+		result.DryRun = envConfig.DryRun
+	}
+	if envConfig.HotReload {
+		result.HotReload = envConfig.HotReload
+	}
+	if envConfig.ShuffleItems {
+		result.ShuffleItems = envConfig.ShuffleItems
+	}
+	if envConfig.DrySpellAlert != 0 {
+		result.DrySpellAlert = envConfig.DrySpellAlert
+	}
+	if envConfig.ZeroFindAlert != 0 {
+		result.ZeroFindAlert = envConfig.ZeroFindAlert
+	}
+	if envConfig.RecoveryAlert != 0 {
+		result.RecoveryAlert = envConfig.RecoveryAlert
+	}
+	if envConfig.FailureAlert != 0 {
+		result.FailureAlert = envConfig.FailureAlert
+	}
+	if envConfig.FailureAlertCooldown != 0 {
+		result.FailureAlertCooldown = envConfig.FailureAlertCooldown
+	}
+	if envConfig.AmbiguousResults != "" {
+		result.AmbiguousResults = envConfig.AmbiguousResults
+	}
+	if envConfig.MaxListProducts != 0 {
+		result.MaxListProducts = envConfig.MaxListProducts
+	}
+	if envConfig.ItemCodeForm != "" {
+		result.ItemCodeForm = envConfig.ItemCodeForm
+	}
+	if envConfig.ShowItemDetails {
+		result.ShowItemDetails = envConfig.ShowItemDetails
+	}
+	if envConfig.MissingPrice != "" {
+		result.MissingPrice = envConfig.MissingPrice
+	}
+	if envConfig.ProductNameCase != "" {
+		result.ProductNameCase = envConfig.ProductNameCase
+	}
+	if envConfig.NotifyNewOnly {
+		result.NotifyNewOnly = envConfig.NotifyNewOnly
+	}
+	if envConfig.RenotifyAfter != 0 {
+		result.RenotifyAfter = envConfig.RenotifyAfter
+	}
+	if envConfig.OnNotifyFailure != "" {
+		result.OnNotifyFailure = envConfig.OnNotifyFailure
+	}
+	if envConfig.DeadLetterFile != "" {
+		result.DeadLetterFile = envConfig.DeadLetterFile
+	}
+	if envConfig.SkipUnchangedCycles {
+		result.SkipUnchangedCycles = envConfig.SkipUnchangedCycles
+	}
+	if envConfig.FlushOnStop {
+		result.FlushOnStop = envConfig.FlushOnStop
+	}
+	if envConfig.ShutdownTimeout != 0 {
+		result.ShutdownTimeout = envConfig.ShutdownTimeout
+	}
+	if envConfig.NotifyRetries != 0 {
+		result.NotifyRetries = envConfig.NotifyRetries
+	}
+	if envConfig.NotifyRetryDelay != 0 {
+		result.NotifyRetryDelay = envConfig.NotifyRetryDelay
+	}
+	if envConfig.CondensedFallback {
+		result.CondensedFallback = envConfig.CondensedFallback
+	}
+	if envConfig.CondenseGroupStores != 0 {
+		result.CondenseGroupStores = envConfig.CondenseGroupStores
+	}
+	if envConfig.CondenseListStores {
+		result.CondenseListStores = envConfig.CondenseListStores
+	}
+	if len(envConfig.AllowedNotificationTypes) > 0 {
+		result.AllowedNotificationTypes = envConfig.AllowedNotificationTypes
+	}
+	if envConfig.StateDir != "" {
+		result.StateDir = envConfig.StateDir
+	}
+	if envConfig.StateFile != "" {
+		result.StateFile = envConfig.StateFile
+	}
+	if len(envConfig.DedupKeyFields) > 0 {
+		result.DedupKeyFields = envConfig.DedupKeyFields
+	}
+	if envConfig.LogFormat != "" {
+		result.LogFormat = envConfig.LogFormat
+	}
+	if len(envConfig.LogLevels) > 0 {
+		result.LogLevels = envConfig.LogLevels
+	}
+	if envConfig.MetricsAddr != "" {
+		result.MetricsAddr = envConfig.MetricsAddr
+	}
+	if envConfig.HealthAddr != "" {
+		result.HealthAddr = envConfig.HealthAddr
+	}
+	if envConfig.StatusAddr != "" {
+		result.StatusAddr = envConfig.StatusAddr
+	}
+	if envConfig.StreamWebhook != "" {
+		result.StreamWebhook = envConfig.StreamWebhook
+	}
+	if envConfig.LogHTTP {
+		result.LogHTTP = envConfig.LogHTTP
+	}
+	if envConfig.PerUserLogs {
+		result.PerUserLogs = envConfig.PerUserLogs
+	}
+	if envConfig.PerUserLogDir != "" {
+		result.PerUserLogDir = envConfig.PerUserLogDir
+	}
 
 	// Legacy fields - only override if env has values
 	if len(envConfig.Items) > 0 {
@@ -289,7 +1001,7 @@ func migrateLegacyConfig(config Config) (Config, error) {
 	// Create a single user from legacy configuration
 	user := UserConfig{
 		Name:          "default",
-		Items:         config.Items,
+		Items:         NewItems(config.Items...),
 		Zipcode:       config.Zipcode,
 		Distance:      config.Distance,
 		Notifications: config.Notifications,
@@ -302,23 +1014,364 @@ func migrateLegacyConfig(config Config) (Config, error) {
 
 	// Create new config with migrated user
 	newConfig := Config{
-		Interval:  config.Interval,
-		UserAgent: config.UserAgent,
-		Verbose:   config.Verbose,
-		Users:     []UserConfig{user},
+		Interval:                 config.Interval,
+		UserAgent:                config.UserAgent,
+		Verbose:                  config.Verbose,
+		Timezone:                 config.Timezone,
+		RotateHeaders:            config.RotateHeaders,
+		ProxyURL:                 config.ProxyURL,
+		UserAgentRotation:        config.UserAgentRotation,
+		MaxConnections:           config.MaxConnections,
+		MaxConcurrentSearches:    config.MaxConcurrentSearches,
+		CircuitBreakerThreshold:  config.CircuitBreakerThreshold,
+		CircuitBreakerCooldown:   config.CircuitBreakerCooldown,
+		SearchCacheTTL:           config.SearchCacheTTL,
+		MinTotalStock:            config.MinTotalStock,
+		MinStoreStock:            config.MinStoreStock,
+		MaxItemsPerUser:          config.MaxItemsPerUser,
+		DryRun:                   config.DryRun,
+		HotReload:                config.HotReload,
+		StartupJitter:            config.StartupJitter,
+		TickJitter:               config.TickJitter,
+		MinItemDelay:             config.MinItemDelay,
+		MaxItemDelay:             config.MaxItemDelay,
+		ShuffleItems:             config.ShuffleItems,
+		DrySpellAlert:            config.DrySpellAlert,
+		ZeroFindAlert:            config.ZeroFindAlert,
+		RecoveryAlert:            config.RecoveryAlert,
+		FailureAlert:             config.FailureAlert,
+		FailureAlertCooldown:     config.FailureAlertCooldown,
+		FlushOnStop:              config.FlushOnStop,
+		ShutdownTimeout:          config.ShutdownTimeout,
+		SkipUnchangedCycles:      config.SkipUnchangedCycles,
+		NotifyNewOnly:            config.NotifyNewOnly,
+		RenotifyAfter:            config.RenotifyAfter,
+		OnNotifyFailure:          config.OnNotifyFailure,
+		DeadLetterFile:           config.DeadLetterFile,
+		NotifyRetries:            config.NotifyRetries,
+		NotifyRetryDelay:         config.NotifyRetryDelay,
+		CondensedFallback:        config.CondensedFallback,
+		CondenseGroupStores:      config.CondenseGroupStores,
+		CondenseListStores:       config.CondenseListStores,
+		StateDir:                 config.StateDir,
+		StateFile:                config.StateFile,
+		DedupKeyFields:           config.DedupKeyFields,
+		LogFormat:                config.LogFormat,
+		LogLevels:                config.LogLevels,
+		StreamWebhook:            config.StreamWebhook,
+		MetricsAddr:              config.MetricsAddr,
+		HealthAddr:               config.HealthAddr,
+		StatusAddr:               config.StatusAddr,
+		LogHTTP:                  config.LogHTTP,
+		PerUserLogs:              config.PerUserLogs,
+		PerUserLogDir:            config.PerUserLogDir,
+		CommonItems:              config.CommonItems,
+		AllowedNotificationTypes: config.AllowedNotificationTypes,
+		MaintenanceMarkers:       config.MaintenanceMarkers,
+		HeartbeatInterval:        config.HeartbeatInterval,
+		HeartbeatTemplate:        config.HeartbeatTemplate,
+		MissingPrice:             config.MissingPrice,
+		ProductNameCase:          config.ProductNameCase,
+		ShowItemDetails:          config.ShowItemDetails,
+		AmbiguousResults:         config.AmbiguousResults,
+		MaxListProducts:          config.MaxListProducts,
+		ItemCodeForm:             config.ItemCodeForm,
+		Users:                    []UserConfig{user},
 	}
 
-	fmt.Printf("Migrated legacy configuration to multi-user format with user '%s'\n", user.Name)
-
 	return newConfig, nil
 }
 
+// legacyKeys are the top-level YAML keys replaced by the users list when migrating
+var legacyKeys = map[string]bool{"items": true, "zipcode": true, "distance": true, "notifications": true}
+
+// MigrateConfigFile converts the legacy single-user YAML config file at path to
+// the multi-user format, returning the new YAML. Global settings are kept as
+// written; only the legacy keys are replaced by a users list. Environment
+// variables are not merged in. It returns ErrNotLegacyConfig if the file is
+// not in the legacy format.
+func MigrateConfigFile(path string) ([]byte, error) {
+	data, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML config: %w", err)
+	}
+	if !isLegacyConfig(config) {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotLegacyConfig)
+	}
+
+	migrated, err := migrateLegacyConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate legacy config: %w", err)
+	}
+	if err := validateConfig(migrated); err != nil {
+		return nil, fmt.Errorf("migrated configuration is invalid: %w", err)
+	}
+
+	// Rewrite the original document so global settings keep their formatting and comments
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML config: %w", err)
+	}
+	mapping := doc.Content[0]
+	var kept []*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if !legacyKeys[mapping.Content[i].Value] {
+			kept = append(kept, mapping.Content[i], mapping.Content[i+1])
+		}
+	}
+
+	var users yaml.Node
+	if err := users.Encode(migrated.Users); err != nil {
+		return nil, fmt.Errorf("failed to encode users: %w", err)
+	}
+	mapping.Content = append(kept, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "users"}, &users)
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
 // validateConfig validates the configuration structure
+// validateProxyURL checks that a proxy URL, if set, has a supported scheme and a host
+func validateProxyURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		// url.Parse errors quote the URL, which may hold proxy credentials
+		return fmt.Errorf("not a valid URL")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("scheme must be http, https, socks5 or socks5h; got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing proxy host")
+	}
+	return nil
+}
+
 func validateConfig(config Config) error {
 	if len(config.Users) == 0 {
 		return fmt.Errorf("at least one user must be configured")
 	}
 
+	if config.Timezone != "" {
+		if _, err := time.LoadLocation(config.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+		}
+	}
+
+	if err := validateProxyURL(config.ProxyURL); err != nil {
+		return fmt.Errorf("invalid proxy_url: %w", err)
+	}
+
+	switch config.UserAgentRotation {
+	case "", "per-search", "per-session", "off":
+	default:
+		return fmt.Errorf("user_agent_rotation must be one of per-search, per-session, off; got %q", config.UserAgentRotation)
+	}
+
+	switch config.AmbiguousResults {
+	case "", "all", "exact":
+	default:
+		return fmt.Errorf("ambiguous_results must be one of all, exact; got %q", config.AmbiguousResults)
+	}
+
+	if config.MaxListProducts < 0 {
+		return fmt.Errorf("max_list_products must not be negative")
+	}
+
+	switch config.OnNotifyFailure {
+	case "", "log", "retry-next-cycle":
+	case "file":
+		if config.DeadLetterFile == "" && config.StateDir == "" {
+			return fmt.Errorf("on_notify_failure file requires dead_letter_file or state_dir")
+		}
+	default:
+		return fmt.Errorf("on_notify_failure must be one of log, file, retry-next-cycle; got %q", config.OnNotifyFailure)
+	}
+
+	switch config.ItemCodeForm {
+	case "", "parenthesized", "full":
+	default:
+		return fmt.Errorf("item_code_form must be one of parenthesized, full; got %q", config.ItemCodeForm)
+	}
+
+	switch config.ProductNameCase {
+	case "", "as-is", "title-case", "lower":
+	default:
+		return fmt.Errorf("product_name_case must be one of as-is, title-case, lower; got %q", config.ProductNameCase)
+	}
+
+	if config.LogFormat != "" && !slices.Contains(logging.Formats, strings.ToLower(config.LogFormat)) {
+		return fmt.Errorf("log_format must be one of %s; got %q", strings.Join(logging.Formats, ", "), config.LogFormat)
+	}
+
+	for component, level := range config.LogLevels {
+		if !slices.Contains(logging.Components, strings.ToLower(component)) {
+			return fmt.Errorf("log_levels: unknown component %q, must be one of %s", component, strings.Join(logging.Components, ", "))
+		}
+		if _, err := log.ParseLevel(level); err != nil {
+			return fmt.Errorf("log_levels: invalid level for %s: %w", component, err)
+		}
+	}
+
+	for _, field := range config.DedupKeyFields {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "code", "store", "price", "size":
+		default:
+			return fmt.Errorf("dedup_key_fields must only contain code, store, price, size; got %q", field)
+		}
+	}
+
+	if config.StreamWebhook != "" {
+		u, err := url.Parse(config.StreamWebhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("stream_webhook must be an http or https URL, got %q", config.StreamWebhook)
+		}
+	}
+
+	if config.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(config.MetricsAddr); err != nil {
+			return fmt.Errorf("metrics_addr must be a host:port address such as :9090: %w", err)
+		}
+	}
+
+	if config.HealthAddr != "" {
+		if _, _, err := net.SplitHostPort(config.HealthAddr); err != nil {
+			return fmt.Errorf("health_addr must be a host:port address such as :8080: %w", err)
+		}
+		if config.HealthAddr == config.MetricsAddr {
+			return fmt.Errorf("health_addr and metrics_addr must be different addresses")
+		}
+	}
+
+	if config.StatusAddr != "" {
+		host, _, err := net.SplitHostPort(config.StatusAddr)
+		if err != nil {
+			return fmt.Errorf("status_addr must be a host:port address such as 127.0.0.1:9092: %w", err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("status_addr must be a localhost address such as 127.0.0.1:9092, got %q", config.StatusAddr)
+		}
+		if config.StatusAddr == config.MetricsAddr || config.StatusAddr == config.HealthAddr {
+			return fmt.Errorf("status_addr must differ from metrics_addr and health_addr")
+		}
+	}
+
+	if config.MaxConnections < 0 {
+		return fmt.Errorf("max_connections must not be negative")
+	}
+
+	if config.MaxConcurrentSearches < 0 {
+		return fmt.Errorf("max_concurrent_searches must not be negative")
+	}
+
+	if config.CircuitBreakerThreshold < 0 {
+		return fmt.Errorf("circuit_breaker_threshold must not be negative")
+	}
+
+	if config.CircuitBreakerCooldown < 0 {
+		return fmt.Errorf("circuit_breaker_cooldown must not be negative")
+	}
+
+	if config.SearchCacheTTL < 0 {
+		return fmt.Errorf("search_cache_ttl must not be negative")
+	}
+
+	if config.MinTotalStock < 0 {
+		return fmt.Errorf("min_total_stock must not be negative")
+	}
+
+	if config.MinStoreStock < 0 {
+		return fmt.Errorf("min_store_stock must not be negative")
+	}
+
+	if config.MaxItemsPerUser < 0 {
+		return fmt.Errorf("max_items_per_user must not be negative")
+	}
+
+	if config.StartupJitter < 0 {
+		return fmt.Errorf("startup_jitter must not be negative")
+	}
+
+	if config.TickJitter < 0 {
+		return fmt.Errorf("tick_jitter must not be negative")
+	}
+
+	if config.MinItemDelay < 0 || config.MaxItemDelay < 0 {
+		return fmt.Errorf("min_item_delay and max_item_delay must not be negative")
+	}
+
+	if config.MinItemDelay > config.MaxItemDelay {
+		return fmt.Errorf("min_item_delay must not be greater than max_item_delay")
+	}
+
+	if config.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown_timeout must not be negative")
+	}
+
+	if config.CondenseGroupStores < 0 {
+		return fmt.Errorf("condense_group_stores must not be negative")
+	}
+
+	if config.DrySpellAlert < 0 {
+		return fmt.Errorf("dry_spell_alert must not be negative")
+	}
+
+	if config.ZeroFindAlert < 0 {
+		return fmt.Errorf("zero_find_alert must not be negative")
+	}
+
+	if config.RecoveryAlert < 0 {
+		return fmt.Errorf("recovery_alert must not be negative")
+	}
+
+	if config.FailureAlert < 0 {
+		return fmt.Errorf("failure_alert must not be negative")
+	}
+
+	if config.FailureAlertCooldown < 0 {
+		return fmt.Errorf("failure_alert_cooldown must not be negative")
+	}
+
+	if config.RenotifyAfter < 0 {
+		return fmt.Errorf("renotify_after must not be negative")
+	}
+
+	if config.NotifyRetries < 0 {
+		return fmt.Errorf("notify_retries must not be negative")
+	}
+
+	if config.NotifyRetryDelay < 0 {
+		return fmt.Errorf("notify_retry_delay must not be negative")
+	}
+
+	if config.HeartbeatInterval < 0 {
+		return fmt.Errorf("heartbeat_interval must not be negative")
+	}
+
+	if config.HeartbeatTemplate != "" {
+		if _, err := template.New("heartbeat").Parse(config.HeartbeatTemplate); err != nil {
+			return fmt.Errorf("invalid heartbeat_template: %w", err)
+		}
+	}
+
 	for i, user := range config.Users {
 		if user.Name == "" {
 			return fmt.Errorf("user %d must have a name", i)
@@ -328,12 +1381,145 @@ func validateConfig(config Config) error {
 			return fmt.Errorf("user '%s' must have at least one item to search for", user.Name)
 		}
 
-		if user.Zipcode == "" {
+		if config.MaxItemsPerUser > 0 && len(user.Items) > config.MaxItemsPerUser {
+			return fmt.Errorf("user '%s' has %d items, more than the max_items_per_user limit of %d",
+				user.Name, len(user.Items), config.MaxItemsPerUser)
+		}
+
+		for j, item := range user.Items {
+			if strings.TrimSpace(item.Name) == "" {
+				return fmt.Errorf("user '%s' item %d must have a name", user.Name, j+1)
+			}
+			if item.Distance < 0 {
+				return fmt.Errorf("user '%s' item %q must not have a negative distance", user.Name, item.Name)
+			}
+			if item.MaxPrice < 0 {
+				return fmt.Errorf("user '%s' item %q must not have a negative max_price", user.Name, item.Name)
+			}
+		}
+
+		if user.Zipcode == "" && !user.Statewide {
 			return fmt.Errorf("user '%s' must have a zipcode specified", user.Name)
 		}
 
-		if user.Distance <= 0 {
-			return fmt.Errorf("user '%s' must have a positive distance", user.Name)
+		if user.Statewide && user.Distance != 0 {
+			return fmt.Errorf("user '%s' may set distance or statewide, but not both", user.Name)
+		}
+
+		if !user.Statewide && user.Distance <= 0 {
+			return fmt.Errorf("user '%s' must have a positive distance, or set statewide: true to search every store", user.Name)
+		}
+
+		if user.Zipcode == "" {
+			// Statewide users without a zipcode can't search items within a radius
+			for _, item := range user.Items {
+				if item.Distance > 0 {
+					return fmt.Errorf("user '%s' item %q sets a distance, which needs a zipcode", user.Name, item.Name)
+				}
+			}
+		}
+
+		if user.HeartbeatInterval < 0 {
+			return fmt.Errorf("user '%s' must not have a negative heartbeat_interval", user.Name)
+		}
+
+		if user.MinTotalStock < 0 {
+			return fmt.Errorf("user '%s' must not have a negative min_total_stock", user.Name)
+		}
+
+		if user.MinStoreStock < 0 {
+			return fmt.Errorf("user '%s' must not have a negative min_store_stock", user.Name)
+		}
+
+		if user.MinItemDelay < 0 || user.MaxItemDelay < 0 {
+			return fmt.Errorf("user '%s' must not have a negative min_item_delay or max_item_delay", user.Name)
+		}
+
+		if minDelay, maxDelay := user.EffectiveItemDelay(config.MinItemDelay, config.MaxItemDelay); minDelay > maxDelay {
+			return fmt.Errorf("user '%s' must not have a min_item_delay greater than max_item_delay", user.Name)
+		}
+
+		if user.Interval < 0 {
+			return fmt.Errorf("user '%s' must not have a negative interval", user.Name)
+		}
+
+		if user.Cron != "" {
+			if user.Interval > 0 {
+				return fmt.Errorf("user '%s' must set either interval or cron, not both", user.Name)
+			}
+			cron, err := schedule.Parse(user.Cron)
+			if err != nil {
+				return fmt.Errorf("user '%s' has an invalid cron: %w", user.Name, err)
+			}
+			if cron.Next(time.Now()).IsZero() {
+				return fmt.Errorf("user '%s' has a cron %q that never runs", user.Name, user.Cron)
+			}
+		}
+
+		for i, nc := range user.Notifications {
+			if _, ok := NotificationCredentials(nc.Type); !ok {
+				return fmt.Errorf("user '%s' notification %d has an unsupported type %q, must be one of: %s",
+					user.Name, i+1, nc.Type, strings.Join(NotificationTypes(), ", "))
+			}
+			if key := nc.MissingCredential(); key != "" {
+				return fmt.Errorf("user '%s' notification %d (%s) requires %s in credentials", user.Name, i+1, nc.Type, key)
+			}
+			if keys, _ := NotificationCredentials(nc.Type); keys.Endpoint && nc.Endpoint == "" {
+				return fmt.Errorf("user '%s' notification %d (%s) requires an endpoint", user.Name, i+1, nc.Type)
+			}
+
+			templates := []struct{ name, text string }{
+				{"subject_template", nc.SubjectTemplate},
+				{"body_template", nc.BodyTemplate},
+			}
+			for _, t := range templates {
+				if t.text == "" {
+					continue
+				}
+				if _, err := template.New(t.name).Parse(t.text); err != nil {
+					return fmt.Errorf("user '%s' notification %d (%s) has an invalid %s: %w", user.Name, i+1, nc.Type, t.name, err)
+				}
+			}
+		}
+
+		if user.Timezone != "" {
+			if _, err := time.LoadLocation(user.Timezone); err != nil {
+				return fmt.Errorf("user '%s' has an invalid timezone %q: %w", user.Name, user.Timezone, err)
+			}
+		}
+
+		if err := validateProxyURL(user.ProxyURL); err != nil {
+			return fmt.Errorf("user '%s' has an invalid proxy_url: %w", user.Name, err)
+		}
+
+		for item, alert := range user.ItemAlerts {
+			if _, ok := user.Item(item); !ok {
+				return fmt.Errorf("user '%s' has item_alerts for %q, which is not in their items", user.Name, item)
+			}
+			if alert.Priority < -2 || alert.Priority > 2 {
+				return fmt.Errorf("user '%s' has item_alerts priority %d for %q; must be between -2 and 2", user.Name, alert.Priority, item)
+			}
+		}
+
+		for item, term := range user.SearchTerms {
+			if _, ok := user.Item(item); !ok {
+				return fmt.Errorf("user '%s' has search_terms for %q, which is not in their items", user.Name, item)
+			}
+			if strings.TrimSpace(term) == "" {
+				return fmt.Errorf("user '%s' has an empty search_terms entry for %q", user.Name, item)
+			}
+		}
+
+		for item, limit := range user.PriceLimits {
+			if _, ok := user.Item(item); !ok {
+				return fmt.Errorf("user '%s' has price_limits for %q, which is not in their items", user.Name, item)
+			}
+			if limit.Min < 0 || limit.Max < 0 {
+				return fmt.Errorf("user '%s' has a negative price limit for %q", user.Name, item)
+			}
+			if limit.Max > 0 && limit.Min > limit.Max {
+				return fmt.Errorf("user '%s' has price_limits for %q with min %.2f above max %.2f", user.Name, item, limit.Min, limit.Max)
+			}
 		}
 	}
 
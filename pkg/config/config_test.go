@@ -1,10 +1,32 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// clearEnvConfig unsets all GFL_* environment variables for the duration of the test
+func clearEnvConfig(t *testing.T) {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(key, envPrefix) {
+			t.Setenv(key, "")
+			if err := os.Unsetenv(key); err != nil {
+				t.Fatalf("Failed to unset %s: %v", key, err)
+			}
+		}
+	}
+}
+
 func TestIsLegacyConfig(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -62,161 +84,1180 @@ func TestMigrateLegacyConfig(t *testing.T) {
 		expectName  string
 	}{
 		{
-			name: "Valid legacy config",
+			name: "Valid legacy config",
+			config: Config{
+				Items:    []string{"Blanton's", "Weller"},
+				Zipcode:  "97201",
+				Distance: 15,
+				Interval: 6 * time.Hour,
+				Verbose:  true,
+				Notifications: []NotificationConfig{
+					{Type: "gotify", Endpoint: "https://gotify.example.com"},
+				},
+			},
+			expectError: false,
+			expectName:  "default",
+		},
+		{
+			name: "Legacy config without items",
+			config: Config{
+				Zipcode:  "97201",
+				Distance: 15,
+			},
+			expectError: true,
+		},
+		{
+			name: "Legacy config without zipcode",
+			config: Config{
+				Items:    []string{"Blanton's"},
+				Distance: 15,
+			},
+			expectError: true,
+		},
+		{
+			name: "Legacy config with zero distance gets default",
+			config: Config{
+				Items:    []string{"Blanton's"},
+				Zipcode:  "97201",
+				Distance: 0,
+				Interval: 6 * time.Hour,
+			},
+			expectError: false,
+			expectName:  "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := migrateLegacyConfig(tt.config)
+
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if !tt.expectError {
+				if len(result.Users) != 1 {
+					t.Errorf("Expected 1 user, got %d", len(result.Users))
+				}
+				if result.Users[0].Name != tt.expectName {
+					t.Errorf("Expected user name %q, got %q", tt.expectName, result.Users[0].Name)
+				}
+				// Check that distance gets default value if zero
+				if tt.config.Distance == 0 && result.Users[0].Distance != 10 {
+					t.Errorf("Expected default distance 10, got %d", result.Users[0].Distance)
+				}
+			}
+		})
+	}
+}
+
+// TestMigrateLegacyConfigKeepsCommonItems tests that health check items set in a
+// legacy config survive the migration to the multi-user format
+func TestMigrateLegacyConfigKeepsCommonItems(t *testing.T) {
+	commonItems := []CommonItem{{Code: "99900046075", Name: "Tito's Handmade Vodka"}}
+	result, err := migrateLegacyConfig(Config{
+		Items:       []string{"Blanton's"},
+		Zipcode:     "97201",
+		CommonItems: commonItems,
+	})
+	if err != nil {
+		t.Fatalf("migrateLegacyConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.CommonItems, commonItems) {
+		t.Errorf("Expected common items %v, got %v", commonItems, result.CommonItems)
+	}
+}
+
+func TestMigrateConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "legacy.yaml")
+	legacy := `# Global settings
+interval: 6h
+verbose: true
+
+items:
+  - "Blanton's"
+  - "Weller"
+zipcode: "97201"
+notifications:
+  - type: gotify
+    endpoint: https://gotify.example.com
+    credential:
+      token: abc
+`
+	if err := os.WriteFile(legacyPath, []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	out, err := MigrateConfigFile(legacyPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var migrated Config
+	if err := yaml.Unmarshal(out, &migrated); err != nil {
+		t.Fatalf("Migrated config is not valid YAML: %v\n%s", err, out)
+	}
+	if isLegacyConfig(migrated) || len(migrated.Items) > 0 || migrated.Zipcode != "" {
+		t.Errorf("Expected no legacy fields in migrated config, got:\n%s", out)
+	}
+	if err := validateConfig(migrated); err != nil {
+		t.Errorf("Expected migrated config to be valid, got: %v", err)
+	}
+	if migrated.Interval != 6*time.Hour || !migrated.Verbose {
+		t.Errorf("Expected global settings to be kept, got interval=%s verbose=%t", migrated.Interval, migrated.Verbose)
+	}
+	if !strings.Contains(string(out), "# Global settings") {
+		t.Errorf("Expected comments on global settings to be kept, got:\n%s", out)
+	}
+
+	user := migrated.Users[0]
+	if user.Name != "default" || len(user.Items) != 2 || user.Zipcode != "97201" || user.Distance != 10 {
+		t.Errorf("Unexpected migrated user: %+v", user)
+	}
+	if len(user.Notifications) != 1 || user.Notifications[0].Credential["token"] != "abc" {
+		t.Errorf("Expected notifications to move to the user, got: %+v", user.Notifications)
+	}
+
+	// Migrating the result again is rejected as it's no longer legacy
+	migratedPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(migratedPath, out, 0600); err != nil {
+		t.Fatalf("Failed to write migrated config: %v", err)
+	}
+	if _, err := MigrateConfigFile(migratedPath); !errors.Is(err, ErrNotLegacyConfig) {
+		t.Errorf("Expected ErrNotLegacyConfig for a multi-user config, got: %v", err)
+	}
+}
+
+func TestItemConfigForms(t *testing.T) {
+	expected := []ItemConfig{
+		{Name: "Blanton's"},
+		{Name: "Weller 12", Distance: 50, MaxPrice: 49.99, Note: "shelf price only"},
+		{Name: "0171B"},
+	}
+
+	for _, tt := range []struct {
+		file string
+		data string
+	}{
+		{file: "config.yaml", data: `users:
+  - name: alice
+    zipcode: "97201"
+    distance: 10
+    items:
+      - "Blanton's"
+      - name: "Weller 12"
+        distance: 50
+        max_price: 49.99
+        note: "shelf price only"
+      - "0171B"
+`},
+		{file: "config.json", data: `{"users": [{"name": "alice", "zipcode": "97201", "distance": 10, "items": [
+  "Blanton's",
+  {"name": "Weller 12", "distance": 50, "max_price": 49.99, "note": "shelf price only"},
+  "0171B"
+]}]}`},
+		{file: "config.toml", data: `[[users]]
+name = "alice"
+zipcode = "97201"
+distance = 10
+items = [
+  "Blanton's",
+  { name = "Weller 12", distance = 50, max_price = 49.99, note = "shelf price only" },
+  "0171B",
+]
+`},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			SetConfigFile(path)
+			t.Cleanup(func() { SetConfigFile("") })
+
+			conf, err := loadConfigFile()
+			if err != nil {
+				t.Fatalf("loadConfigFile failed: %v", err)
+			}
+			if !reflect.DeepEqual(conf.Users[0].Items, expected) {
+				t.Errorf("Expected items %+v, got %+v", expected, conf.Users[0].Items)
+			}
+			if err := validateConfig(conf); err != nil {
+				t.Errorf("Expected config to be valid, got: %v", err)
+			}
+		})
+	}
+
+	// Items without options are written back as plain strings
+	out, err := yaml.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to marshal items: %v", err)
+	}
+	if !strings.HasPrefix(string(out), "- Blanton's\n- name: Weller 12\n") || !strings.HasSuffix(string(out), "- 0171B\n") {
+		t.Errorf("Expected name-only items as plain strings, got:\n%s", out)
+	}
+
+	var invalid []ItemConfig
+	if err := yaml.Unmarshal([]byte("- [nested, list]\n"), &invalid); err == nil {
+		t.Error("Expected an error for an item that is neither a string nor a mapping")
+	}
+}
+
+func TestLoadConfigFileStringItems(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{name: "multi-user", data: "users:\n  - name: default\n    zipcode: \"97201\"\n    distance: 10\n    items:\n      - \"Blanton's\"\n      - \"Weller\"\n"},
+		{name: "legacy", data: "zipcode: \"97201\"\nitems:\n  - \"Blanton's\"\n  - \"Weller\"\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			SetConfigFile(path)
+			t.Cleanup(func() { SetConfigFile("") })
+
+			conf, err := loadConfigFile()
+			if err != nil {
+				t.Fatalf("loadConfigFile failed: %v", err)
+			}
+			if isLegacyConfig(conf) {
+				if conf, err = migrateLegacyConfig(conf); err != nil {
+					t.Fatalf("migrateLegacyConfig failed: %v", err)
+				}
+			}
+			if want := NewItems("Blanton's", "Weller"); !reflect.DeepEqual(conf.Users[0].Items, want) {
+				t.Errorf("Expected items %+v, got %+v", want, conf.Users[0].Items)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "Valid config",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "No users",
+			config: Config{
+				Users: []UserConfig{},
+			},
+			expectError: true,
+			errorMsg:    "at least one user must be configured",
+		},
+		{
+			name: "User without name",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "must have a name",
+		},
+		{
+			name: "User without items",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "must have at least one item",
+		},
+		{
+			name: "User without zipcode",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "must have a zipcode",
+		},
+		{
+			name: "User with zero distance",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 0,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "must have a positive distance",
+		},
+		{
+			name: "User with negative interval",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Interval: -time.Hour,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "must not have a negative interval",
+		},
+		{
+			name: "User with invalid notification body template",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Notifications: []NotificationConfig{
+							{Type: "gotify", Endpoint: "https://gotify.example.com", Credential: map[string]string{"token": "test-token"}, BodyTemplate: "{{.Name"},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "notification 1 (gotify) has an invalid body_template",
+		},
+		{
+			name: "User with unsupported notification type",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Notifications: []NotificationConfig{
+							{Type: "gotify", Endpoint: "https://gotify.example.com", Credential: map[string]string{"token": "test-token"}},
+							{Type: "gotfy", Credential: map[string]string{"token": "test-token"}},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' notification 2 has an unsupported type \"gotfy\"",
+		},
+		{
+			name: "User notification missing a credential",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Notifications: []NotificationConfig{
+							{Type: "Pushover", Credential: map[string]string{"token": "test-token", "recipient_id": ""}},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' notification 1 (Pushover) requires recipient_id in credentials",
+		},
+		{
+			name: "User notification missing an endpoint",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Notifications: []NotificationConfig{
+							{Type: "apprise", Credential: map[string]string{"urls": "tgram://bottoken/12345"}},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' notification 1 (apprise) requires an endpoint",
+		},
+		{
+			name: "Gotify notification missing an endpoint",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Notifications: []NotificationConfig{
+							{Type: "gotify", Credential: map[string]string{"token": "test-token"}},
+						},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' notification 1 (gotify) requires an endpoint",
+		},
+		{
+			name: "User with cron",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Cron:     "0 8,18 * * *",
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "User with both interval and cron",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Interval: time.Hour,
+						Cron:     "0 8,18 * * *",
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "must set either interval or cron, not both",
+		},
+		{
+			name: "User with invalid cron",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Cron:     "0 25 * * *",
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "has an invalid cron",
+		},
+		{
+			name: "User with cron that never runs",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Cron:     "0 0 31 2 *",
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "never runs",
+		},
+		{
+			name: "User over max items per user",
+			config: Config{
+				MaxItemsPerUser: 2,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's", "Eagle Rare", "Weller"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "more than the max_items_per_user limit of 2",
+		},
+		{
+			name: "User at max items per user",
+			config: Config{
+				MaxItemsPerUser: 2,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's", "Eagle Rare"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Negative max items per user",
+			config: Config{
+				MaxItemsPerUser: -1,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "max_items_per_user must not be negative",
+		},
+		{
+			name: "Invalid on_notify_failure",
+			config: Config{
+				OnNotifyFailure: "email",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "on_notify_failure must be one of",
+		},
+		{
+			name: "Invalid product_name_case",
+			config: Config{
+				ProductNameCase: "upper",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "product_name_case must be one of",
+		},
+		{
+			name: "on_notify_failure file without a path",
+			config: Config{
+				OnNotifyFailure: "file",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "requires dead_letter_file or state_dir",
+		},
+		{
+			name: "on_notify_failure file in state dir",
+			config: Config{
+				OnNotifyFailure: "file",
+				StateDir:        "state",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Search term for an unknown item",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:        "user1",
+						Items:       NewItems("Blanton's"),
+						Zipcode:     "97201",
+						Distance:    10,
+						SearchTerms: map[string]string{"Eagle Rare": "eagle"},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "not in their items",
+		},
+		{
+			name: "Empty search term",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:        "user1",
+						Items:       NewItems("Blanton's"),
+						Zipcode:     "97201",
+						Distance:    10,
+						SearchTerms: map[string]string{"blanton's": " "},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "empty search_terms entry",
+		},
+		{
+			name: "Valid price limits",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:        "user1",
+						Items:       NewItems("Weller 12"),
+						Zipcode:     "97201",
+						Distance:    10,
+						PriceLimits: map[string]PriceLimit{"weller 12": {Min: 30, Max: 49.99}},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Price limits for an unknown item",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:        "user1",
+						Items:       NewItems("Blanton's"),
+						Zipcode:     "97201",
+						Distance:    10,
+						PriceLimits: map[string]PriceLimit{"Weller 12": {Max: 49.99}},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "not in their items",
+		},
+		{
+			name: "Price limit min above max",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:        "user1",
+						Items:       NewItems("Weller 12"),
+						Zipcode:     "97201",
+						Distance:    10,
+						PriceLimits: map[string]PriceLimit{"Weller 12": {Min: 60, Max: 49.99}},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "above max",
+		},
+		{
+			name: "Negative price limit",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:        "user1",
+						Items:       NewItems("Weller 12"),
+						Zipcode:     "97201",
+						Distance:    10,
+						PriceLimits: map[string]PriceLimit{"Weller 12": {Max: -1}},
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "negative price limit",
+		},
+		{
+			name: "Item without a name",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    []ItemConfig{{Name: "Blanton's"}, {Distance: 50}},
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "item 2 must have a name",
+		},
+		{
+			name: "Negative item distance",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    []ItemConfig{{Name: "Blanton's", Distance: -5}},
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "negative distance",
+		},
+		{
+			name: "Statewide user",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:      "user1",
+						Items:     NewItems("Blanton's"),
+						Zipcode:   "97201",
+						Statewide: true,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Zero distance without statewide",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:    "user1",
+						Items:   NewItems("Blanton's"),
+						Zipcode: "97201",
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' must have a positive distance, or set statewide: true",
+		},
+		{
+			name: "Statewide user without zipcode",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:      "user1",
+						Items:     NewItems("Blanton's"),
+						Statewide: true,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Statewide user without zipcode with item distance",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:      "user1",
+						Items:     []ItemConfig{{Name: "Blanton's", Distance: 10}},
+						Statewide: true,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' item \"Blanton's\" sets a distance, which needs a zipcode",
+		},
+		{
+			name: "Statewide user with distance",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:      "user1",
+						Items:     NewItems("Blanton's"),
+						Zipcode:   "97201",
+						Distance:  10,
+						Statewide: true,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "may set distance or statewide, but not both",
+		},
+		{
+			name: "Negative max connections",
+			config: Config{
+				MaxConnections: -1,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "max_connections must not be negative",
+		},
+		{
+			name: "Negative max concurrent searches",
+			config: Config{
+				MaxConcurrentSearches: -1,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "max_concurrent_searches must not be negative",
+		},
+		{
+			name: "Negative circuit breaker threshold",
+			config: Config{
+				CircuitBreakerThreshold: -1,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "circuit_breaker_threshold must not be negative",
+		},
+		{
+			name: "Negative notify retry delay",
+			config: Config{
+				NotifyRetryDelay: -time.Second,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "notify_retry_delay must not be negative",
+		},
+		{
+			name: "Min item delay above max",
+			config: Config{
+				MinItemDelay: time.Minute,
+				MaxItemDelay: 30 * time.Second,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "min_item_delay must not be greater than max_item_delay",
+		},
+		{
+			name: "User min item delay above global max",
+			config: Config{
+				MaxItemDelay: 30 * time.Second,
+				Users: []UserConfig{
+					{
+						Name:         "user1",
+						Items:        NewItems("Blanton's"),
+						Zipcode:      "97201",
+						Distance:     10,
+						MinItemDelay: time.Minute,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' must not have a min_item_delay greater than max_item_delay",
+		},
+		{
+			name: "Invalid heartbeat template",
+			config: Config{
+				HeartbeatTemplate: "{{.Users",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "invalid heartbeat_template",
+		},
+		{
+			name: "Invalid user agent rotation",
+			config: Config{
+				UserAgentRotation: "hourly",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user_agent_rotation must be one of",
+		},
+		{
+			name: "Invalid user timezone",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+						Timezone: "Mars/Olympus_Mons",
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "has an invalid timezone",
+		},
+		{
+			name: "Item alert for unknown item",
 			config: Config{
-				Items:    []string{"Blanton's", "Weller"},
-				Zipcode:  "97201",
-				Distance: 15,
-				Interval: 6 * time.Hour,
-				Verbose:  true,
-				Notifications: []NotificationConfig{
-					{Type: "gotify", Endpoint: "https://gotify.example.com"},
+				Users: []UserConfig{
+					{
+						Name:       "user1",
+						Items:      NewItems("Blanton's"),
+						Zipcode:    "97201",
+						Distance:   10,
+						ItemAlerts: map[string]ItemAlert{"Weller": {Sound: "siren"}},
+					},
 				},
 			},
-			expectError: false,
-			expectName:  "default",
+			expectError: true,
+			errorMsg:    "not in their items",
 		},
 		{
-			name: "Legacy config without items",
+			name: "Item alert priority out of range",
 			config: Config{
-				Zipcode:  "97201",
-				Distance: 15,
+				Users: []UserConfig{
+					{
+						Name:       "user1",
+						Items:      NewItems("Blanton's"),
+						Zipcode:    "97201",
+						Distance:   10,
+						ItemAlerts: map[string]ItemAlert{"blanton's": {Priority: 3}},
+					},
+				},
 			},
 			expectError: true,
+			errorMsg:    "must be between -2 and 2",
 		},
 		{
-			name: "Legacy config without zipcode",
+			name: "Negative dry spell alert",
 			config: Config{
-				Items:    []string{"Blanton's"},
-				Distance: 15,
+				DrySpellAlert: -time.Hour,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
 			},
 			expectError: true,
+			errorMsg:    "dry_spell_alert must not be negative",
 		},
 		{
-			name: "Legacy config with zero distance gets default",
+			name: "Negative shutdown timeout",
 			config: Config{
-				Items:    []string{"Blanton's"},
-				Zipcode:  "97201",
-				Distance: 0,
-				Interval: 6 * time.Hour,
+				ShutdownTimeout: -time.Second,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
 			},
-			expectError: false,
-			expectName:  "default",
+			expectError: true,
+			errorMsg:    "shutdown_timeout must not be negative",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := migrateLegacyConfig(tt.config)
-
-			if tt.expectError && err == nil {
-				t.Errorf("Expected error but got none")
-			}
-			if !tt.expectError && err != nil {
-				t.Errorf("Unexpected error: %v", err)
-			}
-
-			if !tt.expectError {
-				if len(result.Users) != 1 {
-					t.Errorf("Expected 1 user, got %d", len(result.Users))
-				}
-				if result.Users[0].Name != tt.expectName {
-					t.Errorf("Expected user name %q, got %q", tt.expectName, result.Users[0].Name)
-				}
-				// Check that distance gets default value if zero
-				if tt.config.Distance == 0 && result.Users[0].Distance != 10 {
-					t.Errorf("Expected default distance 10, got %d", result.Users[0].Distance)
-				}
-			}
-		})
-	}
-}
-
-func TestValidateConfig(t *testing.T) {
-	tests := []struct {
-		name        string
-		config      Config
-		expectError bool
-		errorMsg    string
-	}{
 		{
-			name: "Valid config",
+			name: "Non-local status address",
 			config: Config{
+				StatusAddr: ":9092",
 				Users: []UserConfig{
 					{
 						Name:     "user1",
-						Items:    []string{"Blanton's"},
+						Items:    NewItems("Blanton's"),
 						Zipcode:  "97201",
 						Distance: 10,
 					},
 				},
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    "status_addr must be a localhost address",
 		},
 		{
-			name: "No users",
+			name: "Unknown log format",
 			config: Config{
-				Users: []UserConfig{},
+				LogFormat: "xml",
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
 			},
 			expectError: true,
-			errorMsg:    "at least one user must be configured",
+			errorMsg:    "log_format must be one of text, json",
 		},
 		{
-			name: "User without name",
+			name: "Unsupported proxy scheme",
 			config: Config{
+				ProxyURL: "ftp://proxy.example.com:21",
 				Users: []UserConfig{
 					{
-						Items:    []string{"Blanton's"},
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
 						Zipcode:  "97201",
 						Distance: 10,
 					},
 				},
 			},
 			expectError: true,
-			errorMsg:    "must have a name",
+			errorMsg:    "invalid proxy_url: scheme must be http, https, socks5 or socks5h",
 		},
 		{
-			name: "User without items",
+			name: "User proxy without host",
 			config: Config{
+				ProxyURL: "socks5://proxy.example.com:1080",
 				Users: []UserConfig{
 					{
 						Name:     "user1",
+						Items:    NewItems("Blanton's"),
 						Zipcode:  "97201",
 						Distance: 10,
+						ProxyURL: "http://",
 					},
 				},
 			},
 			expectError: true,
-			errorMsg:    "must have at least one item",
+			errorMsg:    "user 'user1' has an invalid proxy_url: missing proxy host",
 		},
 		{
-			name: "User without zipcode",
+			name: "Negative max list products",
 			config: Config{
+				MaxListProducts: -1,
 				Users: []UserConfig{
 					{
 						Name:     "user1",
-						Items:    []string{"Blanton's"},
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
 						Distance: 10,
 					},
 				},
 			},
 			expectError: true,
-			errorMsg:    "must have a zipcode",
+			errorMsg:    "max_list_products must not be negative",
 		},
 		{
-			name: "User with zero distance",
+			name: "Negative search cache TTL",
 			config: Config{
+				SearchCacheTTL: -time.Minute,
 				Users: []UserConfig{
 					{
 						Name:     "user1",
-						Items:    []string{"Blanton's"},
+						Items:    NewItems("Blanton's"),
 						Zipcode:  "97201",
-						Distance: 0,
+						Distance: 10,
 					},
 				},
 			},
 			expectError: true,
-			errorMsg:    "must have a positive distance",
+			errorMsg:    "search_cache_ttl must not be negative",
+		},
+		{
+			name: "Negative user heartbeat interval",
+			config: Config{
+				Users: []UserConfig{
+					{
+						Name:              "user1",
+						Items:             NewItems("Blanton's"),
+						Zipcode:           "97201",
+						Distance:          10,
+						HeartbeatInterval: -time.Hour,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "user 'user1' must not have a negative heartbeat_interval",
+		},
+		{
+			name: "Negative failure alert cooldown",
+			config: Config{
+				FailureAlert:         3,
+				FailureAlertCooldown: -time.Hour,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "failure_alert_cooldown must not be negative",
+		},
+		{
+			name: "Negative condense group stores",
+			config: Config{
+				CondenseGroupStores: -1,
+				Users: []UserConfig{
+					{
+						Name:     "user1",
+						Items:    NewItems("Blanton's"),
+						Zipcode:  "97201",
+						Distance: 10,
+					},
+				},
+			},
+			expectError: true,
+			errorMsg:    "condense_group_stores must not be negative",
 		},
 	}
 
@@ -230,6 +1271,9 @@ func TestValidateConfig(t *testing.T) {
 			if !tt.expectError && err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
+			if tt.expectError && err != nil && !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got: %v", tt.errorMsg, err)
+			}
 		})
 	}
 }
@@ -264,7 +1308,7 @@ func TestUserConfigStructure(t *testing.T) {
 	// Test that UserConfig has all required fields
 	user := UserConfig{
 		Name:     "test_user",
-		Items:    []string{"Blanton's", "Weller"},
+		Items:    NewItems("Blanton's", "Weller"),
 		Zipcode:  "97201",
 		Distance: 15,
 		Notifications: []NotificationConfig{
@@ -313,7 +1357,7 @@ func TestMultiUserConfigStructure(t *testing.T) {
 		Users: []UserConfig{
 			{
 				Name:     "user1",
-				Items:    []string{"Blanton's"},
+				Items:    NewItems("Blanton's"),
 				Zipcode:  "97201",
 				Distance: 10,
 				Notifications: []NotificationConfig{
@@ -322,7 +1366,7 @@ func TestMultiUserConfigStructure(t *testing.T) {
 			},
 			{
 				Name:     "user2",
-				Items:    []string{"Weller"},
+				Items:    NewItems("Weller"),
 				Zipcode:  "97210",
 				Distance: 15,
 				Notifications: []NotificationConfig{
@@ -395,7 +1439,7 @@ func TestConfigCommonItemsField(t *testing.T) {
 			{Code: "99900014675", Name: "Jack Daniels #7 Whiskey"},
 		},
 		Users: []UserConfig{
-			{Name: "user1", Items: []string{"Blanton's"}, Zipcode: "97201", Distance: 10},
+			{Name: "user1", Items: NewItems("Blanton's"), Zipcode: "97201", Distance: 10},
 		},
 	}
 
@@ -416,7 +1460,7 @@ func TestConfigCommonItemsEmpty(t *testing.T) {
 	config := Config{
 		Interval: 6 * time.Hour,
 		Users: []UserConfig{
-			{Name: "user1", Items: []string{"Blanton's"}, Zipcode: "97201", Distance: 10},
+			{Name: "user1", Items: NewItems("Blanton's"), Zipcode: "97201", Distance: 10},
 		},
 	}
 
@@ -424,3 +1468,375 @@ func TestConfigCommonItemsEmpty(t *testing.T) {
 		t.Errorf("Expected 0 common items when not configured, got %d", len(config.CommonItems))
 	}
 }
+
+func TestGetConfigNoConfigSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, dir string)
+		expectNoCfg bool
+	}{
+		{
+			name:        "No config file and no env vars",
+			setup:       func(t *testing.T, dir string) {},
+			expectNoCfg: true,
+		},
+		{
+			name: "Env vars present but invalid",
+			setup: func(t *testing.T, dir string) {
+				t.Setenv("GFL_ZIPCODE", "97201")
+			},
+			expectNoCfg: false,
+		},
+		{
+			name: "Config file present but invalid",
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("interval: 6h\n"), 0600); err != nil {
+					t.Fatalf("Failed to write config file: %v", err)
+				}
+			},
+			expectNoCfg: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnvConfig(t)
+			dir := t.TempDir()
+			t.Chdir(dir)
+			SetConfigFile("")
+			tt.setup(t, dir)
+
+			_, err := GetConfig()
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+
+			if errors.Is(err, ErrNoConfig) != tt.expectNoCfg {
+				t.Errorf("Expected errors.Is(err, ErrNoConfig) to be %v, got error: %v", tt.expectNoCfg, err)
+			}
+
+			if tt.expectNoCfg && !strings.Contains(err.Error(), filepath.Join(dir, "config.yaml")) {
+				t.Errorf("Expected error to mention searched config path, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetConfigMaxItemsPerUserOverride(t *testing.T) {
+	clearEnvConfig(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	yamlConfig := `max_items_per_user: 1
+users:
+  - name: alice
+    items: ["Blanton's", "Eagle Rare"]
+    zipcode: "97201"
+    distance: 10
+`
+	if err := os.WriteFile(path, []byte(yamlConfig), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	SetConfigFile(path)
+	t.Cleanup(func() {
+		SetConfigFile("")
+		maxItemsPerUser = nil
+	})
+
+	if _, err := GetConfig(); err == nil || !strings.Contains(err.Error(), "max_items_per_user") {
+		t.Errorf("Expected config over max_items_per_user to be rejected, got: %v", err)
+	}
+
+	SetMaxItemsPerUser(2)
+	conf, err := GetConfig()
+	if err != nil {
+		t.Fatalf("Expected flag override to allow 2 items, got: %v", err)
+	}
+	if conf.MaxItemsPerUser != 2 {
+		t.Errorf("Expected MaxItemsPerUser 2, got %d", conf.MaxItemsPerUser)
+	}
+}
+
+func TestGetConfigJitterDefaults(t *testing.T) {
+	tests := []struct {
+		name            string
+		extra           string
+		expectedStartup time.Duration
+		expectedTick    time.Duration
+	}{
+		{name: "Defaults when unset", expectedStartup: DefaultStartupJitter, expectedTick: DefaultTickJitter},
+		{name: "Zero disables", extra: "startup_jitter: 0s\ntick_jitter: 0s\n", expectedStartup: 0, expectedTick: 0},
+		{name: "Configured", extra: "startup_jitter: 5m\ntick_jitter: 30s\n", expectedStartup: 5 * time.Minute, expectedTick: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnvConfig(t)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			yamlConfig := tt.extra + `users:
+  - name: alice
+    items: ["Blanton's"]
+    zipcode: "97201"
+    distance: 10
+`
+			if err := os.WriteFile(path, []byte(yamlConfig), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			SetConfigFile(path)
+			t.Cleanup(func() { SetConfigFile("") })
+
+			conf, err := GetConfig()
+			if err != nil {
+				t.Fatalf("GetConfig failed: %v", err)
+			}
+			if conf.StartupJitter != tt.expectedStartup || conf.TickJitter != tt.expectedTick {
+				t.Errorf("Expected jitter %s/%s, got %s/%s",
+					tt.expectedStartup, tt.expectedTick, conf.StartupJitter, conf.TickJitter)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFormats(t *testing.T) {
+	expected := Config{
+		Interval:      6 * time.Hour,
+		Verbose:       true,
+		StartupJitter: 0,
+		TickJitter:    30 * time.Second,
+		MaxItemDelay:  DefaultMaxItemDelay,
+		MissingPrice:  "(price N/A)",
+		Users: []UserConfig{
+			{
+				Name:     "alice",
+				Items:    NewItems("Blanton's", "Eagle Rare"),
+				Zipcode:  "97201",
+				Distance: 10,
+				Interval: 2 * time.Hour,
+				Notifications: []NotificationConfig{
+					{Type: "gotify", Endpoint: "https://gotify.example.com", Credential: map[string]string{"token": "abc"}, Condense: true},
+					{Type: "telegram", Credential: map[string]string{"token": "t", "chat_id": "12345"}},
+				},
+			},
+			{
+				Name:     "bob",
+				Items:    NewItems("0171B"),
+				Zipcode:  "97401",
+				Distance: 25,
+				ItemAlerts: map[string]ItemAlert{
+					"0171B": {Priority: 2, Sound: "siren"},
+				},
+			},
+		},
+	}
+
+	// Marshal expected into a generic document without empty lists and maps,
+	// which would otherwise decode as empty rather than nil
+	data, err := yaml.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to marshal YAML: %v", err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to unmarshal YAML: %v", err)
+	}
+	doc = pruneEmpty(doc)
+
+	yamlData, err := yaml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal YAML: %v", err)
+	}
+	jsonData, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal JSON: %v", err)
+	}
+	tomlData := []byte(`# Global settings
+interval = "6h"
+verbose = true
+startup_jitter = "0s"
+tick_jitter = '30s'
+missing_price = "(price N/A)"
+
+[[users]]
+name = "alice"
+items = [
+  "Blanton's",
+  "Eagle Rare", # trailing comma allowed
+]
+zipcode = "97201"
+distance = 10
+interval = "2h"
+
+[[users.notifications]]
+type = "gotify"
+endpoint = "https://gotify.example.com"
+credential = { token = "abc" }
+condense = true
+
+[[users.notifications]]
+type = "telegram"
+credential.token = "t"
+credential.chat_id = "12345"
+
+[[users]]
+name = "bob"
+items = ["0171B"]
+zipcode = "97401"
+distance = 25
+
+[users.item_alerts."0171B"]
+priority = 2
+sound = "siren"
+`)
+
+	for _, tt := range []struct {
+		file string
+		data []byte
+	}{
+		{file: "config.yaml", data: yamlData},
+		{file: "config.yml", data: yamlData},
+		{file: "config.json", data: jsonData},
+		{file: "config.toml", data: tomlData},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, tt.data, 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			SetConfigFile(path)
+			t.Cleanup(func() { SetConfigFile("") })
+
+			conf, err := loadConfigFile()
+			if err != nil {
+				t.Fatalf("loadConfigFile failed: %v", err)
+			}
+			if !reflect.DeepEqual(conf, expected) {
+				t.Errorf("Expected config %+v, got %+v", expected, conf)
+			}
+		})
+	}
+}
+
+// pruneEmpty removes nil values and empty lists and maps from a generic document
+func pruneEmpty(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			value = pruneEmpty(value)
+			if value == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = value
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, value := range v {
+			v[i] = pruneEmpty(value)
+		}
+	}
+	return v
+}
+
+func TestDecodeConfigTOMLItems(t *testing.T) {
+	data := []byte(`[[users]]
+name = "alice"
+items = [
+  "Blanton's",
+  { name = "Eagle Rare", distance = 5, max_price = 40, note = "for dad" },
+]
+`)
+	var conf Config
+	if err := decodeConfig("config.toml", data, &conf); err != nil {
+		t.Fatalf("decodeConfig failed: %v", err)
+	}
+	expected := []ItemConfig{
+		{Name: "Blanton's"},
+		{Name: "Eagle Rare", Distance: 5, MaxPrice: 40, Note: "for dad"},
+	}
+	if len(conf.Users) != 1 || !reflect.DeepEqual(conf.Users[0].Items, expected) {
+		t.Errorf("Expected items %+v, got %+v", expected, conf.Users)
+	}
+
+	conf = Config{}
+	err := decodeConfig("config.toml", []byte("[[users]]\nitems = [42]\n"), &conf)
+	if err == nil || !strings.Contains(err.Error(), "item must be a string or a table") {
+		t.Errorf("Expected an item type error, got %v", err)
+	}
+}
+
+// TestConfigTOMLTags checks that every field decoded from YAML is decoded
+// from TOML under the same key
+func TestConfigTOMLTags(t *testing.T) {
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[Config](),
+		reflect.TypeFor[UserConfig](),
+		reflect.TypeFor[NotificationConfig](),
+		reflect.TypeFor[ItemConfig](),
+		reflect.TypeFor[ItemAlert](),
+		reflect.TypeFor[PriceLimit](),
+		reflect.TypeFor[CommonItem](),
+	} {
+		for field := range typ.Fields() {
+			yamlTag, ok := field.Tag.Lookup("yaml")
+			if !ok {
+				continue
+			}
+			if tomlTag := field.Tag.Get("toml"); tomlTag != yamlTag {
+				t.Errorf("%s.%s: toml tag %q does not match yaml tag %q", typ.Name(), field.Name, tomlTag, yamlTag)
+			}
+		}
+	}
+}
+
+func TestGetConfigDiscoversConfigFormats(t *testing.T) {
+	for _, file := range []string{"config.json", "config.toml"} {
+		t.Run(file, func(t *testing.T) {
+			clearEnvConfig(t)
+			dir := t.TempDir()
+			t.Chdir(dir)
+			SetConfigFile("")
+
+			data := `{"users": [{"name": "alice", "items": ["Blanton's"], "zipcode": "97201", "distance": 10}]}`
+			if strings.HasSuffix(file, ".toml") {
+				data = "[[users]]\nname = \"alice\"\nitems = [\"Blanton's\"]\nzipcode = \"97201\"\ndistance = 10\n"
+			}
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			conf, err := GetConfig()
+			if err != nil {
+				t.Fatalf("GetConfig failed: %v", err)
+			}
+			if len(conf.Users) != 1 || conf.Users[0].Name != "alice" {
+				t.Errorf("Expected user alice from %s, got %+v", file, conf.Users)
+			}
+		})
+	}
+}
+
+func TestGetConfigInvalidFileFormats(t *testing.T) {
+	for file, data := range map[string]string{
+		"config.json": `{"users": [}`,
+		"config.toml": "[[users]\nname = \"alice\"\n",
+	} {
+		t.Run(file, func(t *testing.T) {
+			clearEnvConfig(t)
+			path := filepath.Join(t.TempDir(), file)
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			SetConfigFile(path)
+			t.Cleanup(func() { SetConfigFile("") })
+
+			format := strings.ToUpper(strings.TrimPrefix(filepath.Ext(file), "."))
+			if _, err := GetConfig(); err == nil || !strings.Contains(err.Error(), "failed to unmarshal "+format+" config") {
+				t.Errorf("Expected %s parse error, got: %v", format, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// CredentialKeys lists the credential keys a notification type reads
+type CredentialKeys struct {
+	// Required keys must be set to a non-empty value
+	Required []string
+	// Optional keys may be left out
+	Optional []string
+	// Endpoint is set for types that send to the notification's endpoint, which must be set
+	Endpoint bool
+}
+
+// notificationCredentials holds the credential keys of every supported
+// notification type. It is the one list of supported types: the notification
+// manager checks required keys against it, and JSONSchema describes it.
+var notificationCredentials = map[string]CredentialKeys{
+	"gotify":     {Required: []string{"token"}, Optional: []string{"content_type", "priority"}, Endpoint: true},
+	"slack":      {Required: []string{"token", "channel_id"}},
+	"telegram":   {Required: []string{"token", "chat_id"}},
+	"discord":    {Required: []string{"token", "channel_id"}},
+	"pushover":   {Required: []string{"token", "recipient_id"}},
+	"email":      {Required: []string{"smtp_host", "smtp_port", "username", "password", "from", "to"}, Optional: []string{"tls_mode"}},
+	"ntfy":       {Required: []string{"topic"}, Optional: []string{"endpoint", "token", "priority", "tags"}},
+	"webhook":    {Required: []string{"url"}, Optional: []string{"method", "timeout"}},
+	"teams":      {Required: []string{"webhook_url"}},
+	"mattermost": {Required: []string{"webhook_url"}},
+	"matrix":     {Required: []string{"homeserver", "user_id", "room_id", "access_token"}},
+	"pushbullet": {Required: []string{"token", "device_nickname"}},
+	"apprise":    {Required: []string{"urls"}, Endpoint: true},
+}
+
+// NotificationTypes returns the supported notification types, sorted
+func NotificationTypes() []string {
+	types := make([]string, 0, len(notificationCredentials))
+	for t := range notificationCredentials {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// NotificationCredentials returns the credential keys of a notification type,
+// and false if the type isn't supported
+func NotificationCredentials(notificationType string) (CredentialKeys, bool) {
+	keys, ok := notificationCredentials[strings.ToLower(notificationType)]
+	return keys, ok
+}
+
+// MissingCredential returns the first required credential key the
+// notification doesn't set, or "" if none is missing or its type is unknown
+func (nc NotificationConfig) MissingCredential() string {
+	keys, _ := NotificationCredentials(nc.Type)
+	for _, key := range keys.Required {
+		if nc.Credential[key] == "" {
+			return key
+		}
+	}
+	return ""
+}
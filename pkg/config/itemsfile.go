@@ -0,0 +1,78 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ItemsFilePath returns the absolute path of a user's items_file, which is
+// relative to the directory of the config file at configPath
+func ItemsFilePath(configPath, itemsFile string) (string, error) {
+	absConfigPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+	return filepath.Join(filepath.Dir(absConfigPath), itemsFile), nil
+}
+
+// loadItemsFiles adds the items listed in each user's items_file to the
+// user's inline items. Items files are read through a root scoped to the
+// config file's directory, so they can't point outside it.
+func loadItemsFiles(config *Config, configPath string) error {
+	absConfigPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+
+	var root *os.Root
+	for i, user := range config.Users {
+		if user.ItemsFile == "" {
+			continue
+		}
+		if root == nil {
+			root, err = os.OpenRoot(filepath.Dir(absConfigPath))
+			if err != nil {
+				return fmt.Errorf("failed to create secure root filesystem: %w", err)
+			}
+			defer root.Close()
+		}
+
+		data, err := root.ReadFile(filepath.Clean(user.ItemsFile))
+		if err != nil {
+			return fmt.Errorf("failed to read items file for user '%s': %w", user.Name, err)
+		}
+		config.Users[i].Items = mergeItems(user.Items, parseItemsFile(data))
+	}
+	return nil
+}
+
+// parseItemsFile returns the items of an items file: one per line, skipping
+// blank lines and # comments
+func parseItemsFile(data []byte) []ItemConfig {
+	var items []ItemConfig
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, ItemConfig{Name: line})
+	}
+	return items
+}
+
+// mergeItems returns the inline items followed by the file items not already
+// listed inline, so an item's inline options win
+func mergeItems(inline, fromFile []ItemConfig) []ItemConfig {
+	merged := append([]ItemConfig(nil), inline...)
+	for _, item := range fromFile {
+		if _, ok := (UserConfig{Items: merged}).Item(item.Name); !ok {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseItemsFile(t *testing.T) {
+	data := []byte(`# Bourbon
+Blanton's
+
+  Eagle Rare  
+# Rye
+99900733075
+`)
+	expected := NewItems("Blanton's", "Eagle Rare", "99900733075")
+	if got := parseItemsFile(data); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected items %v, got %v", expected, got)
+	}
+}
+
+func TestMergeItems(t *testing.T) {
+	inline := []ItemConfig{{Name: "Blanton's", MaxPrice: 80}, {Name: "Weller 12"}}
+	fromFile := NewItems("blanton's", "Eagle Rare")
+
+	expected := []ItemConfig{{Name: "Blanton's", MaxPrice: 80}, {Name: "Weller 12"}, {Name: "Eagle Rare"}}
+	if got := mergeItems(inline, fromFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected items %v, got %v", expected, got)
+	}
+}
+
+func TestGetConfigItemsFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		itemsFile string
+		expected  []string
+		errorMsg  string
+	}{
+		{name: "Merged with inline items", itemsFile: "items.txt", expected: []string{"Blanton's", "Eagle Rare", "Weller 12"}},
+		{name: "Subdirectory", itemsFile: "lists/items.txt", expected: []string{"Blanton's", "Pappy Van Winkle"}},
+		{name: "Missing file", itemsFile: "missing.txt", errorMsg: "failed to read items file for user 'alice'"},
+		{name: "Outside the config directory", itemsFile: "../items.txt", errorMsg: "failed to read items file for user 'alice'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnvConfig(t)
+			dir := filepath.Join(t.TempDir(), "config")
+			writeFile(t, filepath.Join(dir, "items.txt"), "# Watch list\nEagle Rare\nBlanton's\n\nWeller 12\n")
+			writeFile(t, filepath.Join(dir, "lists", "items.txt"), "Pappy Van Winkle\n")
+			writeFile(t, filepath.Join(dir, "..", "items.txt"), "Eagle Rare\n")
+			path := filepath.Join(dir, "config.yaml")
+			writeFile(t, path, `users:
+  - name: alice
+    items: ["Blanton's"]
+    items_file: `+tt.itemsFile+`
+    zipcode: "97201"
+    distance: 10
+`)
+			SetConfigFile(path)
+			t.Cleanup(func() { SetConfigFile("") })
+
+			conf, err := GetConfig()
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error containing %q, got: %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetConfig failed: %v", err)
+			}
+			if got := conf.Users[0].ItemNames(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected items %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// writeFile writes content to path, creating its directory
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// schemaURL is the JSON Schema draft the generated schema follows
+const schemaURL = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches a Go duration string such as "6h" or "1h30m"
+const durationPattern = `^(0|[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	itemType     = reflect.TypeFor[ItemConfig]()
+	notifyType   = reflect.TypeFor[NotificationConfig]()
+)
+
+// JSONSchema returns a JSON Schema describing the config file, for editor
+// completion and validation. It is generated from the yaml tags of Config and
+// the types it holds, and lists the credential keys each notification type
+// requires. Value checks beyond types, like ranges, are left to validation.
+func JSONSchema() ([]byte, error) {
+	g := schemaGenerator{defs: make(map[string]any)}
+	schema := g.object(reflect.TypeFor[Config]())
+	schema["$schema"] = schemaURL
+	schema["title"] = "go-find-liquor configuration"
+	schema["$defs"] = g.defs
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaGenerator builds schemas for config types, placing every struct type
+// other than the root under $defs so types used in several places are shared
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// typeSchema returns the schema for values of type t
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]any {
+	switch {
+	case t == durationType:
+		// Durations are written as strings like "6h", or as nanoseconds
+		return map[string]any{"type": []string{"string", "integer"}, "pattern": durationPattern}
+	case t == itemType:
+		return g.ref(t, g.itemSchema)
+	case t == notifyType:
+		return g.ref(t, g.notificationSchema)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t, g.object)
+	default:
+		return map[string]any{}
+	}
+}
+
+// ref returns a reference to the schema of struct type t, adding it to $defs
+// with build the first time t is seen
+func (g *schemaGenerator) ref(t reflect.Type, build func(reflect.Type) map[string]any) map[string]any {
+	if _, ok := g.defs[t.Name()]; !ok {
+		// Reserve the name first in case the type refers to itself
+		g.defs[t.Name()] = nil
+		g.defs[t.Name()] = build(t)
+	}
+	return map[string]any{"$ref": "#/$defs/" + t.Name()}
+}
+
+// object returns the schema of a struct, with a property per yaml tag
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		properties[name] = g.typeSchema(field.Type)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// itemSchema returns the schema of a watched item: its name or code as a
+// plain string, or a mapping with options
+func (g *schemaGenerator) itemSchema(t reflect.Type) map[string]any {
+	object := g.object(t)
+	object["required"] = []string{"name"}
+	return map[string]any{"anyOf": []any{map[string]any{"type": "string"}, object}}
+}
+
+// notificationSchema returns the schema of a notification, requiring the
+// credential keys of its type
+func (g *schemaGenerator) notificationSchema(t reflect.Type) map[string]any {
+	schema := g.object(t)
+	schema["required"] = []string{"type"}
+	types := NotificationTypes()
+	schema["properties"].(map[string]any)["type"] = map[string]any{"type": "string", "enum": types}
+
+	var rules []any
+	for _, notificationType := range types {
+		keys := notificationCredentials[notificationType]
+		credential := make(map[string]any)
+		for _, key := range slices.Concat(keys.Required, keys.Optional) {
+			credential[key] = map[string]any{"type": "string"}
+		}
+		required := []string{"credential"}
+		if keys.Endpoint {
+			required = append(required, "endpoint")
+		}
+		rules = append(rules, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"type": map[string]any{"const": notificationType}},
+			},
+			"then": map[string]any{
+				"required": required,
+				"properties": map[string]any{
+					"credential": map[string]any{
+						"type":       "object",
+						"properties": credential,
+						"required":   keys.Required,
+					},
+				},
+			},
+		})
+	}
+	schema["allOf"] = rules
+	return schema
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validateSchema checks value against the subset of JSON Schema that
+// JSONSchema generates, returning the first violation found
+func validateSchema(root, schema map[string]any, value any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		defs := root["$defs"].(map[string]any)
+		return validateSchema(root, defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), value, path)
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		return fmt.Errorf("%s: %v does not have type %v", path, value, types)
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+	}
+	if c, ok := schema["const"]; ok && c != value {
+		return fmt.Errorf("%s: %v is not %v", path, value, c)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if s, isString := value.(string); isString && !regexp.MustCompile(pattern).MatchString(s) {
+			return fmt.Errorf("%s: %q does not match %s", path, s, pattern)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if validateSchema(root, sub.(map[string]any), value, path) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: %v matches none of anyOf", path, value)
+		}
+	}
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			sub := sub.(map[string]any)
+			if cond, ok := sub["if"].(map[string]any); ok {
+				if validateSchema(root, cond, value, path) != nil {
+					continue
+				}
+				sub = sub["then"].(map[string]any)
+			}
+			if err := validateSchema(root, sub, value, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for _, key := range toStrings(schema["required"]) {
+			if s, ok := v[key]; !ok || s == "" {
+				return fmt.Errorf("%s: missing required %s", path, key)
+			}
+		}
+		for key, item := range v {
+			sub, ok := properties[key].(map[string]any)
+			if !ok {
+				if extra, isSchema := schema["additionalProperties"].(map[string]any); isSchema {
+					sub = extra
+				} else if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unknown property %s", path, key)
+				} else {
+					continue
+				}
+			}
+			if err := validateSchema(root, sub, item, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateSchema(root, items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether value has one of the JSON Schema types
+func matchesType(types any, value any) bool {
+	for _, t := range toStrings(types) {
+		switch v := value.(type) {
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == float64(int64(v))) {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toStrings converts a JSON string or array of strings to a slice
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var s []string
+		for _, item := range v {
+			s = append(s, item.(string))
+		}
+		return s
+	}
+	return nil
+}
+
+// loadSchema generates the schema and decodes it for validateSchema
+func loadSchema(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("JSONSchema returned invalid JSON: %v", err)
+	}
+	return schema
+}
+
+// yamlToJSON decodes a YAML config into the values JSON decoding would give
+func yamlToJSON(t *testing.T, data []byte) any {
+	t.Helper()
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to convert YAML to JSON: %v", err)
+	}
+	var value any
+	if err := json.Unmarshal(converted, &value); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	return value
+}
+
+func TestJSONSchema_ExampleConfig(t *testing.T) {
+	schema := loadSchema(t)
+	data, err := os.ReadFile("../../config.example.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read example config: %v", err)
+	}
+	if err := validateSchema(schema, schema, yamlToJSON(t, data), "$"); err != nil {
+		t.Errorf("Expected the example config to match the schema, got: %v", err)
+	}
+}
+
+func TestJSONSchema_Validation(t *testing.T) {
+	schema := loadSchema(t)
+	tests := []struct {
+		name     string
+		config   string
+		errorMsg string
+	}{
+		{
+			name: "Valid",
+			config: `interval: 6h
+max_connections: 2
+users:
+  - name: alice
+    items:
+      - Blanton's
+      - name: Pappy Van Winkle
+        max_price: 300
+    zipcode: "97201"
+    distance: 10
+    notifications:
+      - type: email
+        credential:
+          smtp_host: smtp.example.com
+          smtp_port: "587"
+          username: alice
+          password: secret
+          from: gfl@example.com
+          to: alice@example.com
+          tls_mode: starttls
+`,
+		},
+		{
+			name:     "Unknown setting",
+			config:   "intervals: 6h\n",
+			errorMsg: "unknown property intervals",
+		},
+		{
+			name:     "Invalid duration",
+			config:   "interval: soon\n",
+			errorMsg: "does not match",
+		},
+		{
+			name: "Unsupported notification type",
+			config: `users:
+  - name: alice
+    notifications:
+      - type: carrier-pigeon
+`,
+			errorMsg: "is not one of",
+		},
+		{
+			name: "Missing credential",
+			config: `users:
+  - name: alice
+    notifications:
+      - type: telegram
+        credential:
+          token: abc
+`,
+			errorMsg: "missing required chat_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchema(schema, schema, yamlToJSON(t, []byte(tt.config)), "$")
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Expected the config to match the schema, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Expected error containing %q, got: %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestNotificationCredentials(t *testing.T) {
+	nc := NotificationConfig{Type: "Matrix", Credential: map[string]string{"homeserver": "https://matrix.org", "user_id": "@gfl:matrix.org"}}
+	if key := nc.MissingCredential(); key != "room_id" {
+		t.Errorf("Expected room_id to be missing, got %q", key)
+	}
+
+	nc.Credential["room_id"] = "!room:matrix.org"
+	nc.Credential["access_token"] = "token"
+	if key := nc.MissingCredential(); key != "" {
+		t.Errorf("Expected no missing credential, got %q", key)
+	}
+
+	if _, ok := NotificationCredentials("carrier-pigeon"); ok {
+		t.Error("Expected an unsupported type to have no credential keys")
+	}
+}